@@ -307,3 +307,32 @@ func (r *ProtocolIncus) GetClusterGroup(name string) (*api.ClusterGroup, string,
 
 	return &group, etag, nil
 }
+
+// GetClusterUpgrade returns the versions of the cluster members and the state of the rolling upgrade.
+func (r *ProtocolIncus) GetClusterUpgrade() (*api.ClusterUpgrade, error) {
+	if !r.HasExtension("cluster_rolling_upgrade") {
+		return nil, fmt.Errorf("The server is missing the required \"cluster_rolling_upgrade\" API extension")
+	}
+
+	upgrade := api.ClusterUpgrade{}
+	_, err := r.queryStruct("GET", "/cluster/upgrade", nil, "", &upgrade)
+	if err != nil {
+		return nil, err
+	}
+
+	return &upgrade, nil
+}
+
+// UpdateClusterUpgrade starts, resumes or aborts a rolling upgrade, or signals that a member was upgraded.
+func (r *ProtocolIncus) UpdateClusterUpgrade(upgrade api.ClusterUpgradePost) error {
+	if !r.HasExtension("cluster_rolling_upgrade") {
+		return fmt.Errorf("The server is missing the required \"cluster_rolling_upgrade\" API extension")
+	}
+
+	_, _, err := r.query("POST", "/cluster/upgrade", upgrade, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
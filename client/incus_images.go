@@ -392,6 +392,12 @@ func (r *ProtocolIncus) CreateImage(image api.ImagesPost, args *ImageCreateArgs)
 		}
 	}
 
+	if image.Stateful {
+		if !r.HasExtension("container_stateful_publish") {
+			return nil, fmt.Errorf("The server is missing the required \"container_stateful_publish\" API extension")
+		}
+	}
+
 	// Send the JSON based request
 	if args == nil {
 		op, _, err := r.queryOperation("POST", "/images", image, "")
@@ -2,8 +2,10 @@ package incus
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -11,6 +13,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -2074,6 +2077,32 @@ func (r *ProtocolIncus) GetInstanceState(name string) (*api.InstanceState, strin
 	return &state, etag, nil
 }
 
+// GetInstanceUsage returns the resource usage history of the instance since the given time, split in steps.
+func (r *ProtocolIncus) GetInstanceUsage(name string, since time.Time, step time.Duration) (*api.InstanceUsage, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.HasExtension("instance_usage_history") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_usage_history\" API extension")
+	}
+
+	v := url.Values{}
+	v.Set("since", since.UTC().Format(time.RFC3339))
+	v.Set("step", fmt.Sprintf("%d", int64(step.Seconds())))
+
+	usage := api.InstanceUsage{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/usage?%s", path, url.PathEscape(name), v.Encode()), nil, "", &usage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
 // UpdateInstanceState updates the instance to match the requested state.
 func (r *ProtocolIncus) UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (Operation, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2601,6 +2630,47 @@ func (r *ProtocolIncus) GetInstanceConsoleLog(instanceName string, args *Instanc
 	return resp.Body, err
 }
 
+// GetInstanceConsoleScreenshot returns a PNG screenshot of the requested instance's VGA console.
+func (r *ProtocolIncus) GetInstanceConsoleScreenshot(instanceName string) (io.ReadCloser, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.HasExtension("console_vga_screenshot") {
+		return nil, fmt.Errorf("The server is missing the required \"console_vga_screenshot\" API extension")
+	}
+
+	// Prepare the HTTP request
+	url := fmt.Sprintf("%s/1.0%s/%s/console?type=vga", r.httpBaseURL.String(), path, url.PathEscape(instanceName))
+
+	url, err = r.setQueryAttributes(url)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	resp, err := r.DoHTTP(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := incusParseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp.Body, nil
+}
+
 // DeleteInstanceConsoleLog deletes the requested instance's console log.
 func (r *ProtocolIncus) DeleteInstanceConsoleLog(instanceName string, args *InstanceConsoleLogArgs) error {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2621,6 +2691,76 @@ func (r *ProtocolIncus) DeleteInstanceConsoleLog(instanceName string, args *Inst
 	return nil
 }
 
+// DumpInstanceMemory dumps the memory of a running virtual machine and returns it as a stream.
+func (r *ProtocolIncus) DumpInstanceMemory(instanceName string, req api.InstanceDebugMemoryPost) (io.ReadCloser, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.HasExtension("instance_debug_memory") {
+		return nil, fmt.Errorf("The server is missing the required \"instance_debug_memory\" API extension")
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare the HTTP request
+	url := fmt.Sprintf("%s/1.0%s/%s/debug/memory", r.httpBaseURL.String(), path, url.PathEscape(instanceName))
+
+	url, err = r.setQueryAttributes(url)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Send the request
+	resp, err := r.DoHTTP(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the return value for a cleaner error
+	if resp.StatusCode != http.StatusOK {
+		_, _, err := incusParseResponse(resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The server reports failures happening after the dump started in a trailer.
+	return &trailerErrorReader{resp: resp}, nil
+}
+
+// trailerErrorReader reads the body of a streamed response and returns the error
+// reported by the server in the X-Incus-Error trailer instead of io.EOF.
+type trailerErrorReader struct {
+	resp *http.Response
+}
+
+// Read reads from the response body.
+func (r *trailerErrorReader) Read(p []byte) (int, error) {
+	n, err := r.resp.Body.Read(p)
+	if err == io.EOF && r.resp.Trailer.Get("X-Incus-Error") != "" {
+		return n, errors.New(r.resp.Trailer.Get("X-Incus-Error"))
+	}
+
+	return n, err
+}
+
+// Close closes the response body.
+func (r *trailerErrorReader) Close() error {
+	return r.resp.Body.Close()
+}
+
 // GetInstanceBackupNames returns a list of backup names for the instance.
 func (r *ProtocolIncus) GetInstanceBackupNames(instanceName string) ([]string, error) {
 	if !r.HasExtension("container_backup") {
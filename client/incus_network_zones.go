@@ -59,6 +59,23 @@ func (r *ProtocolIncus) GetNetworkZone(name string) (*api.NetworkZone, string, e
 	return &zone, etag, nil
 }
 
+// GetNetworkZoneDNSSEC returns the DNSSEC keys of a Network zone.
+func (r *ProtocolIncus) GetNetworkZoneDNSSEC(name string) (*api.NetworkZoneDNSSEC, error) {
+	if !r.HasExtension("network_zones_dnssec") {
+		return nil, fmt.Errorf(`The server is missing the required "network_zones_dnssec" API extension`)
+	}
+
+	dnssec := api.NetworkZoneDNSSEC{}
+
+	// Fetch the raw value.
+	_, err := r.queryStruct("GET", fmt.Sprintf("/network-zones/%s/dnssec", url.PathEscape(name)), nil, "", &dnssec)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dnssec, nil
+}
+
 // CreateNetworkZone defines a new Network zone using the provided struct.
 func (r *ProtocolIncus) CreateNetworkZone(zone api.NetworkZonesPost) error {
 	if !r.HasExtension("network_dns") {
@@ -76,6 +76,23 @@ func (r *ProtocolIncus) GetNetworkLeases(name string) ([]api.NetworkLease, error
 	return leases, nil
 }
 
+// GetNetworkFlows returns the connections recorded by the flow log of the network.
+func (r *ProtocolIncus) GetNetworkFlows(name string) ([]api.NetworkFlow, error) {
+	if !r.HasExtension("network_flows") {
+		return nil, fmt.Errorf("The server is missing the required \"network_flows\" API extension")
+	}
+
+	flows := []api.NetworkFlow{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/networks/%s/flows", url.PathEscape(name)), nil, "", &flows)
+	if err != nil {
+		return nil, err
+	}
+
+	return flows, nil
+}
+
 // GetNetworkState returns metrics and information on the running network.
 func (r *ProtocolIncus) GetNetworkState(name string) (*api.NetworkState, error) {
 	if !r.HasExtension("network_state") {
@@ -3,6 +3,7 @@ package incus
 import (
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/lxc/incus/shared/api"
 )
@@ -78,6 +79,28 @@ func (r *ProtocolIncus) GetProjectState(name string) (*api.ProjectState, error)
 	return &projectState, nil
 }
 
+// GetProjectUsage returns the resource usage history of the project between start and end, split in steps.
+func (r *ProtocolIncus) GetProjectUsage(name string, start time.Time, end time.Time, step time.Duration) (*api.ProjectUsage, error) {
+	if !r.HasExtension("projects_usage_history") {
+		return nil, fmt.Errorf("The server is missing the required \"projects_usage_history\" API extension")
+	}
+
+	v := url.Values{}
+	v.Set("start", start.UTC().Format(time.RFC3339))
+	v.Set("end", end.UTC().Format(time.RFC3339))
+	v.Set("step", fmt.Sprintf("%d", int64(step.Seconds())))
+
+	usage := api.ProjectUsage{}
+
+	// Fetch the raw value
+	_, err := r.queryStruct("GET", fmt.Sprintf("/projects/%s/usage?%s", url.PathEscape(name), v.Encode()), nil, "", &usage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
 // CreateProject defines a new project.
 func (r *ProtocolIncus) CreateProject(project api.ProjectsPost) error {
 	if !r.HasExtension("projects") {
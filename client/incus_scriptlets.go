@@ -0,0 +1,39 @@
+package incus
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lxc/incus/shared/api"
+)
+
+// GetScriptletData returns the key/value store of the given scriptlet.
+func (r *ProtocolIncus) GetScriptletData(name string) (*api.ScriptletData, string, error) {
+	if !r.HasExtension("instance_placement_scriptlet_data") {
+		return nil, "", fmt.Errorf("The server is missing the required \"instance_placement_scriptlet_data\" API extension")
+	}
+
+	data := api.ScriptletData{}
+
+	etag, err := r.queryStruct("GET", fmt.Sprintf("/scriptlets/%s/data", url.PathEscape(name)), nil, "", &data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &data, etag, nil
+}
+
+// UpdateScriptletData replaces the key/value store of the given scriptlet.
+func (r *ProtocolIncus) UpdateScriptletData(name string, data api.ScriptletData, ETag string) error {
+	if !r.HasExtension("instance_placement_scriptlet_data") {
+		return fmt.Errorf("The server is missing the required \"instance_placement_scriptlet_data\" API extension")
+	}
+
+	// Send the request
+	_, _, err := r.query("PUT", fmt.Sprintf("/scriptlets/%s/data", url.PathEscape(name)), data, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -123,6 +124,8 @@ type InstanceServer interface {
 
 	GetInstanceConsoleLog(instanceName string, args *InstanceConsoleLogArgs) (content io.ReadCloser, err error)
 	DeleteInstanceConsoleLog(instanceName string, args *InstanceConsoleLogArgs) (err error)
+	GetInstanceConsoleScreenshot(instanceName string) (content io.ReadCloser, err error)
+	DumpInstanceMemory(instanceName string, req api.InstanceDebugMemoryPost) (content io.ReadCloser, err error)
 
 	GetInstanceFile(instanceName string, path string) (content io.ReadCloser, resp *InstanceFileResponse, err error)
 	CreateInstanceFile(instanceName string, path string, args InstanceFileArgs) (err error)
@@ -151,6 +154,7 @@ type InstanceServer interface {
 	CreateInstanceFromBackup(args InstanceBackupArgs) (op Operation, err error)
 
 	GetInstanceState(name string) (state *api.InstanceState, ETag string, err error)
+	GetInstanceUsage(name string, since time.Time, step time.Duration) (usage *api.InstanceUsage, err error)
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
 
 	GetInstanceLogfiles(name string) (logfiles []string, err error)
@@ -188,6 +192,7 @@ type InstanceServer interface {
 	GetNetwork(name string) (network *api.Network, ETag string, err error)
 	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
 	GetNetworkState(name string) (state *api.NetworkState, err error)
+	GetNetworkFlows(name string) (flows []api.NetworkFlow, err error)
 	CreateNetwork(network api.NetworksPost) (err error)
 	UpdateNetwork(name string, network api.NetworkPut, ETag string) (err error)
 	RenameNetwork(name string, network api.NetworkPost) (err error)
@@ -237,6 +242,7 @@ type InstanceServer interface {
 	CreateNetworkZone(zone api.NetworkZonesPost) (err error)
 	UpdateNetworkZone(name string, zone api.NetworkZonePut, ETag string) (err error)
 	DeleteNetworkZone(name string) (err error)
+	GetNetworkZoneDNSSEC(name string) (dnssec *api.NetworkZoneDNSSEC, err error)
 
 	GetNetworkZoneRecordNames(zone string) (names []string, err error)
 	GetNetworkZoneRecords(zone string) (records []api.NetworkZoneRecord, err error)
@@ -269,6 +275,7 @@ type InstanceServer interface {
 	GetProjects() (projects []api.Project, err error)
 	GetProject(name string) (project *api.Project, ETag string, err error)
 	GetProjectState(name string) (project *api.ProjectState, err error)
+	GetProjectUsage(name string, start time.Time, end time.Time, step time.Duration) (usage *api.ProjectUsage, err error)
 	CreateProject(project api.ProjectsPost) (err error)
 	UpdateProject(name string, project api.ProjectPut, ETag string) (err error)
 	RenameProject(name string, project api.ProjectPost) (op Operation, err error)
@@ -356,6 +363,12 @@ type InstanceServer interface {
 	DeleteClusterGroup(name string) error
 	UpdateClusterGroup(name string, group api.ClusterGroupPut, ETag string) error
 	GetClusterGroup(name string) (*api.ClusterGroup, string, error)
+	GetClusterUpgrade() (upgrade *api.ClusterUpgrade, err error)
+	UpdateClusterUpgrade(upgrade api.ClusterUpgradePost) (err error)
+
+	// Scriptlet functions
+	GetScriptletData(name string) (data *api.ScriptletData, ETag string, err error)
+	UpdateScriptletData(name string, data api.ScriptletData, ETag string) (err error)
 
 	// Warning functions
 	GetWarningUUIDs() (uuids []string, err error)
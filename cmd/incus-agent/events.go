@@ -94,6 +94,13 @@ func eventsPost(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
+	// Bring hotplugged CPUs and memory online.
+	if event.Type == "cpu" {
+		onlineCPUs()
+	} else if event.Type == "memory" {
+		onlineMemory()
+	}
+
 	err = d.events.Send("", event.Type, event.Metadata)
 	if err != nil {
 		return response.InternalError(err)
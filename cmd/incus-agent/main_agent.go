@@ -111,6 +111,10 @@ func (c *cmdAgent) Run(cmd *cobra.Command, args []string) error {
 
 	reconfigureNetworkInterfaces()
 
+	// Bring online any CPU and memory hotplugged before the agent started.
+	onlineCPUs()
+	onlineMemory()
+
 	// Load the kernel driver.
 	logger.Info("Loading vsock module")
 	err = linux.LoadModule("vsock")
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lxc/incus/shared/logger"
+)
+
+// onlineMemory brings online any memory block that was hotplugged into the virtual machine but left offline by the kernel.
+func onlineMemory() {
+	paths, err := filepath.Glob("/sys/devices/system/memory/memory[0-9]*/online")
+	if err != nil {
+		return
+	}
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil || strings.TrimSpace(string(content)) != "0" {
+			continue
+		}
+
+		err = os.WriteFile(path, []byte("1"), 0644)
+		if err != nil {
+			logger.Errorf("Failed to bring memory block %q online: %v", filepath.Base(filepath.Dir(path)), err)
+			continue
+		}
+
+		logger.Infof("Brought memory block %q online", filepath.Base(filepath.Dir(path)))
+	}
+}
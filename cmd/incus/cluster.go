@@ -93,6 +93,10 @@ func (c *cmdCluster) Command() *cobra.Command {
 	cmdClusterRestore := cmdClusterRestore{global: c.global, cluster: c}
 	cmd.AddCommand(cmdClusterRestore.Command())
 
+	// Rolling upgrade plan
+	cmdClusterUpgradePlan := cmdClusterUpgradePlan{global: c.global, cluster: c}
+	cmd.AddCommand(cmdClusterUpgradePlan.Command())
+
 	clusterGroupCmd := cmdClusterGroup{global: c.global, cluster: c}
 	cmd.AddCommand(clusterGroupCmd.Command())
 
@@ -173,7 +177,16 @@ func (c *cmdClusterList) Run(cmd *cobra.Command, args []string) error {
 			rolesDelimiter = ","
 		}
 
-		line := []string{member.ServerName, member.URL, strings.Join(roles, rolesDelimiter), member.Architecture, member.FailureDomain, member.Description, strings.ToUpper(member.Status), member.Message}
+		labels := []string{}
+		for k, v := range member.Config {
+			if strings.HasPrefix(k, "label.") {
+				labels = append(labels, fmt.Sprintf("%s=%s", strings.TrimPrefix(k, "label."), v))
+			}
+		}
+
+		sort.Strings(labels)
+
+		line := []string{member.ServerName, member.URL, strings.Join(roles, rolesDelimiter), member.Architecture, member.FailureDomain, member.Description, strings.ToUpper(member.Status), member.Message, strings.Join(labels, rolesDelimiter)}
 		data = append(data, line)
 	}
 
@@ -188,6 +201,7 @@ func (c *cmdClusterList) Run(cmd *cobra.Command, args []string) error {
 		i18n.G("DESCRIPTION"),
 		i18n.G("STATE"),
 		i18n.G("MESSAGE"),
+		i18n.G("LABELS"),
 	}
 
 	return cli.RenderTable(c.flagFormat, header, data, members)
@@ -1253,3 +1267,104 @@ func (c *cmdClusterEvacuateAction) Run(cmd *cobra.Command, args []string) error
 	progress.Done("")
 	return nil
 }
+
+// Upgrade plan.
+type cmdClusterUpgradePlan struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+
+	flagFormat string
+}
+
+func (c *cmdClusterUpgradePlan) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("upgrade-plan", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Show the rolling upgrade plan of the cluster")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the rolling upgrade plan of the cluster
+
+The versions of the cluster members are listed in upgrade order, along with the
+state of the rolling upgrade and anything preventing it from proceeding.`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdClusterUpgradePlan) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// Get the upgrade plan
+	upgrade, err := resource.server.GetClusterUpgrade()
+	if err != nil {
+		return err
+	}
+
+	if c.flagFormat == cli.TableFormatJSON || c.flagFormat == cli.TableFormatYAML {
+		return cli.RenderTable(c.flagFormat, nil, nil, upgrade)
+	}
+
+	// Members are listed in upgrade order while an upgrade is tracked, by name otherwise.
+	tracked := false
+	data := [][]string{}
+	for _, member := range upgrade.Members {
+		line := []string{member.Name, member.Version, fmt.Sprintf("%d", member.Schema), fmt.Sprintf("%d", member.APIExtensions), strings.ToUpper(member.Status), member.UpgradeState, member.UpgradeError}
+		data = append(data, line)
+
+		if member.UpgradeState != "" {
+			tracked = true
+		}
+	}
+
+	if !tracked {
+		sort.Sort(cli.SortColumnsNaturally(data))
+	}
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("VERSION"),
+		i18n.G("SCHEMA"),
+		i18n.G("API EXTENSIONS"),
+		i18n.G("STATE"),
+		i18n.G("UPGRADE STATE"),
+		i18n.G("ERROR"),
+	}
+
+	if c.flagFormat == cli.TableFormatCSV {
+		return cli.RenderTable(c.flagFormat, header, data, upgrade.Members)
+	}
+
+	fmt.Printf(i18n.G("Status: %s")+"\n\n", upgrade.Status)
+
+	err = cli.RenderTable(c.flagFormat, header, data, upgrade.Members)
+	if err != nil {
+		return err
+	}
+
+	if len(upgrade.Blockers) > 0 {
+		fmt.Println("\n" + i18n.G("Blockers:"))
+		for _, blocker := range upgrade.Blockers {
+			fmt.Printf("  - %s\n", blocker)
+		}
+	}
+
+	return nil
+}
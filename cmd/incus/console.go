@@ -25,8 +25,9 @@ import (
 type cmdConsole struct {
 	global *cmdGlobal
 
-	flagShowLog bool
-	flagType    string
+	flagShowLog    bool
+	flagType       string
+	flagScreenshot string
 }
 
 func (c *cmdConsole) Command() *cobra.Command {
@@ -42,6 +43,7 @@ as well as retrieve past log entries from it.`))
 	cmd.RunE = c.Run
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Retrieve the instance's console log"))
 	cmd.Flags().StringVarP(&c.flagType, "type", "t", "console", i18n.G("Type of connection to establish: 'console' for serial console, 'vga' for SPICE graphical output")+"``")
+	cmd.Flags().StringVar(&c.flagScreenshot, "screenshot", "", i18n.G("Save a PNG screenshot of the VGA console to the file")+"``")
 
 	return cmd
 }
@@ -140,6 +142,33 @@ func (c *cmdConsole) Run(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Save a screenshot if requested
+	if c.flagScreenshot != "" {
+		if cmd.Flags().Changed("type") && c.flagType != "vga" {
+			return fmt.Errorf(i18n.G("The --screenshot flag is only supported by 'vga' output type"))
+		}
+
+		screenshot, err := d.GetInstanceConsoleScreenshot(name)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = screenshot.Close() }()
+
+		target, err := os.Create(c.flagScreenshot)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(target, screenshot)
+		if err != nil {
+			_ = target.Close()
+			return err
+		}
+
+		return target.Close()
+	}
+
 	return c.Console(d, name)
 }
 
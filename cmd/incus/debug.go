@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	cli "github.com/lxc/incus/internal/cmd"
+	"github.com/lxc/incus/internal/i18n"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/ioprogress"
+	"github.com/lxc/incus/shared/units"
+)
+
+type cmdDebug struct {
+	global *cmdGlobal
+}
+
+func (c *cmdDebug) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("debug")
+	cmd.Short = i18n.G("Debug commands")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Debug commands for instances`))
+
+	// Dump memory
+	debugDumpMemoryCmd := cmdDebugDumpMemory{global: c.global, debug: c}
+	cmd.AddCommand(debugDumpMemoryCmd.Command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Dump memory.
+type cmdDebugDumpMemory struct {
+	global *cmdGlobal
+	debug  *cmdDebug
+
+	flagFormat string
+	flagPause  bool
+}
+
+func (c *cmdDebugDumpMemory) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("dump-memory", i18n.G("[<remote>:]<instance> <target>"))
+	cmd.Short = i18n.G("Dump the memory of a virtual machine")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Dump the memory of a virtual machine
+
+The virtual machine is paused while its memory is being dumped.
+Use "-" as the target to write the dump to standard output.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus debug dump-memory v1 v1.elf
+    Dump the memory of the v1 virtual machine to v1.elf.
+
+incus debug dump-memory v1 v1.kdump --format=kdump-zlib --pause
+    Dump the memory of v1 in the compressed kdump format and leave it paused.`))
+
+	cmd.Flags().StringVar(&c.flagFormat, "format", "elf", i18n.G("Format of the dump (elf, kdump-zlib, kdump-lzo, kdump-snappy or win-dmp)")+"``")
+	cmd.Flags().BoolVar(&c.flagPause, "pause", false, i18n.G("Leave the instance paused once the dump is complete"))
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdDebugDumpMemory) Run(cmd *cobra.Command, args []string) error {
+	conf := c.global.conf
+
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Connect to the daemon.
+	remote, name, err := conf.ParseRemote(args[0])
+	if err != nil {
+		return err
+	}
+
+	d, err := conf.GetInstanceServer(remote)
+	if err != nil {
+		return err
+	}
+
+	// Request the dump.
+	req := api.InstanceDebugMemoryPost{
+		Format: c.flagFormat,
+		Pause:  c.flagPause,
+	}
+
+	content, err := d.DumpInstanceMemory(name, req)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = content.Close() }()
+
+	// Prepare the target.
+	targetName := args[1]
+
+	var target *os.File
+	if targetName == "-" {
+		target = os.Stdout
+		c.global.flagQuiet = true
+	} else {
+		target, err = os.Create(targetName)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = target.Close() }()
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Dumping memory: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	reader := &ioprogress.ProgressReader{
+		ReadCloser: content,
+		Tracker: &ioprogress.ProgressTracker{
+			Handler: func(received int64, speed int64) {
+				progress.UpdateProgress(ioprogress.ProgressData{
+					Text: fmt.Sprintf("%s (%s/s)", units.GetByteSizeString(received, 2), units.GetByteSizeString(speed, 2)),
+				})
+			},
+		},
+	}
+
+	_, err = io.Copy(target, reader)
+	if err != nil {
+		progress.Done("")
+
+		if targetName != "-" {
+			_ = os.Remove(targetName)
+		}
+
+		return err
+	}
+
+	if targetName != "-" {
+		err = target.Close()
+		if err != nil {
+			progress.Done("")
+			return err
+		}
+	}
+
+	progress.Done(i18n.G("Memory dumped successfully!"))
+	return nil
+}
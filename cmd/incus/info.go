@@ -5,6 +5,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -24,6 +25,9 @@ type cmdInfo struct {
 	flagShowLog   bool
 	flagResources bool
 	flagTarget    string
+	flagUsage     bool
+	flagSince     string
+	flagStep      string
 }
 
 func (c *cmdInfo) Command() *cobra.Command {
@@ -36,6 +40,9 @@ func (c *cmdInfo) Command() *cobra.Command {
 		`incus info [<remote>:]<instance> [--show-log]
     For instance information.
 
+incus info [<remote>:]<instance> --usage [--since=7d] [--step=1d]
+    For the resource usage history of an instance.
+
 incus info [<remote>:] [--resources]
     For server information.`))
 
@@ -43,6 +50,9 @@ incus info [<remote>:] [--resources]
 	cmd.Flags().BoolVar(&c.flagShowLog, "show-log", false, i18n.G("Show the instance's last 100 log lines?"))
 	cmd.Flags().BoolVar(&c.flagResources, "resources", false, i18n.G("Show the resources available to the server"))
 	cmd.Flags().StringVar(&c.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().BoolVar(&c.flagUsage, "usage", false, i18n.G("Show the instance's resource usage history"))
+	cmd.Flags().StringVar(&c.flagSince, "since", "24h", i18n.G("How far back to show the resource usage history (e.g. 12h or 7d)")+"``")
+	cmd.Flags().StringVar(&c.flagStep, "step", "1h", i18n.G("Duration of each entry of the resource usage history (e.g. 5m or 1d)")+"``")
 
 	return cmd
 }
@@ -79,6 +89,10 @@ func (c *cmdInfo) Run(cmd *cobra.Command, args []string) error {
 		return c.remoteInfo(d)
 	}
 
+	if c.flagUsage {
+		return c.instanceUsage(d, cName)
+	}
+
 	return c.instanceInfo(d, conf.Remotes[remote], cName, c.flagShowLog)
 }
 
@@ -712,3 +726,57 @@ func (c *cmdInfo) instanceInfo(d incus.InstanceServer, remote config.Remote, nam
 
 	return nil
 }
+
+func (c *cmdInfo) instanceUsage(d incus.InstanceServer, name string) error {
+	// Quick checks.
+	if c.flagTarget != "" {
+		return fmt.Errorf(i18n.G("--target cannot be used with instances"))
+	}
+
+	since, err := parseDuration(c.flagSince)
+	if err != nil {
+		return err
+	}
+
+	step, err := parseDuration(c.flagStep)
+	if err != nil {
+		return err
+	}
+
+	usage, err := d.GetInstanceUsage(name, time.Now().Add(-since), step)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04 MST"
+
+	data := [][]string{}
+	for _, entry := range usage.Entries {
+		if entry.Samples == 0 {
+			data = append(data, []string{entry.Timestamp.Local().Format(layout), "-", "-", "-", "-", "-", "-"})
+			continue
+		}
+
+		data = append(data, []string{
+			entry.Timestamp.Local().Format(layout),
+			fmt.Sprintf("%.2f", float64(entry.CPUUsage)/1000000000),
+			units.GetByteSizeStringIEC(entry.MemoryUsage, 2),
+			units.GetByteSizeStringIEC(entry.MemoryUsagePeak, 2),
+			units.GetByteSizeStringIEC(entry.DiskUsage, 2),
+			units.GetByteSizeString(entry.NetworkReceived, 2),
+			units.GetByteSizeString(entry.NetworkSent, 2),
+		})
+	}
+
+	header := []string{
+		i18n.G("TIME"),
+		i18n.G("CPU (SECONDS)"),
+		i18n.G("MEMORY (AVERAGE)"),
+		i18n.G("MEMORY (PEAK)"),
+		i18n.G("DISK"),
+		i18n.G("RECEIVED"),
+		i18n.G("SENT"),
+	}
+
+	return cli.RenderTable(cli.TableFormatTable, header, data, usage)
+}
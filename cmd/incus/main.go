@@ -137,6 +137,10 @@ For help with any of those, simply call them with --help.`))
 	copyCmd := cmdCopy{global: &globalCmd}
 	app.AddCommand(copyCmd.Command())
 
+	// debug sub-command
+	debugCmd := cmdDebug{global: &globalCmd}
+	app.AddCommand(debugCmd.Command())
+
 	// delete sub-command
 	deleteCmd := cmdDelete{global: &globalCmd}
 	app.AddCommand(deleteCmd.Command())
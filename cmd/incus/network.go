@@ -893,6 +893,35 @@ func (c *cmdNetworkInfo) Run(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s: %s\n", i18n.G("Chassis"), state.OVN.Chassis)
 	}
 
+	// WireGuard information.
+	if state.Wireguard != nil {
+		fmt.Println("")
+		fmt.Println(i18n.G("WireGuard:"))
+		fmt.Printf("  %s: %s\n", i18n.G("Public key"), state.Wireguard.PublicKey)
+		fmt.Printf("  %s: %d\n", i18n.G("Listen port"), state.Wireguard.ListenPort)
+
+		if len(state.Wireguard.Peers) > 0 {
+			fmt.Printf("  %s:\n", i18n.G("Peers"))
+			for _, peer := range state.Wireguard.Peers {
+				name := peer.Name
+				if name == "" {
+					name = peer.PublicKey
+				}
+
+				handshake := i18n.G("never")
+				if !peer.LatestHandshake.IsZero() {
+					handshake = peer.LatestHandshake.Local().Format("2006/01/02 15:04 MST")
+				}
+
+				fmt.Printf("    %s:\n", name)
+				fmt.Printf("      %s: %s\n", i18n.G("Endpoint"), peer.Endpoint)
+				fmt.Printf("      %s: %s\n", i18n.G("Latest handshake"), handshake)
+				fmt.Printf("      %s: %s\n", i18n.G("Bytes received"), units.GetByteSizeString(int64(peer.BytesReceived), 2))
+				fmt.Printf("      %s: %s\n", i18n.G("Bytes sent"), units.GetByteSizeString(int64(peer.BytesSent), 2))
+			}
+		}
+	}
+
 	return nil
 }
 
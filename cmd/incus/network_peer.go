@@ -209,9 +209,16 @@ type cmdNetworkPeerCreate struct {
 
 func (c *cmdNetworkPeerCreate) Command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <peer_name> <[target project/]target_network> [key=value...]"))
+	cmd.Use = usage("create", i18n.G("[<remote>:]<network> <peer_name> [<[target project/]target_network>] [key=value...]"))
 	cmd.Short = i18n.G("Create new network peering")
-	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new network peering"))
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Create new network peering
+
+The target network is required for OVN networks and must be omitted for WireGuard networks.`))
+	cmd.Example = cli.FormatSection("", i18n.G(`incus network peer create ovn1 mypeer ovn2
+    Create a peering between the ovn1 and ovn2 OVN networks.
+
+incus network peer create wg0 site-b public_key=xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg= allowed_ips=10.2.0.0/16 endpoint=198.51.100.10:51820
+    Add the site-b peer to the wg0 WireGuard network.`))
 	cmd.RunE = c.Run
 
 	return cmd
@@ -219,7 +226,7 @@ func (c *cmdNetworkPeerCreate) Command() *cobra.Command {
 
 func (c *cmdNetworkPeerCreate) Run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
-	exit, err := c.global.CheckArgs(cmd, args, 3, -1)
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
 	if exit {
 		return err
 	}
@@ -240,18 +247,23 @@ func (c *cmdNetworkPeerCreate) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Missing peer name"))
 	}
 
-	if args[2] == "" {
-		return fmt.Errorf(i18n.G("Missing target network"))
-	}
+	// The target network is optional, anything containing "=" is treated as a config key.
+	var targetProject, targetNetwork string
+	configArgs := args[2:]
+	if len(args) > 2 && !strings.Contains(args[2], "=") {
+		if args[2] == "" {
+			return fmt.Errorf(i18n.G("Missing target network"))
+		}
 
-	targetParts := strings.SplitN(args[2], "/", 2)
+		targetParts := strings.SplitN(args[2], "/", 2)
+		if len(targetParts) == 2 {
+			targetProject = targetParts[0]
+			targetNetwork = targetParts[1]
+		} else {
+			targetNetwork = targetParts[0]
+		}
 
-	var targetProject, targetNetwork string
-	if len(targetParts) == 2 {
-		targetProject = targetParts[0]
-		targetNetwork = targetParts[1]
-	} else {
-		targetNetwork = targetParts[0]
+		configArgs = args[3:]
 	}
 
 	// If stdin isn't a terminal, read yaml from it.
@@ -273,10 +285,10 @@ func (c *cmdNetworkPeerCreate) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get config filters from arguments.
-	for i := 3; i < len(args); i++ {
-		entry := strings.SplitN(args[i], "=", 2)
+	for _, arg := range configArgs {
+		entry := strings.SplitN(arg, "=", 2)
 		if len(entry) < 2 {
-			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), arg)
 		}
 
 		peerPut.Config[entry[0]] = entry[1]
@@ -34,6 +34,10 @@ func (c *cmdNetworkZone) Command() *cobra.Command {
 	networkZoneShowCmd := cmdNetworkZoneShow{global: c.global, networkZone: c}
 	cmd.AddCommand(networkZoneShowCmd.Command())
 
+	// List DNSSEC keys.
+	networkZoneListDNSSECKeysCmd := cmdNetworkZoneListDNSSECKeys{global: c.global, networkZone: c}
+	cmd.AddCommand(networkZoneListDNSSECKeysCmd.Command())
+
 	// Get.
 	networkZoneGetCmd := cmdNetworkZoneGet{global: c.global, networkZone: c}
 	cmd.AddCommand(networkZoneGetCmd.Command())
@@ -195,6 +199,84 @@ func (c *cmdNetworkZoneShow) Run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// List DNSSEC keys.
+type cmdNetworkZoneListDNSSECKeys struct {
+	global      *cmdGlobal
+	networkZone *cmdNetworkZone
+
+	flagFormat string
+}
+
+func (c *cmdNetworkZoneListDNSSECKeys) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-dnssec-keys", i18n.G("[<remote>:]<Zone>"))
+	cmd.Short = i18n.G("List the DNSSEC keys of a network zone")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`List the DNSSEC keys of a network zone
+
+The DS records of the key signing keys must be published in the parent zone.`))
+
+	cmd.RunE = c.Run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	return cmd
+}
+
+func (c *cmdNetworkZoneListDNSSECKeys) Run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing network zone name"))
+	}
+
+	// Get the keys.
+	dnssec, err := resource.server.GetNetworkZoneDNSSEC(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, key := range dnssec.Keys {
+		status := i18n.G("active")
+		if !key.RetiredAt.IsZero() {
+			status = i18n.G("retired")
+		}
+
+		details := []string{
+			fmt.Sprintf("%d", key.KeyTag),
+			strings.ToUpper(key.Type),
+			key.Algorithm,
+			status,
+			key.CreatedAt.Local().Format("2006/01/02 15:04 MST"),
+			key.DS,
+		}
+
+		data = append(data, details)
+	}
+
+	header := []string{
+		i18n.G("KEY TAG"),
+		i18n.G("TYPE"),
+		i18n.G("ALGORITHM"),
+		i18n.G("STATUS"),
+		i18n.G("CREATED AT"),
+		i18n.G("DS"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, dnssec.Keys)
+}
+
 // Get.
 type cmdNetworkZoneGet struct {
 	global      *cmdGlobal
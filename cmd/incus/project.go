@@ -6,10 +6,12 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 
+	"github.com/lxc/incus/client"
 	cli "github.com/lxc/incus/internal/cmd"
 	"github.com/lxc/incus/internal/i18n"
 	"github.com/lxc/incus/shared/api"
@@ -793,6 +795,10 @@ type cmdProjectInfo struct {
 	project *cmdProject
 
 	flagFormat string
+	flagUsage  bool
+	flagStart  string
+	flagEnd    string
+	flagStep   string
 }
 
 func (c *cmdProjectInfo) Command() *cobra.Command {
@@ -800,8 +806,18 @@ func (c *cmdProjectInfo) Command() *cobra.Command {
 	cmd.Use = usage("info", i18n.G("[<remote>:]<project> <key>"))
 	cmd.Short = i18n.G("Get a summary of resource allocations")
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
-		`Get a summary of resource allocations`))
+		`Get a summary of resource allocations
+
+With --usage, the resource usage history of the project is shown instead.
+The start and end of the history can be dates (e.g. 2023-10-01) or RFC3339 timestamps.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`incus project info default --usage --start=2023-10-01 --end=2023-11-01 --format=csv
+    Show the daily resource usage of the default project in October 2023 as CSV.`))
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.Flags().BoolVar(&c.flagUsage, "usage", false, i18n.G("Show the project's resource usage history"))
+	cmd.Flags().StringVar(&c.flagStart, "start", "", i18n.G("Start of the resource usage history (defaults to 30 days before the end)")+"``")
+	cmd.Flags().StringVar(&c.flagEnd, "end", "", i18n.G("End of the resource usage history (defaults to now)")+"``")
+	cmd.Flags().StringVar(&c.flagStep, "step", "1d", i18n.G("Duration of each entry of the resource usage history (e.g. 1h or 7d)")+"``")
 
 	cmd.RunE = c.Run
 
@@ -827,6 +843,10 @@ func (c *cmdProjectInfo) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Missing project name"))
 	}
 
+	if c.flagUsage {
+		return c.projectUsage(resource.server, resource.name)
+	}
+
 	// Get the current allocations
 	projectState, err := resource.server.GetProjectState(resource.name)
 	if err != nil {
@@ -834,7 +854,7 @@ func (c *cmdProjectInfo) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Render the output
-	byteLimits := []string{"disk", "memory"}
+	byteLimits := []string{"backups-size", "disk", "memory"}
 	data := [][]string{}
 	for k, v := range projectState.Resources {
 		limit := i18n.G("UNLIMITED")
@@ -866,3 +886,71 @@ func (c *cmdProjectInfo) Run(cmd *cobra.Command, args []string) error {
 
 	return cli.RenderTable(c.flagFormat, header, data, projectState)
 }
+
+// parseTime parses a date or an RFC3339 timestamp.
+func (c *cmdProjectInfo) parseTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(i18n.G("Invalid date or timestamp %q"), value)
+	}
+
+	return t, nil
+}
+
+func (c *cmdProjectInfo) projectUsage(d incus.InstanceServer, name string) error {
+	var err error
+
+	end := time.Now()
+	if c.flagEnd != "" {
+		end, err = c.parseTime(c.flagEnd)
+		if err != nil {
+			return err
+		}
+	}
+
+	start := end.Add(-30 * 24 * time.Hour)
+	if c.flagStart != "" {
+		start, err = c.parseTime(c.flagStart)
+		if err != nil {
+			return err
+		}
+	}
+
+	step, err := parseDuration(c.flagStep)
+	if err != nil {
+		return err
+	}
+
+	usage, err := d.GetProjectUsage(name, start, end, step)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04 MST"
+
+	data := [][]string{}
+	for _, entry := range usage.Entries {
+		data = append(data, []string{
+			entry.Timestamp.Local().Format(layout),
+			fmt.Sprintf("%.2f", entry.ContainerHours),
+			fmt.Sprintf("%.2f", entry.VirtualMachineHours),
+			fmt.Sprintf("%.2f", entry.CPUHours),
+			fmt.Sprintf("%.2f", entry.DiskGBHours),
+		})
+	}
+
+	header := []string{
+		i18n.G("TIME"),
+		i18n.G("CONTAINER HOURS"),
+		i18n.G("VIRTUAL-MACHINE HOURS"),
+		i18n.G("CPU HOURS"),
+		i18n.G("DISK GB HOURS"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, usage)
+}
@@ -23,6 +23,7 @@ type cmdPublish struct {
 	flagMakePublic           bool
 	flagForce                bool
 	flagReuse                bool
+	flagStateful             bool
 }
 
 func (c *cmdPublish) Command() *cobra.Command {
@@ -39,6 +40,7 @@ func (c *cmdPublish) Command() *cobra.Command {
 	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Compression algorithm to use (`none` for uncompressed)"))
 	cmd.Flags().StringVar(&c.flagExpiresAt, "expire", "", i18n.G("Image expiration date (format: rfc3339)")+"``")
 	cmd.Flags().BoolVar(&c.flagReuse, "reuse", false, i18n.G("If the image alias already exists, delete and create a new one"))
+	cmd.Flags().BoolVar(&c.flagStateful, "stateful", false, i18n.G("Include the runtime state of the container in the image"))
 
 	return cmd
 }
@@ -96,7 +98,8 @@ func (c *cmdPublish) Run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if !instance.IsSnapshot(cName) {
+	// Running containers are checkpointed and resumed by the server when publishing statefully.
+	if !instance.IsSnapshot(cName) && !c.flagStateful {
 		ct, etag, err := s.GetInstance(cName)
 		if err != nil {
 			return err
@@ -205,6 +208,7 @@ func (c *cmdPublish) Run(cmd *cobra.Command, args []string) error {
 			Name: cName,
 		},
 		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		Stateful:             c.flagStateful,
 	}
 
 	req.Properties = properties
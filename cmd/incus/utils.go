@@ -7,7 +7,9 @@ import (
 	"os/exec"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lxc/incus/client"
 	"github.com/lxc/incus/internal/i18n"
@@ -495,3 +497,22 @@ func removeElementsFromSlice[T comparable](list []T, elements ...T) []T {
 
 	return list
 }
+
+// parseDuration parses a duration which may also be expressed in days (e.g. 7d).
+func parseDuration(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf(i18n.G("Invalid duration %q"), value)
+		}
+
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf(i18n.G("Invalid duration %q"), value)
+	}
+
+	return duration, nil
+}
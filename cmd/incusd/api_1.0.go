@@ -48,11 +48,13 @@ var api10 = []APIEndpoint{
 	clusterNodeStateCmd,
 	clusterNodesCmd,
 	clusterCertificateCmd,
+	clusterUpgradeCmd,
 	instanceBackupCmd,
 	instanceBackupExportCmd,
 	instanceBackupsCmd,
 	instanceCmd,
 	instanceConsoleCmd,
+	instanceDebugMemoryCmd,
 	instanceExecCmd,
 	instanceFileCmd,
 	instanceExecOutputCmd,
@@ -67,6 +69,7 @@ var api10 = []APIEndpoint{
 	instanceSnapshotCmd,
 	instanceSnapshotsCmd,
 	instanceStateCmd,
+	instanceUsageCmd,
 	eventsCmd,
 	imageAliasCmd,
 	imageAliasesCmd,
@@ -78,6 +81,7 @@ var api10 = []APIEndpoint{
 	metadataConfigurationCmd,
 	networkCmd,
 	networkLeasesCmd,
+	networkFlowsCmd,
 	networksCmd,
 	networkStateCmd,
 	networkACLCmd,
@@ -91,6 +95,7 @@ var api10 = []APIEndpoint{
 	networkPeerCmd,
 	networkPeersCmd,
 	networkZoneCmd,
+	networkZoneDNSSECCmd,
 	networkZonesCmd,
 	networkZoneRecordCmd,
 	networkZoneRecordsCmd,
@@ -103,6 +108,8 @@ var api10 = []APIEndpoint{
 	projectCmd,
 	projectsCmd,
 	projectStateCmd,
+	projectUsageCmd,
+	scriptletDataCmd,
 	storagePoolCmd,
 	storagePoolResourcesCmd,
 	storagePoolsCmd,
@@ -777,6 +784,7 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 
 	bgpChanged := false
 	dnsChanged := false
+	dnsResolverChanged := false
 	lokiChanged := false
 	acmeDomainChanged := false
 	acmeCAURLChanged := false
@@ -811,6 +819,8 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 
 		case "core.bgp_asn":
 			bgpChanged = true
+		case "core.dns_resolver", "core.dns_forwarders", "core.dns_resolver_subnets":
+			dnsResolverChanged = true
 		case "loki.api.url":
 			fallthrough
 		case "loki.auth.username":
@@ -932,6 +942,15 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	if dnsResolverChanged {
+		enabled, forwarders, subnets := clusterConfig.DNSResolver()
+
+		err := s.DNS.UpdateResolver(enabled, forwarders, subnets)
+		if err != nil {
+			return fmt.Errorf("Failed reconfiguring DNS resolver: %w", err)
+		}
+	}
+
 	if lokiChanged {
 		lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiLabels, lokiLoglevel, lokiTypes := clusterConfig.LokiServer()
 
@@ -961,6 +980,24 @@ func doApi10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	// Compile and load the instance validation scriptlet.
+	value, ok = clusterChanged["instances.validation.scriptlet"]
+	if ok {
+		err := scriptletLoad.InstanceValidationSet(value)
+		if err != nil {
+			return fmt.Errorf("Failed saving instance validation scriptlet: %w", err)
+		}
+	}
+
+	// Compile and load the project validation scriptlet.
+	value, ok = clusterChanged["projects.validation.scriptlet"]
+	if ok {
+		err := scriptletLoad.ProjectValidationSet(value)
+		if err != nil {
+			return fmt.Errorf("Failed saving project validation scriptlet: %w", err)
+		}
+	}
+
 	if oidcChanged {
 		oidcIssuer, oidcClientID, oidcAudience := clusterConfig.OIDCServer()
 
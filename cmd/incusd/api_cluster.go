@@ -14,6 +14,7 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/gorilla/mux"
 
@@ -48,6 +49,7 @@ import (
 	apiScriptlet "github.com/lxc/incus/shared/api/scriptlet"
 	"github.com/lxc/incus/shared/logger"
 	"github.com/lxc/incus/shared/osarch"
+	"github.com/lxc/incus/shared/subprocess"
 	localtls "github.com/lxc/incus/shared/tls"
 	"github.com/lxc/incus/shared/util"
 	"github.com/lxc/incus/shared/validate"
@@ -1693,6 +1695,23 @@ func updateClusterNode(s *state.State, gateway *cluster.Gateway, r *http.Request
 			}
 		}
 
+		// Keep the volatile keys as they are.
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		for k := range req.Config {
+			if strings.HasPrefix(k, "volatile.") {
+				delete(req.Config, k)
+			}
+		}
+
+		for k, v := range nodeInfo.Config {
+			if strings.HasPrefix(k, "volatile.") {
+				req.Config[k] = v
+			}
+		}
+
 		// Update node config.
 		err = tx.UpdateNodeConfig(ctx, nodeInfo.ID, req.Config)
 		if err != nil {
@@ -1781,6 +1800,53 @@ func clusterValidateConfig(config map[string]string) error {
 		//  defaultdesc: `all`
 		//  shortdesc: Controls how instances are scheduled to run on this member
 		"scheduler.instance": validate.Optional(validate.IsOneOf("all", "group", "manual")),
+
+		// gendoc:generate(entity=cluster, group=cluster, key=maintenance.schedule)
+		// Cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases
+		// (`@hourly`, `@daily`, `@weekly`, `@monthly`, `@annually`, `@yearly`) or a comma-separated list of cron
+		// expressions, in the time zone of the cluster leader.
+		// See {ref}`cluster-maintenance-windows` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: When the maintenance windows of the member start
+		"maintenance.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@weekly", "@monthly", "@annually", "@yearly"})),
+
+		// gendoc:generate(entity=cluster, group=cluster, key=maintenance.duration)
+		// Specify the duration with a number and a unit (for example, `30m` or `4h`).
+		// ---
+		//  type: string
+		//  defaultdesc: `1h`
+		//  shortdesc: How long the maintenance windows of the member last
+		"maintenance.duration": validate.Optional(func(value string) error {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+
+			if duration <= 0 {
+				return fmt.Errorf("The duration must be positive")
+			}
+
+			return nil
+		}),
+
+		// gendoc:generate(entity=cluster, group=cluster, key=maintenance.evacuate)
+		// Possible values are `auto`, `migrate`, `live-migrate` and `stop`.
+		// With `auto`, each instance is handled according to its {config:option}`instance-miscellaneous:cluster.evacuate` configuration.
+		// ---
+		//  type: string
+		//  defaultdesc: `auto`
+		//  shortdesc: How instances are evacuated at the start of a maintenance window
+		"maintenance.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop")),
+
+		// gendoc:generate(entity=cluster, group=cluster, key=maintenance.restore)
+		// Possible values are `auto`, to restore the member at the end of the maintenance window, and `manual`,
+		// to keep it evacuated until it is restored with `incus cluster restore`.
+		// ---
+		//  type: string
+		//  defaultdesc: `auto`
+		//  shortdesc: How the member is restored after a maintenance window
+		"maintenance.restore": validate.Optional(validate.IsOneOf("auto", "manual")),
 	}
 
 	for k, v := range config {
@@ -1795,6 +1861,26 @@ func clusterValidateConfig(config map[string]string) error {
 			continue
 		}
 
+		// Volatile keys are managed by the server.
+		if strings.HasPrefix(k, "volatile.") {
+			continue
+		}
+
+		// gendoc:generate(entity=cluster, group=cluster, key=label.*)
+		// Labels can be used to target the members with a given label value, for example `@rack=a1`.
+		// See {ref}`cluster-member-labels` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Label of the member
+		if strings.HasPrefix(k, db.ClusterMemberLabelPrefix) {
+			err := clusterValidateLabel(strings.TrimPrefix(k, db.ClusterMemberLabelPrefix), v)
+			if err != nil {
+				return fmt.Errorf("Invalid cluster configuration key %q value: %w", k, err)
+			}
+
+			continue
+		}
+
 		validator, ok := clusterConfigKeys[k]
 		if !ok {
 			return fmt.Errorf("Invalid cluster configuration key %q", k)
@@ -1809,6 +1895,24 @@ func clusterValidateConfig(config map[string]string) error {
 	return nil
 }
 
+// clusterValidateLabel validates the name and value of a cluster member label.
+// Both are used in cluster group selectors, so they're limited to a safe set of characters.
+func clusterValidateLabel(name string, value string) error {
+	if name == "" {
+		return fmt.Errorf("Label names may not be empty")
+	}
+
+	for _, part := range []string{name, value} {
+		for _, r := range part {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.:", r) {
+				return fmt.Errorf("Labels may only contain letters, digits, dashes, underscores, dots and colons")
+			}
+		}
+	}
+
+	return nil
+}
+
 // swagger:operation POST /1.0/cluster/members/{name} cluster cluster_member_post
 //
 //	Rename the cluster member
@@ -2894,6 +2998,25 @@ func clusterNodeStateGet(d *Daemon, r *http.Request) response.Response {
 
 	s := d.State()
 
+	// An offline member can't report its own state, only return its healing history.
+	var member db.NodeInfo
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		member, err = tx.GetNodeByName(ctx, memberName)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if member.Name != s.ServerName && member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+		healing, err := cluster.MemberHealing(r.Context(), s, memberName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.SyncResponse(true, api.ClusterMemberState{Healing: healing})
+	}
+
 	// Forward request.
 	resp := forwardedResponseToNode(s, r, memberName)
 	if resp != nil {
@@ -3039,7 +3162,17 @@ func clusterNodeStatePost(d *Daemon, r *http.Request) response.Response {
 }
 
 func internalClusterHeal(d *Daemon, r *http.Request) response.Response {
+	memberName, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	migrateFunc := func(s *state.State, r *http.Request, inst instance.Instance, targetMemberInfo *db.NodeInfo, live bool, startInstance bool, metadata map[string]any, op *operations.Operation) error {
+		// Only heal the instances which are left for Incus to handle on evacuation.
+		if !util.ValueInSlice(inst.ExpandedConfig()["cluster.evacuate"], []string{"", "auto"}) {
+			return nil
+		}
+
 		// This returns an error if the instance's storage pool is local.
 		// Since we only care about remote backed instances, this can be ignored and return nil instead.
 		poolName, err := inst.StoragePool()
@@ -3061,48 +3194,92 @@ func internalClusterHeal(d *Daemon, r *http.Request) response.Response {
 			return nil
 		}
 
-		// Migrate the instance.
-		req := api.InstancePost{
-			Migration: true,
+		healingAction := db.NodeHealingAction{
+			Action:   "instance-moved",
+			Project:  inst.Project().Name,
+			Instance: inst.Name(),
+			Target:   targetMemberInfo.Name,
 		}
 
-		dest, err := cluster.Connect(targetMemberInfo.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
-		if err != nil {
-			return err
+		// The member is offline so rely on the last recorded power state to find out if the instance was running.
+		if inst.LocalConfig()["volatile.last_state.power"] == instance.PowerStateRunning {
+			startInstance = true
 		}
 
-		dest = dest.UseProject(inst.Project().Name)
-		dest = dest.UseTarget(targetMemberInfo.Name)
-
-		migrateOp, err := dest.MigrateInstance(inst.Name(), req)
+		err = clusterHealInstance(s, inst, targetMemberInfo, startInstance)
 		if err != nil {
-			return err
+			healingAction.Action = "instance-failed"
+			healingAction.Message = err.Error()
 		}
 
-		err = migrateOp.Wait()
-		if err != nil {
-			return err
-		}
+		clusterHealingRecord(context.TODO(), s, memberName, healingAction)
 
-		if !startInstance || live {
-			return nil
-		}
+		return err
+	}
 
-		// Start it back up on target.
-		startOp, err := dest.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "start"}, "")
-		if err != nil {
-			return err
-		}
+	return evacuateClusterMember(d.State(), d.gateway, r, "migrate", nil, migrateFunc)
+}
+
+// clusterHealInstance moves a remote backed instance of an offline member to the target member and starts
+// it back up if needed. The instance can't be live-migrated off an offline member, so it always needs
+// to be started again.
+func clusterHealInstance(s *state.State, inst instance.Instance, targetMemberInfo *db.NodeInfo, startInstance bool) error {
+	// Migrate the instance.
+	req := api.InstancePost{
+		Migration: true,
+	}
+
+	dest, err := cluster.Connect(targetMemberInfo.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return err
+	}
+
+	dest = dest.UseProject(inst.Project().Name)
+	dest = dest.UseTarget(targetMemberInfo.Name)
+
+	migrateOp, err := dest.MigrateInstance(inst.Name(), req)
+	if err != nil {
+		return err
+	}
+
+	err = migrateOp.Wait()
+	if err != nil {
+		return err
+	}
+
+	if !startInstance {
+		return nil
+	}
+
+	// Start it back up on target.
+	startOp, err := dest.UpdateInstanceState(inst.Name(), api.InstanceStatePut{Action: "start"}, "")
+	if err != nil {
+		return err
+	}
+
+	err = startOp.Wait()
+	if err != nil {
+		return err
+	}
 
-		err = startOp.Wait()
+	return nil
+}
+
+// clusterHealingRecord records a healing action in the history of the given cluster member.
+func clusterHealingRecord(ctx context.Context, s *state.State, memberName string, action db.NodeHealingAction) {
+	action.Date = time.Now().UTC()
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		member, err := tx.GetNodeByName(ctx, memberName)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return tx.CreateNodeHealingAction(ctx, member.ID, action)
+	})
+	if err != nil {
+		logger.Warn("Failed recording healing action", logger.Ctx{"member": memberName, "action": action.Action, "err": err})
 	}
-
-	return evacuateClusterMember(d.State(), d.gateway, r, "migrate", nil, migrateFunc)
 }
 
 func evacuateClusterSetState(s *state.State, name string, state int) error {
@@ -3533,6 +3710,13 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 		}
 
 		revert.Success()
+
+		// End the maintenance of the member if it was evacuated for it.
+		err = clusterMaintenanceEnd(context.TODO(), s, originName)
+		if err != nil {
+			logger.Warn("Failed ending cluster member maintenance", logger.Ctx{"member": originName, "err": err})
+		}
+
 		return nil
 	}
 
@@ -4317,6 +4501,10 @@ func clusterGroupValidateName(name string) error {
 		return fmt.Errorf("Cluster group names may not contain quotes")
 	}
 
+	if strings.Contains(name, "=") {
+		return fmt.Errorf("Cluster group names may not contain equal signs")
+	}
+
 	if name == "*" {
 		return fmt.Errorf("Reserved cluster group name")
 	}
@@ -4372,12 +4560,14 @@ func evacuateClusterSelectTarget(ctx context.Context, s *state.State, gateway *c
 	}
 
 	// If target member not specified yet, then find the least loaded cluster member which
-	// supports the instance's architecture.
+	// supports the instance's architecture, taking the instance's placement group into account.
 	if targetMemberInfo == nil {
 		var err error
 
+		expandedConfig := inst.ExpandedConfig()
+
 		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-			targetMemberInfo, err = tx.GetNodeWithLeastInstances(ctx, candidateMembers)
+			targetMemberInfo, err = tx.GetNodeForPlacementGroup(ctx, candidateMembers, inst.Project().Name, expandedConfig["placement.group"], expandedConfig["placement.policy"])
 			if err != nil {
 				return err
 			}
@@ -4477,6 +4667,45 @@ func autoHealClusterTask(d *Daemon) (task.Func, task.Schedule) {
 	return f, task.Every(time.Minute)
 }
 
+// clusterHealingFenceMaxAttempts is the number of times fencing an offline member is attempted before giving up.
+const clusterHealingFenceMaxAttempts = 5
+
+// clusterHealingFenceBackoff is the delay before fencing is retried after the first failure. It doubles on each
+// subsequent failure.
+const clusterHealingFenceBackoff = time.Minute
+
+// clusterHealingFenceRetry returns the time after which an offline member can be fenced, given its healing actions
+// and the time it was last seen online. Only failures recorded since then are taken into account, so the attempts
+// are reset once the member comes back. An error is returned if fencing should no longer be attempted.
+func clusterHealingFenceRetry(actions []db.NodeHealingAction, offlineSince time.Time) (time.Time, error) {
+	failures := 0
+	var lastFailure time.Time
+
+	for _, action := range actions {
+		if action.Date.Before(offlineSince) {
+			continue
+		}
+
+		if action.Action != "fencing-failed" {
+			failures = 0
+			continue
+		}
+
+		failures++
+		lastFailure = action.Date
+	}
+
+	if failures == 0 {
+		return time.Time{}, nil
+	}
+
+	if failures >= clusterHealingFenceMaxAttempts {
+		return time.Time{}, fmt.Errorf("Fencing failed %d times, giving up until the member is back online", failures)
+	}
+
+	return lastFailure.Add(clusterHealingFenceBackoff << (failures - 1)), nil
+}
+
 func autoHealCluster(ctx context.Context, s *state.State, offlineMembers []db.NodeInfo) error {
 	logger.Info("Healing cluster instances")
 
@@ -4485,12 +4714,71 @@ func autoHealCluster(ctx context.Context, s *state.State, offlineMembers []db.No
 		return err
 	}
 
+	// Use the stored value rather than the loaded one, so a fence command that failed validation on this
+	// member makes fencing fail instead of being skipped.
+	var fenceCommand string
+	var healingActions map[string][]db.NodeHealingAction
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		values, err := tx.Config(ctx)
+		if err != nil {
+			return err
+		}
+
+		fenceCommand = values["cluster.healing_fence_command"]
+
+		healingActions = make(map[string][]db.NodeHealingAction, len(offlineMembers))
+		for _, member := range offlineMembers {
+			healingActions[member.Name], err = tx.GetNodeHealingActions(ctx, member.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading healing state: %w", err)
+	}
+
 	for _, member := range offlineMembers {
+		// Make sure the member is really down before its instances get started elsewhere.
+		if fenceCommand != "" {
+			retry, err := clusterHealingFenceRetry(healingActions[member.Name], member.Heartbeat)
+			if err != nil {
+				logger.Warn("Not fencing cluster member", logger.Ctx{"member": member.Name, "err": err})
+				continue
+			}
+
+			if time.Now().Before(retry) {
+				logger.Debug("Delaying fencing of cluster member", logger.Ctx{"member": member.Name, "retry": retry})
+				continue
+			}
+
+			logger.Info("Fencing cluster member", logger.Ctx{"member": member.Name})
+			_, err = subprocess.RunCommandContext(ctx, fenceCommand, member.Name, member.Address)
+			if err != nil {
+				logger.Error("Failed fencing cluster member, skipping healing", logger.Ctx{"member": member.Name, "err": err})
+				clusterHealingRecord(ctx, s, member.Name, db.NodeHealingAction{Action: "fencing-failed", Message: err.Error()})
+				continue
+			}
+
+			clusterHealingRecord(ctx, s, member.Name, db.NodeHealingAction{Action: "fenced"})
+			s.Events.SendLifecycle(project.Default, lifecycle.ClusterMemberFenced.Event(member.Name, nil, nil))
+		}
+
 		logger.Info("Healing cluster member instances", logger.Ctx{"member": member.Name})
-		_, _, err = dest.RawQuery("POST", fmt.Sprintf("/internal/cluster/heal/%s", member.Name), nil, "")
+		op, _, err := dest.RawOperation("POST", fmt.Sprintf("/internal/cluster/heal/%s", member.Name), nil, "")
 		if err != nil {
 			return fmt.Errorf("Failed evacuating cluster member %q: %w", member.Name, err)
 		}
+
+		err = op.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed evacuating cluster member %q: %w", member.Name, err)
+		}
+
+		clusterHealingRecord(ctx, s, member.Name, db.NodeHealingAction{Action: "healed"})
+		s.Events.SendLifecycle(project.Default, lifecycle.ClusterMemberHealed.Event(member.Name, nil, nil))
 	}
 
 	logger.Info("Done healing cluster instances")
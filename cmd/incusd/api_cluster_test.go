@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/lxc/incus/client"
+	"github.com/lxc/incus/internal/server/db"
 	"github.com/lxc/incus/shared/api"
 )
 
@@ -27,6 +29,62 @@ func allocatePort() (int, error) {
 	return l.Addr().(*net.TCPAddr).Port, l.Close()
 }
 
+func TestCluster_HealingFenceRetry(t *testing.T) {
+	offlineSince := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int, action string) db.NodeHealingAction {
+		return db.NodeHealingAction{Date: offlineSince.Add(time.Duration(minutes) * time.Minute), Action: action}
+	}
+
+	tests := []struct {
+		name    string
+		actions []db.NodeHealingAction
+		retry   time.Time
+		err     string
+	}{
+		{
+			name:    "No failures",
+			actions: []db.NodeHealingAction{at(-10, "fenced"), at(-9, "healed")},
+		},
+		{
+			name:    "First failure waits one interval",
+			actions: []db.NodeHealingAction{at(2, "fencing-failed")},
+			retry:   offlineSince.Add(3 * time.Minute),
+		},
+		{
+			name:    "Delay doubles on each failure",
+			actions: []db.NodeHealingAction{at(2, "fencing-failed"), at(3, "fencing-failed"), at(5, "fencing-failed")},
+			retry:   offlineSince.Add(9 * time.Minute),
+		},
+		{
+			name:    "Failures from a previous outage are ignored",
+			actions: []db.NodeHealingAction{at(-20, "fencing-failed"), at(-19, "fencing-failed"), at(1, "fencing-failed")},
+			retry:   offlineSince.Add(2 * time.Minute),
+		},
+		{
+			name:    "Other actions reset the failures",
+			actions: []db.NodeHealingAction{at(1, "fencing-failed"), at(2, "fencing-failed"), at(4, "fenced")},
+		},
+		{
+			name:    "Gives up after too many failures",
+			actions: []db.NodeHealingAction{at(1, "fencing-failed"), at(2, "fencing-failed"), at(4, "fencing-failed"), at(8, "fencing-failed"), at(16, "fencing-failed")},
+			err:     "Fencing failed 5 times, giving up until the member is back online",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			retry, err := clusterHealingFenceRetry(test.actions, offlineSince)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.retry, retry)
+		})
+	}
+}
+
 // A node which is already configured for networking can be converted to a
 // single-node cluster.
 func TestCluster_Bootstrap(t *testing.T) {
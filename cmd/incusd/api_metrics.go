@@ -17,6 +17,7 @@ import (
 	instanceDrivers "github.com/lxc/incus/internal/server/instance/drivers"
 	"github.com/lxc/incus/internal/server/locking"
 	"github.com/lxc/incus/internal/server/metrics"
+	"github.com/lxc/incus/internal/server/network"
 	"github.com/lxc/incus/internal/server/response"
 	"github.com/lxc/incus/shared/api"
 	"github.com/lxc/incus/shared/logger"
@@ -255,6 +256,17 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 	wg.Wait()
 	close(instMetricsCh)
 
+	// Add the traffic recorded by network flow logging.
+	for _, filter := range projectsToFetch {
+		projectName := *filter.Project
+
+		if newMetrics[projectName] == nil {
+			newMetrics[projectName] = metrics.NewMetricSet(nil)
+		}
+
+		newMetrics[projectName].Merge(network.FlowMetrics(projectName))
+	}
+
 	// Put the new data in the global cache and in response.
 	metricsCacheLock.Lock()
 
@@ -23,10 +23,12 @@ import (
 	projecthelpers "github.com/lxc/incus/internal/server/project"
 	"github.com/lxc/incus/internal/server/request"
 	"github.com/lxc/incus/internal/server/response"
+	"github.com/lxc/incus/internal/server/scriptlet"
 	"github.com/lxc/incus/internal/server/state"
 	localUtil "github.com/lxc/incus/internal/server/util"
 	"github.com/lxc/incus/internal/version"
 	"github.com/lxc/incus/shared/api"
+	apiScriptlet "github.com/lxc/incus/shared/api/scriptlet"
 	"github.com/lxc/incus/shared/logger"
 	"github.com/lxc/incus/shared/util"
 	"github.com/lxc/incus/shared/validate"
@@ -304,6 +306,11 @@ func projectsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	err = projectValidationRun(r.Context(), s, apiScriptlet.ProjectValidationReasonCreate, project.Name, project.ProjectPut)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	var id int64
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		id, err = cluster.CreateProject(ctx, tx.Tx(), cluster.Project{Description: project.Description, Name: project.Name})
@@ -651,6 +658,19 @@ func projectPatch(d *Daemon, r *http.Request) response.Response {
 	return projectChange(s, project, req)
 }
 
+// projectValidationRun runs the project validation scriptlet, if configured, against the proposed project.
+func projectValidationRun(ctx context.Context, s *state.State, reason string, name string, req api.ProjectPut) error {
+	if s.GlobalConfig.ProjectsValidationScriptlet() == "" {
+		return nil
+	}
+
+	return scriptlet.ProjectValidationRun(ctx, logger.Log, &apiScriptlet.ProjectValidation{
+		ProjectPut: req,
+		Name:       name,
+		Reason:     reason,
+	})
+}
+
 // Common logic between PUT and PATCH.
 func projectChange(s *state.State, project *api.Project, req api.ProjectPut) response.Response {
 	// Make a list of config keys that have changed.
@@ -710,6 +730,11 @@ func projectChange(s *state.State, project *api.Project, req api.ProjectPut) res
 		return response.BadRequest(err)
 	}
 
+	err = projectValidationRun(context.TODO(), s, apiScriptlet.ProjectValidationReasonUpdate, project.Name, req)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Update the database entry.
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		err := projecthelpers.AllowProjectUpdate(tx, project.Name, req.Config, configChanged)
@@ -802,6 +827,28 @@ func projectPost(d *Daemon, r *http.Request) response.Response {
 		return response.Forbidden(fmt.Errorf("The 'default' project cannot be renamed"))
 	}
 
+	// Run the validation scriptlet against the renamed project.
+	if s.GlobalConfig.ProjectsValidationScriptlet() != "" {
+		var project *api.Project
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			dbProject, err := cluster.GetProject(ctx, tx.Tx(), name)
+			if err != nil {
+				return err
+			}
+
+			project, err = dbProject.ToAPI(ctx, tx.Tx())
+			return err
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = projectValidationRun(r.Context(), s, apiScriptlet.ProjectValidationReasonRename, req.Name, project.Writable())
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// Perform the rename.
 	run := func(op *operations.Operation) error {
 		var id int64
@@ -1200,6 +1247,45 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: integer
 		//  shortdesc: Maximum number of networks that the project can have
 		"limits.networks": validate.Optional(validate.IsUint32),
+		// gendoc:generate(entity=project, group=limits, key=limits.networks.forwards)
+		// This value is the maximum number of network forwards created by the project, including the ones on the
+		// networks of the `default` project if `features.networks` isn't enabled.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of network forwards that the project can have
+		"limits.networks.forwards": validate.Optional(validate.IsUint32),
+		// gendoc:generate(entity=project, group=limits, key=limits.networks.load_balancers)
+		// This value is the maximum number of network load balancers created by the project, including the ones on the
+		// networks of the `default` project if `features.networks` isn't enabled.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of network load balancers that the project can have
+		"limits.networks.load_balancers": validate.Optional(validate.IsUint32),
+		// gendoc:generate(entity=project, group=limits, key=limits.buckets)
+		// This value is the maximum number of storage buckets in the project, across all storage pools.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of storage buckets that the project can have
+		"limits.buckets": validate.Optional(validate.IsUint32),
+		// gendoc:generate(entity=project, group=limits, key=limits.snapshots)
+		// This value is the maximum number of snapshots of each instance and custom storage volume of the project.
+		// Scheduled snapshots are skipped once the limit is reached.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of snapshots per instance or custom volume
+		"limits.snapshots": validate.Optional(validate.IsUint32),
+		// gendoc:generate(entity=project, group=limits, key=limits.backups)
+		// This value is the maximum number of instance and custom storage volume backups in the project.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of backups that the project can have
+		"limits.backups": validate.Optional(validate.IsUint32),
+		// gendoc:generate(entity=project, group=limits, key=limits.backups.size)
+		// This value is the maximum total size of the instance and custom storage volume backups in the project.
+		// ---
+		//  type: string
+		//  shortdesc: Maximum total size of the backups that the project can have
+		"limits.backups.size": validate.Optional(validate.IsSize),
 		// gendoc:generate(entity=project, group=restricted, key=restricted)
 		// This option must be enabled to allow the `restricted.*` keys to take effect.
 		// To temporarily remove the restrictions, you can disable this option instead of clearing the related keys.
@@ -216,12 +216,37 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error closing tar file: %w", err)
 	}
 
+	err = backupRecordSize(s, sourceInst.Project().Name, target, func(ctx context.Context, tx *db.ClusterTx, size int64) error {
+		return tx.UpdateInstanceBackupSize(ctx, args.Name, size)
+	})
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	s.Events.SendLifecycle(sourceInst.Project().Name, lifecycle.InstanceBackupCreated.Event(args.Name, b.Instance(), nil))
 
 	return nil
 }
 
+// backupRecordSize records the size of the backup tarball written to target and checks that the backups of the
+// project don't exceed its "limits.backups.size".
+func backupRecordSize(s *state.State, projectName string, target string, updateSize func(ctx context.Context, tx *db.ClusterTx, size int64) error) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return fmt.Errorf("Failed getting backup size: %w", err)
+	}
+
+	return s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := updateSize(ctx, tx, info.Size())
+		if err != nil {
+			return err
+		}
+
+		return project.AllowBackupSize(tx, projectName)
+	})
+}
+
 // backupWriteIndex generates an index.yaml file and then writes it to the root of the backup tarball.
 func backupWriteIndex(sourceInst instance.Instance, pool storagePools.Pool, optimized bool, snapshots bool, tarWriter *instancewriter.InstanceTarWriter) error {
 	// Indicate whether the driver will include a driver-specific optimized header.
@@ -508,6 +533,13 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 		return fmt.Errorf("Error closing tar file: %w", err)
 	}
 
+	err = backupRecordSize(s, projectName, target, func(ctx context.Context, tx *db.ClusterTx, size int64) error {
+		return tx.UpdateStoragePoolVolumeBackupSize(ctx, args.Name, size)
+	})
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/lxc/incus/client"
+	"github.com/lxc/incus/internal/server/cluster"
+	"github.com/lxc/incus/internal/server/db"
+	"github.com/lxc/incus/internal/server/lifecycle"
+	"github.com/lxc/incus/internal/server/project"
+	"github.com/lxc/incus/internal/server/state"
+	"github.com/lxc/incus/internal/server/task"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+	"github.com/lxc/incus/shared/util"
+)
+
+// clusterMaintenanceStartKey is the member config key recording the start of the last maintenance window
+// handled for the member.
+const clusterMaintenanceStartKey = "volatile.maintenance.start"
+
+// clusterMaintenanceEndKey is the member config key recording the end of the maintenance window of a member
+// evacuated for maintenance. It is removed once the member is restored.
+const clusterMaintenanceEndKey = "volatile.maintenance.end"
+
+// clusterMaintenanceDefaultDuration is the duration of the maintenance windows if maintenance.duration isn't set.
+const clusterMaintenanceDefaultDuration = time.Hour
+
+// clusterMaintenanceWindow returns whether now is within one of the maintenance windows starting on the given
+// schedule and lasting for the given duration, along with the start and end of that window.
+func clusterMaintenanceWindow(schedule string, duration time.Duration, now time.Time) (bool, time.Time, time.Time, error) {
+	for _, spec := range util.SplitNTrimSpace(strings.ToLower(schedule), ",", -1, true) {
+		sched, err := cron.ParseStandard(spec)
+		if err != nil {
+			return false, time.Time{}, time.Time{}, fmt.Errorf("Failed parsing maintenance schedule %q: %w", spec, err)
+		}
+
+		// The first window starting after now minus its duration is the current one if it has started already.
+		start := sched.Next(now.Add(-duration))
+		if !start.After(now) {
+			return true, start, start.Add(duration), nil
+		}
+	}
+
+	return false, time.Time{}, time.Time{}, nil
+}
+
+// clusterMaintenanceDuration returns the duration of the maintenance windows of a cluster member.
+func clusterMaintenanceDuration(config map[string]string) (time.Duration, error) {
+	if config["maintenance.duration"] == "" {
+		return clusterMaintenanceDefaultDuration, nil
+	}
+
+	duration, err := time.ParseDuration(config["maintenance.duration"])
+	if err != nil {
+		return 0, fmt.Errorf("Failed parsing maintenance duration: %w", err)
+	}
+
+	return duration, nil
+}
+
+// clusterMaintenanceCanEvacuate returns whether the given cluster member can be evacuated, that is whether at
+// least one other member is online and not evacuated to take over its instances.
+func clusterMaintenanceCanEvacuate(members []db.NodeInfo, name string, offlineThreshold time.Duration) bool {
+	for _, member := range members {
+		if member.Name == name || member.State != db.ClusterMemberStateCreated || member.IsOffline(offlineThreshold) {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// clusterMaintenanceSetConfig sets the given member config keys, removing those with an empty value.
+func clusterMaintenanceSetConfig(ctx context.Context, s *state.State, name string, keys map[string]string) error {
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		member, err := tx.GetNodeByName(ctx, name)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster member %q: %w", name, err)
+		}
+
+		config := make(map[string]string, len(member.Config)+len(keys))
+		for k, v := range member.Config {
+			config[k] = v
+		}
+
+		for k, v := range keys {
+			if v == "" {
+				delete(config, k)
+			} else {
+				config[k] = v
+			}
+		}
+
+		return tx.UpdateNodeConfig(ctx, member.ID, config)
+	})
+}
+
+// clusterMaintenanceEnd ends the maintenance of a cluster member once it has been restored.
+// Nothing is done if the member wasn't evacuated for maintenance.
+func clusterMaintenanceEnd(ctx context.Context, s *state.State, name string) error {
+	var inMaintenance bool
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		member, err := tx.GetNodeByName(ctx, name)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster member %q: %w", name, err)
+		}
+
+		inMaintenance = member.Config[clusterMaintenanceEndKey] != ""
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !inMaintenance {
+		return nil
+	}
+
+	err = clusterMaintenanceSetConfig(ctx, s, name, map[string]string{clusterMaintenanceEndKey: ""})
+	if err != nil {
+		return err
+	}
+
+	s.Events.SendLifecycle(project.Default, lifecycle.ClusterMemberMaintenanceEnded.Event(name, nil, nil))
+
+	return nil
+}
+
+// clusterMaintenance evacuates the cluster members whose maintenance window has started and restores those
+// whose maintenance window has ended, unless they must be restored manually.
+func clusterMaintenance(ctx context.Context, s *state.State) error {
+	var members []db.NodeInfo
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		members, err = tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var dest incus.InstanceServer
+	changeState := func(name string, req api.ClusterMemberStatePost) error {
+		if dest == nil {
+			c, err := cluster.Connect(s.LocalConfig.ClusterAddress(), s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+			if err != nil {
+				return err
+			}
+
+			dest = c
+		}
+
+		op, err := dest.UpdateClusterMemberState(name, req)
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	}
+
+	offlineThreshold := s.GlobalConfig.OfflineThreshold()
+	now := time.Now()
+
+	for i, member := range members {
+		l := logger.AddContext(logger.Ctx{"member": member.Name})
+
+		// Restore the members whose maintenance window has ended.
+		if member.Config[clusterMaintenanceEndKey] != "" {
+			end, err := time.Parse(time.RFC3339, member.Config[clusterMaintenanceEndKey])
+			if err == nil && now.Before(end) {
+				continue
+			}
+
+			if member.Config["maintenance.restore"] == "manual" || member.IsOffline(offlineThreshold) {
+				continue
+			}
+
+			if member.State != db.ClusterMemberStateEvacuated {
+				err = clusterMaintenanceEnd(ctx, s, member.Name)
+				if err != nil {
+					l.Error("Failed ending cluster member maintenance", logger.Ctx{"err": err})
+				}
+
+				continue
+			}
+
+			l.Info("Restoring cluster member at the end of its maintenance window")
+			err = changeState(member.Name, api.ClusterMemberStatePost{Action: "restore"})
+			if err != nil {
+				l.Error("Failed restoring cluster member after maintenance", logger.Ctx{"err": err})
+				continue
+			}
+
+			members[i].State = db.ClusterMemberStateCreated
+			continue
+		}
+
+		if member.Config["maintenance.schedule"] == "" {
+			continue
+		}
+
+		duration, err := clusterMaintenanceDuration(member.Config)
+		if err != nil {
+			l.Error("Invalid cluster member maintenance window", logger.Ctx{"err": err})
+			continue
+		}
+
+		active, start, end, err := clusterMaintenanceWindow(member.Config["maintenance.schedule"], duration, now)
+		if err != nil {
+			l.Error("Invalid cluster member maintenance window", logger.Ctx{"err": err})
+			continue
+		}
+
+		// Each maintenance window is only handled once, so that restoring the member early isn't undone.
+		windowStart := start.UTC().Format(time.RFC3339)
+		if !active || member.Config[clusterMaintenanceStartKey] == windowStart {
+			continue
+		}
+
+		if member.IsOffline(offlineThreshold) || member.State == db.ClusterMemberStatePending {
+			continue
+		}
+
+		// Members which are already evacuated are left alone, including at the end of the window.
+		if member.State == db.ClusterMemberStateEvacuated {
+			err = clusterMaintenanceSetConfig(ctx, s, member.Name, map[string]string{clusterMaintenanceStartKey: windowStart})
+			if err != nil {
+				l.Error("Failed starting cluster member maintenance", logger.Ctx{"err": err})
+			}
+
+			continue
+		}
+
+		// Don't leave the cluster without any member to run the instances, the evacuation is attempted again
+		// later in the window.
+		if !clusterMaintenanceCanEvacuate(members, member.Name, offlineThreshold) {
+			l.Warn("Not evacuating cluster member for its maintenance window as no other member is available")
+			continue
+		}
+
+		err = clusterMaintenanceSetConfig(ctx, s, member.Name, map[string]string{
+			clusterMaintenanceStartKey: windowStart,
+			clusterMaintenanceEndKey:   end.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			l.Error("Failed starting cluster member maintenance", logger.Ctx{"err": err})
+			continue
+		}
+
+		// Evacuate the member, following the evacuation mode of each instance unless overridden.
+		mode := member.Config["maintenance.evacuate"]
+		if mode == "auto" {
+			mode = ""
+		}
+
+		l.Info("Evacuating cluster member for its maintenance window", logger.Ctx{"end": end})
+		err = changeState(member.Name, api.ClusterMemberStatePost{Action: "evacuate", Mode: mode})
+		if err != nil {
+			l.Error("Failed evacuating cluster member for maintenance", logger.Ctx{"err": err})
+
+			// Forget about the window so that the evacuation is attempted again.
+			err = clusterMaintenanceSetConfig(ctx, s, member.Name, map[string]string{
+				clusterMaintenanceStartKey: member.Config[clusterMaintenanceStartKey],
+				clusterMaintenanceEndKey:   "",
+			})
+			if err != nil {
+				l.Error("Failed resetting cluster member maintenance", logger.Ctx{"err": err})
+			}
+
+			continue
+		}
+
+		members[i].State = db.ClusterMemberStateEvacuated
+
+		s.Events.SendLifecycle(project.Default, lifecycle.ClusterMemberMaintenanceStarted.Event(member.Name, nil, map[string]any{"end": end}))
+	}
+
+	return nil
+}
+
+func autoClusterMaintenanceTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil {
+			if errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				return // Skip maintenance windows if not clustered.
+			}
+
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if s.LocalConfig.ClusterAddress() != leader {
+			return // Skip maintenance windows if not cluster leader.
+		}
+
+		err = clusterMaintenance(ctx, s)
+		if err != nil {
+			logger.Error("Failed handling cluster maintenance windows", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
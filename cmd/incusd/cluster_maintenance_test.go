@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/internal/server/db"
+)
+
+func TestClusterMaintenanceWindow(t *testing.T) {
+	// Daily window from 02:00 to 06:00, or on Sundays from 12:00 to 16:00.
+	schedule := "0 2 * * *, 0 12 * * 0"
+	duration := 4 * time.Hour
+
+	day := func(d int, hour int, minute int) time.Time {
+		return time.Date(2023, 10, d, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		active bool
+		start  time.Time
+	}{
+		{name: "Before the window", now: day(18, 1, 59)},
+		{name: "Start of the window", now: day(18, 2, 0), active: true, start: day(18, 2, 0)},
+		{name: "Within the window", now: day(18, 5, 59), active: true, start: day(18, 2, 0)},
+		{name: "End of the window", now: day(18, 6, 0)},
+		{name: "Not a Sunday", now: day(18, 13, 0)},
+		{name: "Sunday window", now: day(22, 13, 0), active: true, start: day(22, 12, 0)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			active, start, end, err := clusterMaintenanceWindow(schedule, duration, test.now)
+			require.NoError(t, err)
+			assert.Equal(t, test.active, active)
+
+			if test.active {
+				assert.Equal(t, test.start, start)
+				assert.Equal(t, test.start.Add(duration), end)
+			}
+		})
+	}
+}
+
+func TestClusterMaintenanceWindowInvalid(t *testing.T) {
+	_, _, _, err := clusterMaintenanceWindow("0 2 * *", time.Hour, time.Now())
+	assert.Error(t, err)
+}
+
+func TestClusterMaintenanceCanEvacuate(t *testing.T) {
+	now := time.Now()
+	member := func(name string, state int, heartbeat time.Time) db.NodeInfo {
+		return db.NodeInfo{Name: name, State: state, Heartbeat: heartbeat}
+	}
+
+	tests := []struct {
+		name     string
+		members  []db.NodeInfo
+		expected bool
+	}{
+		{
+			name:     "Other member available",
+			members:  []db.NodeInfo{member("m1", db.ClusterMemberStateCreated, now), member("m2", db.ClusterMemberStateCreated, now)},
+			expected: true,
+		},
+		{
+			name:    "Only member",
+			members: []db.NodeInfo{member("m1", db.ClusterMemberStateCreated, now)},
+		},
+		{
+			name:    "Other member evacuated",
+			members: []db.NodeInfo{member("m1", db.ClusterMemberStateCreated, now), member("m2", db.ClusterMemberStateEvacuated, now)},
+		},
+		{
+			name:    "Other member offline",
+			members: []db.NodeInfo{member("m1", db.ClusterMemberStateCreated, now), member("m2", db.ClusterMemberStateCreated, now.Add(-time.Hour))},
+		},
+		{
+			name:    "Other member pending",
+			members: []db.NodeInfo{member("m1", db.ClusterMemberStateCreated, now), member("m2", db.ClusterMemberStatePending, now)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, clusterMaintenanceCanEvacuate(test.members, "m1", 20*time.Second))
+		})
+	}
+}
@@ -0,0 +1,505 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	incus "github.com/lxc/incus/client"
+	internalInstance "github.com/lxc/incus/internal/instance"
+	"github.com/lxc/incus/internal/revert"
+	"github.com/lxc/incus/internal/server/cluster"
+	"github.com/lxc/incus/internal/server/db"
+	dbCluster "github.com/lxc/incus/internal/server/db/cluster"
+	"github.com/lxc/incus/internal/server/db/operationtype"
+	"github.com/lxc/incus/internal/server/instance"
+	"github.com/lxc/incus/internal/server/instance/instancetype"
+	"github.com/lxc/incus/internal/server/operations"
+	"github.com/lxc/incus/internal/server/project"
+	"github.com/lxc/incus/internal/server/state"
+	"github.com/lxc/incus/internal/server/task"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+	"github.com/lxc/incus/shared/util"
+)
+
+// clusterRebalanceMember is the load of a cluster member considered for rebalancing.
+type clusterRebalanceMember struct {
+	name        string
+	cpuLoad     float64 // Load average relative to the number of CPU threads (in percent).
+	memoryUsed  uint64
+	memoryTotal uint64
+}
+
+// score returns the load of the member (in percent), that is the highest of its CPU and memory loads.
+func (m *clusterRebalanceMember) score() float64 {
+	memoryLoad := float64(0)
+	if m.memoryTotal > 0 {
+		memoryLoad = float64(m.memoryUsed) * 100 / float64(m.memoryTotal)
+	}
+
+	return math.Max(m.cpuLoad, memoryLoad)
+}
+
+// clusterRebalanceInstance is a running instance which can be moved by rebalancing.
+type clusterRebalanceInstance struct {
+	project     string
+	name        string
+	location    string
+	memoryUsage uint64
+	live        bool
+	targets     []string // Cluster members the instance can be moved to.
+}
+
+// clusterRebalanceMove is an instance move decided by rebalancing.
+type clusterRebalanceMove struct {
+	instance clusterRebalanceInstance
+	target   string
+}
+
+// clusterRebalancePlan decides which instances to move to balance the load of the cluster members.
+//
+// Instances are moved off the busiest members, largest memory users first, to the least busy member they can be
+// moved to, as long as the load difference between both members exceeds the threshold and the move doesn't make
+// the target busier than the source. The memory usage of each moved instance is accounted for on both members so
+// that a single run doesn't overshoot. The CPU usage of the instances isn't known ahead of time and is only
+// reflected by the next run.
+func clusterRebalancePlan(members []clusterRebalanceMember, instances []clusterRebalanceInstance, threshold float64, batch int) []clusterRebalanceMove {
+	loads := make(map[string]*clusterRebalanceMember, len(members))
+	sorted := make([]*clusterRebalanceMember, 0, len(members))
+	for i := range members {
+		member := members[i]
+		loads[member.name] = &member
+		sorted = append(sorted, &member)
+	}
+
+	// Consider the largest instances first.
+	sort.SliceStable(instances, func(i, j int) bool { return instances[i].memoryUsage > instances[j].memoryUsage })
+
+	moves := []clusterRebalanceMove{}
+	moved := make(map[int]bool, len(instances))
+	for len(moves) < batch {
+		// Look at the busiest members first.
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].score() > sorted[j].score() })
+
+		var next *clusterRebalanceMove
+		nextIndex := -1
+
+	search:
+		for _, source := range sorted {
+			for i, inst := range instances {
+				if moved[i] || inst.location != source.name {
+					continue
+				}
+
+				// Find the least busy member the instance can be moved to.
+				var target *clusterRebalanceMember
+				for _, name := range inst.targets {
+					candidate := loads[name]
+					if candidate == nil || candidate.name == source.name {
+						continue
+					}
+
+					if target == nil || candidate.score() < target.score() {
+						target = candidate
+					}
+				}
+
+				if target == nil || source.score()-target.score() <= threshold {
+					continue
+				}
+
+				after := *target
+				after.memoryUsed += inst.memoryUsage
+				if after.score() >= source.score() {
+					continue
+				}
+
+				next = &clusterRebalanceMove{instance: inst, target: target.name}
+				nextIndex = i
+				break search
+			}
+		}
+
+		if next == nil {
+			break
+		}
+
+		moved[nextIndex] = true
+		moves = append(moves, *next)
+
+		source := loads[next.instance.location]
+		if source.memoryUsed > next.instance.memoryUsage {
+			source.memoryUsed -= next.instance.memoryUsage
+		} else {
+			source.memoryUsed = 0
+		}
+
+		loads[next.target].memoryUsed += next.instance.memoryUsage
+	}
+
+	return moves
+}
+
+// clusterRebalanceMemberLoad returns the current CPU and memory load of a cluster member.
+func clusterRebalanceMemberLoad(client incus.InstanceServer, name string) (*clusterRebalanceMember, error) {
+	resources, err := client.UseTarget(name).GetServerResources()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting resources: %w", err)
+	}
+
+	memberState, _, err := client.GetClusterMemberState(name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting state: %w", err)
+	}
+
+	load := &clusterRebalanceMember{
+		name:        name,
+		memoryUsed:  resources.Memory.Used,
+		memoryTotal: resources.Memory.Total,
+	}
+
+	// Use the 5 minutes load average to avoid reacting to short spikes.
+	if len(memberState.SysInfo.LoadAverages) > 1 && resources.CPU.Total > 0 {
+		load.cpuLoad = memberState.SysInfo.LoadAverages[1] * 100 / float64(resources.CPU.Total)
+	}
+
+	return load, nil
+}
+
+// clusterRebalanceTargets returns the cluster members an instance can be moved to, that is the candidate members
+// allowed by its project which share a cluster group with the member it is running on.
+func clusterRebalanceTargets(ctx context.Context, s *state.State, inst instance.Instance, allMembers []db.NodeInfo) ([]string, error) {
+	var source *db.NodeInfo
+	for i := range allMembers {
+		if allMembers[i].Name == inst.Location() {
+			source = &allMembers[i]
+			break
+		}
+	}
+
+	if source == nil {
+		return nil, fmt.Errorf("Cluster member %q not found", inst.Location())
+	}
+
+	instProject := inst.Project()
+
+	var candidateMembers []db.NodeInfo
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		candidateMembers, err = tx.GetCandidateMembers(ctx, allMembers, []int{inst.Architecture()}, "", project.GetRestrictedClusterGroups(&instProject), s.GlobalConfig.OfflineThreshold())
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []string{}
+	for _, member := range candidateMembers {
+		if member.Name == source.Name {
+			continue
+		}
+
+		for _, group := range member.Groups {
+			if util.ValueInSlice(group, source.Groups) {
+				targets = append(targets, member.Name)
+				break
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// clusterRebalanceMoveInstance moves an instance to another cluster member.
+// Virtual machines are live-migrated while containers are cleanly shut down and started again on the target member.
+func clusterRebalanceMoveInstance(client incus.InstanceServer, inst instance.Instance, move clusterRebalanceMove) error {
+	// Record the move so that the instance isn't moved again during the cooldown.
+	err := inst.VolatileSet(map[string]string{"volatile.rebalance.last_move": strconv.FormatInt(time.Now().Unix(), 10)})
+	if err != nil {
+		return err
+	}
+
+	source := client.UseProject(move.instance.project)
+	dest := source.UseTarget(move.target)
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	if !move.instance.live {
+		// Get the shutdown timeout for the instance.
+		timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+		if err != nil {
+			timeout = evacuateHostShutdownDefaultTimeout
+		}
+
+		// Start with a clean shutdown.
+		err = clusterRebalanceUpdateState(source, move.instance.name, api.InstanceStatePut{Action: "stop", Timeout: timeout})
+		if err != nil {
+			logger.Warn("Failed shutting down instance, forcing stop", logger.Ctx{"project": move.instance.project, "instance": move.instance.name, "err": err})
+
+			// Fallback to forced stop.
+			err = clusterRebalanceUpdateState(source, move.instance.name, api.InstanceStatePut{Action: "stop", Force: true})
+			if err != nil {
+				return fmt.Errorf("Failed stopping instance: %w", err)
+			}
+		}
+
+		// Start the instance back up where it was if it can't be moved.
+		reverter.Add(func() {
+			err := clusterRebalanceUpdateState(source, move.instance.name, api.InstanceStatePut{Action: "start"})
+			if err != nil {
+				logger.Warn("Failed restarting instance after failed move", logger.Ctx{"project": move.instance.project, "instance": move.instance.name, "err": err})
+			}
+		})
+	}
+
+	migrateOp, err := dest.MigrateInstance(move.instance.name, api.InstancePost{Migration: true, Live: move.instance.live})
+	if err != nil {
+		return err
+	}
+
+	err = migrateOp.Wait()
+	if err != nil {
+		return err
+	}
+
+	reverter.Success()
+
+	if move.instance.live {
+		return nil
+	}
+
+	// Start it back up on target.
+	return clusterRebalanceUpdateState(dest, move.instance.name, api.InstanceStatePut{Action: "start"})
+}
+
+// clusterRebalanceUpdateState changes the state of an instance and waits for it to be done.
+func clusterRebalanceUpdateState(client incus.InstanceServer, name string, req api.InstanceStatePut) error {
+	op, err := client.UpdateInstanceState(name, req, "")
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
+
+// clusterRebalanceCandidate returns whether an instance can be considered for rebalancing based on its
+// configuration, without querying the cluster member it is running on.
+func clusterRebalanceCandidate(inst instance.Instance, cooldown string, now time.Time) bool {
+	// Only consider instances which were running when last seen.
+	if inst.LocalConfig()["volatile.last_state.power"] != instance.PowerStateRunning {
+		return false
+	}
+
+	// Skip instances which opted out of rebalancing.
+	if inst.ExpandedConfig()["cluster.rebalance"] == "manual" {
+		return false
+	}
+
+	// Skip instances which were moved recently.
+	lastMove, err := strconv.ParseInt(inst.LocalConfig()["volatile.rebalance.last_move"], 10, 64)
+	if err == nil {
+		expiry, err := internalInstance.GetExpiry(time.Unix(lastMove, 0), cooldown)
+		if err == nil && now.Before(expiry) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// clusterRebalance moves running instances from the busiest cluster members to the least busy ones.
+func clusterRebalance(ctx context.Context, s *state.State, op *operations.Operation) error {
+	client, err := cluster.Connect(s.LocalConfig.ClusterAddress(), s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return err
+	}
+
+	var allMembers []db.NodeInfo
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		allMembers, err = tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Get the load of the online members.
+	members := []clusterRebalanceMember{}
+	for _, member := range allMembers {
+		if member.State != db.ClusterMemberStateCreated || member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+			continue
+		}
+
+		load, err := clusterRebalanceMemberLoad(client, member.Name)
+		if err != nil {
+			logger.Warn("Failed getting cluster member load", logger.Ctx{"member": member.Name, "err": err})
+			continue
+		}
+
+		members = append(members, *load)
+	}
+
+	if len(members) < 2 {
+		return nil
+	}
+
+	// Only look at the instances of the members taking part in the rebalancing.
+	filters := make([]dbCluster.InstanceFilter, 0, len(members))
+	for _, member := range members {
+		name := member.name
+		filters = append(filters, dbCluster.InstanceFilter{Node: &name})
+	}
+
+	cooldown := s.GlobalConfig.ClusterRebalanceCooldown()
+	now := time.Now()
+
+	candidates := []instance.Instance{}
+	err = s.DB.Cluster.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+		inst, err := instance.Load(s, dbInst, p)
+		if err != nil {
+			logger.Warn("Failed loading instance", logger.Ctx{"project": dbInst.Project, "instance": dbInst.Name, "err": err})
+			return nil
+		}
+
+		if clusterRebalanceCandidate(inst, cooldown, now) {
+			candidates = append(candidates, inst)
+		}
+
+		return nil
+	}, filters...)
+	if err != nil {
+		return fmt.Errorf("Failed getting instances: %w", err)
+	}
+
+	// Get the usage of the candidate instances which can be moved.
+	loaded := make(map[string]instance.Instance, len(candidates))
+	instances := []clusterRebalanceInstance{}
+	for _, inst := range candidates {
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+		// Only move virtual machines which can be live-migrated, containers get restarted on the target.
+		migrate, live := inst.CanMigrate()
+		if !migrate || (inst.Type() == instancetype.VM && !live) {
+			continue
+		}
+
+		instState, _, err := client.UseProject(inst.Project().Name).GetInstanceState(inst.Name())
+		if err != nil {
+			l.Warn("Failed getting instance state", logger.Ctx{"err": err})
+			continue
+		}
+
+		if instState.StatusCode != api.Running {
+			continue
+		}
+
+		targets, err := clusterRebalanceTargets(ctx, s, inst, allMembers)
+		if err != nil {
+			l.Warn("Failed getting rebalancing targets for instance", logger.Ctx{"err": err})
+			continue
+		}
+
+		loaded[inst.Project().Name+"/"+inst.Name()] = inst
+		instances = append(instances, clusterRebalanceInstance{
+			project:     inst.Project().Name,
+			name:        inst.Name(),
+			location:    inst.Location(),
+			memoryUsage: uint64(instState.Memory.Usage),
+			live:        live,
+			targets:     targets,
+		})
+	}
+
+	moves := clusterRebalancePlan(members, instances, float64(s.GlobalConfig.ClusterRebalanceThreshold()), int(s.GlobalConfig.ClusterRebalanceBatch()))
+
+	metadata := make(map[string]any)
+	for _, move := range moves {
+		l := logger.AddContext(logger.Ctx{"project": move.instance.project, "instance": move.instance.name, "source": move.instance.location, "target": move.target})
+		l.Info("Moving instance to rebalance the cluster")
+
+		metadata["rebalance_progress"] = fmt.Sprintf("Moving %q in project %q to %q", move.instance.name, move.instance.project, move.target)
+		_ = op.UpdateMetadata(metadata)
+
+		err = clusterRebalanceMoveInstance(client, loaded[move.instance.project+"/"+move.instance.name], move)
+		if err != nil {
+			return fmt.Errorf("Failed moving instance %q in project %q to %q: %w", move.instance.name, move.instance.project, move.target, err)
+		}
+	}
+
+	return nil
+}
+
+func autoRebalanceClusterTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+		if s.GlobalConfig.ClusterRebalanceInterval() == 0 {
+			return // Skip rebalancing if it's disabled.
+		}
+
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil {
+			if errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				return // Skip rebalancing if not clustered.
+			}
+
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if s.LocalConfig.ClusterAddress() != leader {
+			return // Skip rebalancing if not cluster leader.
+		}
+
+		opRun := func(op *operations.Operation) error {
+			return clusterRebalance(ctx, s, op)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterRebalance, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating cluster rebalance operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting cluster rebalance operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed rebalancing cluster", logger.Ctx{"err": err})
+			return
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := d.State().GlobalConfig.ClusterRebalanceInterval()
+		if interval == 0 {
+			// Check again later in case rebalancing gets enabled.
+			return time.Minute, task.ErrSkip
+		}
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
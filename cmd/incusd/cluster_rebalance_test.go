@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterRebalancePlan(t *testing.T) {
+	members := []clusterRebalanceMember{
+		{name: "node1", memoryUsed: 90, memoryTotal: 100},
+		{name: "node2", memoryUsed: 20, memoryTotal: 100},
+		{name: "node3", memoryUsed: 40, memoryTotal: 100},
+	}
+
+	instances := []clusterRebalanceInstance{
+		{project: "default", name: "c1", location: "node1", memoryUsage: 10, targets: []string{"node2", "node3"}},
+		{project: "default", name: "c2", location: "node1", memoryUsage: 30, targets: []string{"node2", "node3"}},
+
+		// Too large, moving it would make the target busier than the source.
+		{project: "default", name: "c3", location: "node1", memoryUsage: 75, targets: []string{"node2", "node3"}},
+
+		// Can't be moved to another member.
+		{project: "default", name: "c4", location: "node1", memoryUsage: 20, targets: []string{}},
+	}
+
+	moves := clusterRebalancePlan(members, instances, 15, 5)
+	assert.Len(t, moves, 2)
+
+	// The largest movable instance goes to the least busy member first.
+	assert.Equal(t, "c2", moves[0].instance.name)
+	assert.Equal(t, "node2", moves[0].target)
+
+	// node1 is then at 60% and node2 at 50%, the next instance goes to node3 (40%).
+	assert.Equal(t, "c1", moves[1].instance.name)
+	assert.Equal(t, "node3", moves[1].target)
+}
+
+func TestClusterRebalancePlanThreshold(t *testing.T) {
+	members := []clusterRebalanceMember{
+		{name: "node1", cpuLoad: 60, memoryTotal: 100},
+		{name: "node2", cpuLoad: 45, memoryTotal: 100},
+	}
+
+	instances := []clusterRebalanceInstance{
+		{project: "default", name: "v1", location: "node1", memoryUsage: 10, live: true, targets: []string{"node2"}},
+	}
+
+	assert.Empty(t, clusterRebalancePlan(members, instances, 20, 1))
+
+	moves := clusterRebalancePlan(members, instances, 10, 1)
+	assert.Len(t, moves, 1)
+	assert.Equal(t, "v1", moves[0].instance.name)
+	assert.True(t, moves[0].instance.live)
+}
+
+func TestClusterRebalancePlanBatch(t *testing.T) {
+	members := []clusterRebalanceMember{
+		{name: "node1", memoryUsed: 95, memoryTotal: 100},
+		{name: "node2", memoryUsed: 5, memoryTotal: 100},
+	}
+
+	instances := []clusterRebalanceInstance{
+		{project: "default", name: "c1", location: "node1", memoryUsage: 10, targets: []string{"node2"}},
+		{project: "default", name: "c2", location: "node1", memoryUsage: 10, targets: []string{"node2"}},
+		{project: "default", name: "c3", location: "node1", memoryUsage: 10, targets: []string{"node2"}},
+	}
+
+	assert.Len(t, clusterRebalancePlan(members, instances, 20, 2), 2)
+}
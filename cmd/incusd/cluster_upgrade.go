@@ -0,0 +1,566 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/lxc/incus/internal/server/cluster"
+	"github.com/lxc/incus/internal/server/db"
+	"github.com/lxc/incus/internal/server/response"
+	"github.com/lxc/incus/internal/server/state"
+	"github.com/lxc/incus/internal/server/task"
+	"github.com/lxc/incus/internal/version"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+)
+
+var clusterUpgradeCmd = APIEndpoint{
+	Path: "cluster/upgrade",
+
+	Get:  APIEndpointAction{Handler: clusterUpgradeGet},
+	Post: APIEndpointAction{Handler: clusterUpgradePost},
+}
+
+// clusterUpgradeLoad returns the cluster members along with the state of the current rolling upgrade, if any.
+func clusterUpgradeLoad(ctx context.Context, s *state.State) ([]db.NodeInfo, []db.NodeUpgrade, error) {
+	var members []db.NodeInfo
+	var upgrades []db.NodeUpgrade
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		members, err = tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		upgrades, err = tx.GetNodeUpgrades(ctx)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return members, upgrades, nil
+}
+
+// clusterUpgradeStatus returns the status of the rolling upgrade with the given member states and blockers.
+func clusterUpgradeStatus(upgrades []db.NodeUpgrade, blockers []string) string {
+	if len(upgrades) == 0 {
+		if len(blockers) > 0 {
+			return "blocked"
+		}
+
+		return "idle"
+	}
+
+	for _, upgrade := range upgrades {
+		if upgrade.Error != "" {
+			return "blocked"
+		}
+
+		if upgrade.State != db.NodeUpgradeDone {
+			return "running"
+		}
+	}
+
+	return "completed"
+}
+
+// clusterUpgradeRunning returns whether a rolling upgrade with the given member states is still in progress.
+func clusterUpgradeRunning(upgrades []db.NodeUpgrade) bool {
+	return len(upgrades) > 0 && clusterUpgradeStatus(upgrades, nil) != "completed"
+}
+
+// swagger:operation GET /1.0/cluster/upgrade cluster cluster_upgrade_get
+//
+//	Get the rolling upgrade plan
+//
+//	Gets the versions of the cluster members, the state of the rolling upgrade
+//	and anything preventing it from proceeding.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Rolling upgrade
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterUpgrade"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterUpgradeGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	clustered, err := cluster.Enabled(s.DB.Node)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !clustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	members, upgrades, err := clusterUpgradeLoad(r.Context(), s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	offlineThreshold := s.GlobalConfig.OfflineThreshold()
+
+	// Blockers only prevent a new rolling upgrade from starting.
+	blockers := []string{}
+	if !clusterUpgradeRunning(upgrades) {
+		blockers = cluster.UpgradeBlockers(members, offlineThreshold)
+	}
+
+	result := api.ClusterUpgrade{
+		Status:   clusterUpgradeStatus(upgrades, blockers),
+		Members:  []api.ClusterUpgradeMember{},
+		Blockers: blockers,
+	}
+
+	memberUpgrades := make(map[int64]db.NodeUpgrade, len(upgrades))
+	for _, upgrade := range upgrades {
+		memberUpgrades[upgrade.NodeID] = upgrade
+	}
+
+	for _, member := range members {
+		entry := api.ClusterUpgradeMember{
+			Name:          member.Name,
+			Schema:        member.Schema,
+			APIExtensions: member.APIExtensions,
+			Status:        "Online",
+		}
+
+		if member.State == db.ClusterMemberStateEvacuated {
+			entry.Status = "Evacuated"
+		} else if member.IsOffline(offlineThreshold) {
+			entry.Status = "Offline"
+		}
+
+		upgrade, ok := memberUpgrades[member.ID]
+		if ok {
+			entry.UpgradeState = upgrade.State
+			entry.UpgradeError = upgrade.Error
+			entry.UpgradeDate = upgrade.Date
+		}
+
+		// Ask the members which aren't offline for the version they are running.
+		if !member.IsOffline(offlineThreshold) {
+			entry.Version, _ = clusterUpgradeServerVersion(s, member, r)
+		}
+
+		result.Members = append(result.Members, entry)
+	}
+
+	// List the members in upgrade order.
+	positions := make(map[string]int, len(upgrades))
+	for _, upgrade := range upgrades {
+		positions[upgrade.Name] = upgrade.Position
+	}
+
+	sort.SliceStable(result.Members, func(i, j int) bool {
+		return positions[result.Members[i].Name] < positions[result.Members[j].Name]
+	})
+
+	return response.SyncResponse(true, result)
+}
+
+// swagger:operation POST /1.0/cluster/upgrade cluster cluster_upgrade_post
+//
+//	Drive the rolling upgrade
+//
+//	Starts, resumes or aborts a rolling upgrade of the cluster, or signals that
+//	the binary of the cluster member being upgraded was replaced.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: upgrade
+//	    description: Rolling upgrade action
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ClusterUpgradePost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterUpgradePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	clustered, err := cluster.Enabled(s.DB.Node)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !clustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	req := api.ClusterUpgradePost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	leaderAddress, err := d.gateway.LeaderAddress()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	offlineThreshold := s.GlobalConfig.OfflineThreshold()
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		members, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		upgrades, err := tx.GetNodeUpgrades(ctx)
+		if err != nil {
+			return err
+		}
+
+		running := clusterUpgradeRunning(upgrades)
+
+		switch req.Action {
+		case "start":
+			if running {
+				return api.StatusErrorf(http.StatusBadRequest, "A rolling upgrade is already running")
+			}
+
+			blockers := cluster.UpgradeBlockers(members, offlineThreshold)
+			if len(blockers) > 0 {
+				return api.StatusErrorf(http.StatusBadRequest, "Rolling upgrade blocked: %s", blockers[0])
+			}
+
+			err = tx.DeleteNodeUpgrades(ctx)
+			if err != nil {
+				return err
+			}
+
+			// Upgrade the leader last to limit the number of leadership changes.
+			nodeIDs := make([]int64, 0, len(members))
+			var leaderID int64
+			for _, member := range members {
+				if member.Address == leaderAddress {
+					leaderID = member.ID
+					continue
+				}
+
+				nodeIDs = append(nodeIDs, member.ID)
+			}
+
+			if leaderID != 0 {
+				nodeIDs = append(nodeIDs, leaderID)
+			}
+
+			return tx.CreateNodeUpgrades(ctx, nodeIDs)
+		case "upgraded":
+			if !running {
+				return api.StatusErrorf(http.StatusBadRequest, "No rolling upgrade is running")
+			}
+
+			for _, upgrade := range upgrades {
+				if upgrade.Name != req.Member {
+					continue
+				}
+
+				if upgrade.State != db.NodeUpgradeUpgrading {
+					return api.StatusErrorf(http.StatusBadRequest, "Cluster member %q isn't waiting to be upgraded (%s)", upgrade.Name, upgrade.State)
+				}
+
+				return tx.UpdateNodeUpgrade(ctx, upgrade.NodeID, db.NodeUpgradeRejoining, "")
+			}
+
+			return api.StatusErrorf(http.StatusBadRequest, "Cluster member %q isn't part of the rolling upgrade", req.Member)
+		case "resume":
+			if !running {
+				return api.StatusErrorf(http.StatusBadRequest, "No rolling upgrade is running")
+			}
+
+			return tx.ClearNodeUpgradeErrors(ctx)
+		case "abort":
+			if !running {
+				return api.StatusErrorf(http.StatusBadRequest, "No rolling upgrade is running")
+			}
+
+			return tx.DeleteNodeUpgrades(ctx)
+		default:
+			return api.StatusErrorf(http.StatusBadRequest, "Unknown action %q", req.Action)
+		}
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// clusterUpgradeServerVersion returns the version of Incus running on the given cluster member.
+func clusterUpgradeServerVersion(s *state.State, member db.NodeInfo, r *http.Request) (string, error) {
+	if member.Address == s.LocalConfig.ClusterAddress() {
+		return version.Version, nil
+	}
+
+	dest, err := cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+	if err != nil {
+		return "", err
+	}
+
+	server, _, err := dest.GetServer()
+	if err != nil {
+		return "", err
+	}
+
+	return server.Environment.ServerVersion, nil
+}
+
+// clusterUpgradeOps holds the operations used to drive a rolling upgrade.
+type clusterUpgradeOps struct {
+	// setState records the upgrade state of a member along with its error, if any.
+	setState func(upgrade db.NodeUpgrade, state string, errorMessage string) error
+
+	// setRestart records the version reported by a member before its upgrade and whether it went offline since.
+	setRestart func(upgrade db.NodeUpgrade, version string, offline bool) error
+
+	// changeState evacuates or restores a member.
+	changeState func(member db.NodeInfo, action string) error
+
+	// serverVersion returns the version of Incus running on a member.
+	serverVersion func(member db.NodeInfo) (string, error)
+}
+
+// clusterUpgradeRun moves the rolling upgrade with the given member states forward. Members are upgraded one at
+// a time: each one is evacuated, left alone until its binary is reported as replaced, waited for until it restarts
+// and rejoins the cluster and then restored.
+//
+// Members restarted with a different schema or API version can't rejoin the cluster until all the others are
+// upgraded, so the upgrade carries on with the remaining members and restores them once they have rejoined.
+func clusterUpgradeRun(members []db.NodeInfo, upgrades []db.NodeUpgrade, localVersion [2]int, offlineThreshold time.Duration, ops clusterUpgradeOps) error {
+	membersByID := make(map[int64]db.NodeInfo, len(members))
+	for _, member := range members {
+		membersByID[member.ID] = member
+	}
+
+	for _, upgrade := range upgrades {
+		if upgrade.State == db.NodeUpgradeDone {
+			continue
+		}
+
+		// Wait for errors to be resolved.
+		if upgrade.Error != "" {
+			return nil
+		}
+
+		member, ok := membersByID[upgrade.NodeID]
+		if !ok {
+			return fmt.Errorf("Failed finding cluster member %q", upgrade.Name)
+		}
+
+		if upgrade.State == db.NodeUpgradeRejoining && member.Version() != localVersion {
+			continue
+		}
+
+		return clusterUpgradeMember(upgrade, member, member.IsOffline(offlineThreshold), ops)
+	}
+
+	return nil
+}
+
+// clusterUpgradeMember moves the upgrade of a single cluster member forward.
+func clusterUpgradeMember(upgrade db.NodeUpgrade, member db.NodeInfo, offline bool, ops clusterUpgradeOps) error {
+	l := logger.AddContext(logger.Ctx{"member": member.Name, "state": upgrade.State})
+
+	switch upgrade.State {
+	case db.NodeUpgradePending, db.NodeUpgradeEvacuating:
+		if offline {
+			return ops.setState(upgrade, upgrade.State, "Cluster member is offline")
+		}
+
+		err := ops.setState(upgrade, db.NodeUpgradeEvacuating, "")
+		if err != nil {
+			return err
+		}
+
+		if member.State != db.ClusterMemberStateEvacuated {
+			l.Info("Evacuating cluster member for rolling upgrade")
+			err = ops.changeState(member, "evacuate")
+			if err != nil {
+				return ops.setState(upgrade, db.NodeUpgradeEvacuating, fmt.Sprintf("Failed evacuating cluster member: %v", err))
+			}
+		}
+
+		// Record the version the member is running to notice when it restarts.
+		serverVersion, err := ops.serverVersion(member)
+		if err != nil {
+			return ops.setState(upgrade, db.NodeUpgradeEvacuating, fmt.Sprintf("Failed getting cluster member version: %v", err))
+		}
+
+		err = ops.setRestart(upgrade, serverVersion, false)
+		if err != nil {
+			return err
+		}
+
+		l.Info("Cluster member ready to be upgraded")
+		return ops.setState(upgrade, db.NodeUpgradeUpgrading, "")
+	case db.NodeUpgradeUpgrading, db.NodeUpgradeRejoining:
+		// The member may be restarted before its binary is reported as replaced.
+		if offline {
+			if !upgrade.Offline {
+				return ops.setRestart(upgrade, upgrade.Version, true)
+			}
+
+			return nil
+		}
+
+		if upgrade.State == db.NodeUpgradeUpgrading {
+			return nil
+		}
+
+		// Wait for the member to come back online or to report a different version.
+		if !upgrade.Offline {
+			serverVersion, err := ops.serverVersion(member)
+			if err != nil || serverVersion == upgrade.Version {
+				return nil
+			}
+		}
+
+		err := ops.setState(upgrade, db.NodeUpgradeRestoring, "")
+		if err != nil {
+			return err
+		}
+
+		fallthrough
+	case db.NodeUpgradeRestoring:
+		if member.State == db.ClusterMemberStateEvacuated {
+			l.Info("Restoring cluster member after rolling upgrade")
+			err := ops.changeState(member, "restore")
+			if err != nil {
+				return ops.setState(upgrade, db.NodeUpgradeRestoring, fmt.Sprintf("Failed restoring cluster member: %v", err))
+			}
+		}
+
+		l.Info("Cluster member upgraded")
+		return ops.setState(upgrade, db.NodeUpgradeDone, "")
+	}
+
+	return nil
+}
+
+// clusterUpgrade moves the current rolling upgrade forward. Progress is recorded in the database so that a new
+// leader picks up where the previous one stopped.
+func clusterUpgrade(ctx context.Context, s *state.State) error {
+	members, upgrades, err := clusterUpgradeLoad(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	var local *db.NodeInfo
+	for i := range members {
+		if members[i].Address == s.LocalConfig.ClusterAddress() {
+			local = &members[i]
+		}
+	}
+
+	if local == nil {
+		return fmt.Errorf("Failed finding local cluster member")
+	}
+
+	ops := clusterUpgradeOps{
+		setState: func(upgrade db.NodeUpgrade, state string, errorMessage string) error {
+			return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.UpdateNodeUpgrade(ctx, upgrade.NodeID, state, errorMessage)
+			})
+		},
+		setRestart: func(upgrade db.NodeUpgrade, version string, offline bool) error {
+			return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.UpdateNodeUpgradeRestart(ctx, upgrade.NodeID, version, offline)
+			})
+		},
+		changeState: func(member db.NodeInfo, action string) error {
+			dest, err := cluster.Connect(s.LocalConfig.ClusterAddress(), s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+			if err != nil {
+				return err
+			}
+
+			op, err := dest.UpdateClusterMemberState(member.Name, api.ClusterMemberStatePost{Action: action})
+			if err != nil {
+				return err
+			}
+
+			return op.Wait()
+		},
+		serverVersion: func(member db.NodeInfo) (string, error) {
+			return clusterUpgradeServerVersion(s, member, nil)
+		},
+	}
+
+	return clusterUpgradeRun(members, upgrades, local.Version(), s.GlobalConfig.OfflineThreshold(), ops)
+}
+
+func autoClusterUpgradeTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil {
+			if errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				return // Skip rolling upgrades if not clustered.
+			}
+
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if s.LocalConfig.ClusterAddress() != leader {
+			return // Skip rolling upgrades if not cluster leader.
+		}
+
+		err = clusterUpgrade(ctx, s)
+		if err != nil {
+			logger.Error("Failed driving rolling cluster upgrade", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(30 * time.Second)
+}
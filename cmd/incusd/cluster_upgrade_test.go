@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/internal/server/db"
+)
+
+// clusterUpgradeTestCluster is an in-memory cluster whose rolling upgrade state stands for the database.
+type clusterUpgradeTestCluster struct {
+	members  []db.NodeInfo
+	upgrades []db.NodeUpgrade
+	versions map[string]string
+	actions  []string
+}
+
+func newClusterUpgradeTestCluster(names ...string) *clusterUpgradeTestCluster {
+	c := &clusterUpgradeTestCluster{versions: map[string]string{}}
+
+	for i, name := range names {
+		c.members = append(c.members, db.NodeInfo{ID: int64(i + 1), Name: name, Schema: 1, APIExtensions: 1, Heartbeat: time.Now()})
+		c.upgrades = append(c.upgrades, db.NodeUpgrade{NodeID: int64(i + 1), Name: name, Position: i, State: db.NodeUpgradePending})
+		c.versions[name] = "1.0"
+	}
+
+	return c
+}
+
+func (c *clusterUpgradeTestCluster) member(name string) *db.NodeInfo {
+	for i := range c.members {
+		if c.members[i].Name == name {
+			return &c.members[i]
+		}
+	}
+
+	return nil
+}
+
+func (c *clusterUpgradeTestCluster) upgrade(name string) *db.NodeUpgrade {
+	for i := range c.upgrades {
+		if c.upgrades[i].Name == name {
+			return &c.upgrades[i]
+		}
+	}
+
+	return nil
+}
+
+// setOnline sets whether a member is sending heartbeats.
+func (c *clusterUpgradeTestCluster) setOnline(name string, online bool) {
+	if online {
+		c.member(name).Heartbeat = time.Now()
+	} else {
+		c.member(name).Heartbeat = time.Now().Add(-time.Hour)
+	}
+}
+
+// run moves the rolling upgrade forward as the leader running the given member would.
+func (c *clusterUpgradeTestCluster) run(t *testing.T, leader string) {
+	ops := clusterUpgradeOps{
+		setState: func(upgrade db.NodeUpgrade, state string, errorMessage string) error {
+			c.upgrade(upgrade.Name).State = state
+			c.upgrade(upgrade.Name).Error = errorMessage
+			return nil
+		},
+		setRestart: func(upgrade db.NodeUpgrade, version string, offline bool) error {
+			c.upgrade(upgrade.Name).Version = version
+			c.upgrade(upgrade.Name).Offline = offline
+			return nil
+		},
+		changeState: func(member db.NodeInfo, action string) error {
+			c.actions = append(c.actions, fmt.Sprintf("%s %s", action, member.Name))
+
+			if action == "evacuate" {
+				c.member(member.Name).State = db.ClusterMemberStateEvacuated
+			} else {
+				c.member(member.Name).State = db.ClusterMemberStateCreated
+			}
+
+			return nil
+		},
+		serverVersion: func(member db.NodeInfo) (string, error) {
+			if member.IsOffline(time.Minute) {
+				return "", fmt.Errorf("Cluster member is offline")
+			}
+
+			return c.versions[member.Name], nil
+		},
+	}
+
+	// Work on copies, like a leader loading the state from the database.
+	members := append([]db.NodeInfo{}, c.members...)
+	upgrades := append([]db.NodeUpgrade{}, c.upgrades...)
+
+	err := clusterUpgradeRun(members, upgrades, c.member(leader).Version(), time.Minute, ops)
+	require.NoError(t, err)
+}
+
+// upgraded reports that the binary of a member was replaced.
+func (c *clusterUpgradeTestCluster) upgraded(t *testing.T, name string) {
+	require.Equal(t, db.NodeUpgradeUpgrading, c.upgrade(name).State)
+	c.upgrade(name).State = db.NodeUpgradeRejoining
+}
+
+func TestClusterUpgradeRun(t *testing.T) {
+	c := newClusterUpgradeTestCluster("m1", "m2", "m3")
+
+	// The first member is evacuated and its version recorded.
+	c.run(t, "m3")
+	assert.Equal(t, db.NodeUpgradeUpgrading, c.upgrade("m1").State)
+	assert.Equal(t, "1.0", c.upgrade("m1").Version)
+	assert.Equal(t, []string{"evacuate m1"}, c.actions)
+
+	// Nothing happens until the binary is reported as replaced.
+	c.run(t, "m3")
+	assert.Equal(t, db.NodeUpgradeUpgrading, c.upgrade("m1").State)
+
+	c.upgraded(t, "m1")
+
+	// The member didn't restart yet.
+	c.run(t, "m3")
+	assert.Equal(t, db.NodeUpgradeRejoining, c.upgrade("m1").State)
+
+	// The member restarts, going offline and back online with the same version.
+	c.setOnline("m1", false)
+	c.run(t, "m3")
+	assert.Equal(t, db.NodeUpgradeRejoining, c.upgrade("m1").State)
+	assert.True(t, c.upgrade("m1").Offline)
+
+	c.setOnline("m1", true)
+	c.run(t, "m3")
+	assert.Equal(t, db.NodeUpgradeDone, c.upgrade("m1").State)
+	assert.Equal(t, db.ClusterMemberStateCreated, c.member("m1").State)
+
+	// The second member restarts quickly, only reporting a new version.
+	c.run(t, "m3")
+	c.upgraded(t, "m2")
+	c.versions["m2"] = "2.0"
+	c.run(t, "m3")
+	assert.Equal(t, db.NodeUpgradeDone, c.upgrade("m2").State)
+
+	assert.Equal(t, []string{"evacuate m1", "restore m1", "evacuate m2", "restore m2"}, c.actions)
+	assert.Equal(t, db.NodeUpgradePending, c.upgrade("m3").State)
+}
+
+func TestClusterUpgradeRunErrors(t *testing.T) {
+	c := newClusterUpgradeTestCluster("m1", "m2")
+
+	// Offline members aren't evacuated.
+	c.setOnline("m1", false)
+	c.run(t, "m2")
+	assert.Equal(t, db.NodeUpgradePending, c.upgrade("m1").State)
+	assert.Equal(t, "Cluster member is offline", c.upgrade("m1").Error)
+	assert.Empty(t, c.actions)
+
+	// The upgrade waits for the error to be resolved.
+	c.setOnline("m1", true)
+	c.run(t, "m2")
+	assert.Empty(t, c.actions)
+
+	c.upgrade("m1").Error = ""
+	c.run(t, "m2")
+	assert.Equal(t, db.NodeUpgradeUpgrading, c.upgrade("m1").State)
+	assert.Equal(t, []string{"evacuate m1"}, c.actions)
+}
+
+func TestClusterUpgradeRunLeaderFailover(t *testing.T) {
+	c := newClusterUpgradeTestCluster("m1", "m2", "m3")
+
+	c.run(t, "m3")
+	c.upgraded(t, "m1")
+
+	// The leader sees the member going offline, then the leadership moves to another member.
+	c.setOnline("m1", false)
+	c.run(t, "m3")
+	c.setOnline("m1", true)
+
+	// The new leader restores the member as it was seen offline by the previous leader.
+	c.run(t, "m2")
+	assert.Equal(t, db.NodeUpgradeDone, c.upgrade("m1").State)
+	assert.Equal(t, []string{"evacuate m1", "restore m1"}, c.actions)
+
+	// The new leader carries on with the next member.
+	c.run(t, "m2")
+	assert.Equal(t, db.NodeUpgradeUpgrading, c.upgrade("m2").State)
+}
+
+func TestClusterUpgradeRunVersionSkew(t *testing.T) {
+	c := newClusterUpgradeTestCluster("m1", "m2", "m3")
+
+	c.run(t, "m3")
+	c.upgraded(t, "m1")
+
+	// The member restarts with a new schema, waiting for the others to be upgraded.
+	c.member("m1").Schema = 2
+	c.versions["m1"] = "2.0"
+	c.setOnline("m1", false)
+
+	// The upgrade carries on with the remaining members.
+	c.run(t, "m3")
+	assert.Equal(t, db.NodeUpgradeRejoining, c.upgrade("m1").State)
+	assert.Equal(t, db.NodeUpgradeUpgrading, c.upgrade("m2").State)
+
+	c.upgraded(t, "m2")
+	c.member("m2").Schema = 2
+	c.versions["m2"] = "2.0"
+	c.setOnline("m2", false)
+
+	c.run(t, "m3")
+	assert.Equal(t, db.NodeUpgradeUpgrading, c.upgrade("m3").State)
+
+	// Once the leader is upgraded, all the members rejoin and get restored by the new leader.
+	c.upgraded(t, "m3")
+	c.member("m3").Schema = 2
+	c.versions["m3"] = "2.0"
+	c.setOnline("m1", true)
+	c.setOnline("m2", true)
+
+	c.run(t, "m1")
+	c.run(t, "m1")
+	c.run(t, "m1")
+
+	for _, name := range []string{"m1", "m2", "m3"} {
+		assert.Equal(t, db.NodeUpgradeDone, c.upgrade(name).State)
+		assert.Equal(t, db.ClusterMemberStateCreated, c.member(name).State)
+	}
+}
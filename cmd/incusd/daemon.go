@@ -1257,6 +1257,9 @@ func (d *Daemon) init() error {
 	oidcIssuer, oidcClientID, oidcAudience := d.globalConfig.OIDCServer()
 	syslogSocketEnabled := d.localConfig.SyslogSocket()
 	instancePlacementScriptlet := d.globalConfig.InstancesPlacementScriptlet()
+	instanceValidationScriptlet := d.globalConfig.InstancesValidationScriptlet()
+	projectValidationScriptlet := d.globalConfig.ProjectsValidationScriptlet()
+	dnsResolver, dnsForwarders, dnsResolverSubnets := d.globalConfig.DNSResolver()
 
 	d.endpoints.NetworkUpdateTrustedProxy(d.globalConfig.HTTPSTrustedProxy())
 	d.globalConfigMu.Unlock()
@@ -1316,6 +1319,25 @@ func (d *Daemon) init() error {
 			}
 
 			resp.Content = strings.TrimSpace(zoneBuilder.String())
+
+			// Load the signing keys.
+			if util.IsTrue(zoneInfo.Config["dnssec.enabled"]) {
+				keys, err := zone.DNSSECKeys()
+				if err != nil {
+					logger.Errorf("Failed to load DNSSEC keys of DNS zone %q: %v", name, err)
+					return nil, err
+				}
+
+				for _, key := range keys {
+					dnssecKey, err := dns.NewDNSSECKey(zoneInfo.Name, key.Flags, key.Algorithm, key.PublicKey, key.PrivateKey)
+					if err != nil {
+						logger.Errorf("Failed to load DNSSEC keys of DNS zone %q: %v", name, err)
+						return nil, err
+					}
+
+					resp.DNSSECKeys = append(resp.DNSSECKeys, *dnssecKey)
+				}
+			}
 		} else {
 			// SOA only.
 			zoneBuilder, err := zone.SOA()
@@ -1329,6 +1351,11 @@ func (d *Daemon) init() error {
 
 		return resp, nil
 	})
+	err = d.dns.UpdateResolver(dnsResolver, dnsForwarders, dnsResolverSubnets)
+	if err != nil {
+		return err
+	}
+
 	if dnsAddress != "" {
 		err := d.dns.Start(dnsAddress)
 		if err != nil {
@@ -1370,6 +1397,22 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Load instance validation scriptlet.
+	if instanceValidationScriptlet != "" {
+		err = scriptletLoad.InstanceValidationSet(instanceValidationScriptlet)
+		if err != nil {
+			logger.Warn("Failed loading instance validation scriptlet", logger.Ctx{"err": err})
+		}
+	}
+
+	// Load project validation scriptlet.
+	if projectValidationScriptlet != "" {
+		err = scriptletLoad.ProjectValidationSet(projectValidationScriptlet)
+		if err != nil {
+			logger.Warn("Failed loading project validation scriptlet", logger.Ctx{"err": err})
+		}
+	}
+
 	// Apply all patches that need to be run after networks are initialised.
 	err = patchesApply(d, patchPostNetworks)
 	if err != nil {
@@ -1487,6 +1530,21 @@ func (d *Daemon) init() error {
 
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Record network flows (every 30s)
+		d.tasks.Add(networkFlowsUpdateTask(d))
+
+		// Request and renew delegated IPv6 prefixes (every minute)
+		d.tasks.Add(networkPrefixDelegationTask(d))
+
+		// Record the resource usage of instances (every 5 minutes)
+		d.tasks.Add(instanceUsageTask(d))
+
+		// Record the resource usage of projects (hourly)
+		d.tasks.Add(projectUsageTask(d))
+
+		// Roll over the DNSSEC keys of network zones (hourly)
+		d.tasks.Add(autoRotateDNSSECKeysTask(d))
 	}
 
 	// Start all background tasks
@@ -1525,6 +1583,15 @@ func (d *Daemon) startClusterTasks() {
 	// Perform automatic evacuation for offline cluster members
 	d.clusterTasks.Add(autoHealClusterTask(d))
 
+	// Move instances between cluster members to balance their load
+	d.clusterTasks.Add(autoRebalanceClusterTask(d))
+
+	// Evacuate and restore cluster members during their maintenance windows
+	d.clusterTasks.Add(autoClusterMaintenanceTask(d))
+
+	// Drive rolling upgrades of the cluster members
+	d.clusterTasks.Add(autoClusterUpgradeTask(d))
+
 	// Start all background tasks
 	d.clusterTasks.Start(d.shutdownCtx)
 }
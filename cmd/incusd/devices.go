@@ -484,11 +484,51 @@ func deviceTaskBalance(s *state.State) {
 		}
 	}
 
+	// Get the CPUs the virtual machines are pinned to so containers can avoid them.
+	vms, err := instance.LoadNodeAll(s, instancetype.VM)
+	if err != nil {
+		logger.Error("Problem loading instances list", logger.Ctx{"err": err})
+		return
+	}
+
+	reservedCpus := map[int64]int{}
+	for _, vm := range vms {
+		conf := vm.ExpandedConfig()
+
+		vmCpus := conf["volatile.cpu.set"]
+		if vmCpus == "" {
+			_, err := strconv.Atoi(conf["limits.cpu"])
+			if conf["limits.cpu"] == "" || err == nil {
+				continue
+			}
+
+			vmCpus = conf["limits.cpu"]
+		}
+
+		if !vm.IsRunning() {
+			continue
+		}
+
+		pinnedCpus, err := resources.ParseCpuset(vmCpus)
+		if err != nil {
+			continue
+		}
+
+		for _, id := range pinnedCpus {
+			reservedCpus[id]++
+		}
+	}
+
 	fixedInstances := map[int64][]instance.Instance{}
 	balancedInstances := map[instance.Instance]int{}
 	for _, c := range instances {
 		conf := c.ExpandedConfig()
 		cpuNodes := conf["limits.cpu.nodes"]
+		if cpuNodes == "balanced" {
+			// Use the NUMA nodes selected when the container started.
+			cpuNodes = conf["volatile.cpu.nodes"]
+		}
+
 		var numaCpus []int64
 		if cpuNodes != "" {
 			numaNodeSet, err := resources.ParseNumaNodeSet(cpuNodes)
@@ -548,7 +588,7 @@ func deviceTaskBalance(s *state.State) {
 		cpu := deviceTaskCPU{}
 		cpu.id = id
 		cpu.strId = fmt.Sprintf("%d", id)
-		count := 0
+		count := reservedCpus[id]
 		cpu.count = &count
 
 		usage[id] = cpu
@@ -237,6 +237,36 @@ func imgPostInstanceInfo(s *state.State, r *http.Request, req api.ImagesPost, op
 
 	info.Type = c.Type().String()
 
+	var resume func()
+	if req.Stateful {
+		if c.Type() != instancetype.Container {
+			return nil, fmt.Errorf("Stateful publishing is only supported for containers")
+		}
+
+		if c.IsRunning() {
+			// Checkpoint the running container and resume it once it has been exported.
+			err = c.Stop(true)
+			if err != nil {
+				return nil, fmt.Errorf("Failed saving the instance state: %w", err)
+			}
+
+			resume = func() {
+				err := c.Start(true)
+				if err != nil {
+					logger.Warn("Failed resuming instance after publishing", logger.Ctx{"project": projectName, "instance": c.Name(), "err": err})
+				}
+			}
+
+			defer func() {
+				if resume != nil {
+					resume()
+				}
+			}()
+		} else if !c.IsStateful() {
+			return nil, fmt.Errorf("Instance has no saved state to publish")
+		}
+	}
+
 	// Build the actual image file
 	imageFile, err := os.CreateTemp(builddir, "incus_build_image_")
 	if err != nil {
@@ -260,6 +290,13 @@ func imgPostInstanceInfo(s *state.State, r *http.Request, req api.ImagesPost, op
 		return nil, err
 	}
 
+	if req.Stateful {
+		err = filepath.Walk(c.StatePath(), sumSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Track progress creating image.
 	metadata := make(map[string]any)
 	imageProgressWriter := &ioprogress.ProgressWriter{
@@ -338,7 +375,7 @@ func imgPostInstanceInfo(s *state.State, r *http.Request, req api.ImagesPost, op
 	var meta api.ImageMetadata
 
 	writer = internalIO.NewQuotaWriter(writer, budget)
-	meta, err = c.Export(writer, req.Properties, req.ExpiresAt)
+	meta, err = c.Export(writer, req.Properties, req.ExpiresAt, req.Stateful)
 
 	// Get ExpiresAt
 	if meta.ExpiryDate != 0 {
@@ -352,6 +389,12 @@ func imgPostInstanceInfo(s *state.State, r *http.Request, req api.ImagesPost, op
 	wg.Wait() // Wait until compression helper has finished if used.
 	_ = imageFile.Close()
 
+	// Resume the instance as soon as its state has been exported.
+	if resume != nil {
+		resume()
+		resume = nil
+	}
+
 	// Check compression errors.
 	if compressErr != nil {
 		return nil, compressErr
@@ -16,16 +16,19 @@ import (
 	"github.com/lxc/incus/internal/server/db"
 	dbCluster "github.com/lxc/incus/internal/server/db/cluster"
 	"github.com/lxc/incus/internal/server/db/operationtype"
+	deviceConfig "github.com/lxc/incus/internal/server/device/config"
 	"github.com/lxc/incus/internal/server/instance"
 	"github.com/lxc/incus/internal/server/instance/instancetype"
 	"github.com/lxc/incus/internal/server/instance/operationlock"
 	"github.com/lxc/incus/internal/server/locking"
 	"github.com/lxc/incus/internal/server/operations"
 	"github.com/lxc/incus/internal/server/project"
+	"github.com/lxc/incus/internal/server/scriptlet"
 	"github.com/lxc/incus/internal/server/state"
 	storagePools "github.com/lxc/incus/internal/server/storage"
 	"github.com/lxc/incus/internal/server/task"
 	"github.com/lxc/incus/shared/api"
+	apiScriptlet "github.com/lxc/incus/shared/api/scriptlet"
 	"github.com/lxc/incus/shared/logger"
 	"github.com/lxc/incus/shared/util"
 )
@@ -174,6 +177,19 @@ func instanceCreateFromImage(s *state.State, r *http.Request, img *api.Image, ar
 
 	revert.Add(func() { _ = inst.Delete(true) })
 
+	// Images published statefully carry the runtime state of the container, resume it on the first start.
+	if inst.Type() == instancetype.Container && util.IsTrue(img.Properties["stateful"]) {
+		err = s.DB.Cluster.UpdateInstanceStatefulFlag(inst.ID(), true)
+		if err != nil {
+			return fmt.Errorf("Failed setting instance stateful flag: %w", err)
+		}
+
+		err = inst.VolatileSet(map[string]string{"volatile.apply_state": "true"})
+		if err != nil {
+			return err
+		}
+	}
+
 	err = inst.UpdateBackupFile()
 	if err != nil {
 		return err
@@ -621,6 +637,18 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 				return nil
 			}
 
+			// Check that the project allows for another snapshot of the instance.
+			snapshots, err := inst.Snapshots()
+			if err != nil {
+				return fmt.Errorf("Failed loading snapshots of instance %q (project %q) for snapshot task: %w", inst.Name(), inst.Project().Name, err)
+			}
+
+			err = project.AllowSnapshotCount(&p, len(snapshots))
+			if err != nil {
+				logger.Warn("Skipping scheduled instance snapshot", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name, "err": err})
+				return nil
+			}
+
 			logger.Debug("Scheduling auto instance snapshot", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name})
 			instances = append(instances, inst)
 
@@ -741,3 +769,25 @@ func instanceOperationLock(ctx context.Context, projectName string, instanceName
 
 	return locking.Lock(ctx, fmt.Sprintf("InstanceOperation_%s", project.Instance(projectName, instanceName)))
 }
+
+// instanceValidationRun runs the instance validation scriptlet, if configured, against the proposed instance
+// configuration expanded with the given profiles.
+func instanceValidationRun(ctx context.Context, s *state.State, reason string, projectName string, name string, instanceType string, req api.InstancePut, profiles []api.Profile) error {
+	if s.GlobalConfig.InstancesValidationScriptlet() == "" {
+		return nil
+	}
+
+	// Copy request so we don't modify it when expanding the config.
+	reqExpanded := apiScriptlet.InstanceValidation{
+		InstancePut: req,
+		Name:        name,
+		Type:        instanceType,
+		Reason:      reason,
+		Project:     projectName,
+	}
+
+	reqExpanded.Config = db.ExpandInstanceConfig(reqExpanded.Config, profiles)
+	reqExpanded.Devices = db.ExpandInstanceDevices(deviceConfig.NewDevices(reqExpanded.Devices), profiles).CloneNative()
+
+	return scriptlet.InstanceValidationRun(ctx, logger.Log, &reqExpanded)
+}
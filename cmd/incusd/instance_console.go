@@ -27,6 +27,7 @@ import (
 	"github.com/lxc/incus/internal/server/instance/instancetype"
 	"github.com/lxc/incus/internal/server/operations"
 	"github.com/lxc/incus/internal/server/response"
+	"github.com/lxc/incus/internal/server/state"
 	internalUtil "github.com/lxc/incus/internal/util"
 	"github.com/lxc/incus/internal/version"
 	"github.com/lxc/incus/shared/api"
@@ -63,7 +64,7 @@ type consoleWs struct {
 	// terminal height
 	height int
 
-	// channel type (either console or vga)
+	// channel type (either console, vga or vnc)
 	protocol string
 }
 
@@ -84,7 +85,7 @@ func (s *consoleWs) Connect(op *operations.Operation, r *http.Request, w http.Re
 	switch s.protocol {
 	case instance.ConsoleTypeConsole:
 		return s.connectConsole(op, r, w)
-	case instance.ConsoleTypeVGA:
+	case instance.ConsoleTypeVGA, instance.ConsoleTypeVNC:
 		return s.connectVGA(op, r, w)
 	default:
 		return fmt.Errorf("Unknown protocol %q", s.protocol)
@@ -161,7 +162,7 @@ func (s *consoleWs) connectVGA(op *operations.Operation, r *http.Request, w http
 
 		logger.Debug("VGA dynamic websocket connected")
 
-		console, _, err := s.instance.Console("vga")
+		console, _, err := s.instance.Console(s.protocol)
 		if err != nil {
 			_ = conn.Close()
 			return err
@@ -197,7 +198,7 @@ func (s *consoleWs) Do(op *operations.Operation) error {
 	switch s.protocol {
 	case instance.ConsoleTypeConsole:
 		return s.doConsole(op)
-	case instance.ConsoleTypeVGA:
+	case instance.ConsoleTypeVGA, instance.ConsoleTypeVNC:
 		return s.doVGA(op)
 	default:
 		return fmt.Errorf("Unknown protocol %q", s.protocol)
@@ -473,7 +474,7 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Basic parameter validation.
-	if !util.ValueInSlice(post.Type, []string{instance.ConsoleTypeConsole, instance.ConsoleTypeVGA}) {
+	if !util.ValueInSlice(post.Type, []string{instance.ConsoleTypeConsole, instance.ConsoleTypeVGA, instance.ConsoleTypeVNC}) {
 		return response.BadRequest(fmt.Errorf("Unknown console type %q", post.Type))
 	}
 
@@ -482,7 +483,7 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	if post.Type == instance.ConsoleTypeVGA && inst.Type() != instancetype.VM {
+	if post.Type != instance.ConsoleTypeConsole && inst.Type() != instancetype.VM {
 		return response.BadRequest(fmt.Errorf("VGA console is only supported by virtual machines"))
 	}
 
@@ -531,9 +532,9 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 
 // swagger:operation GET /1.0/instances/{name}/console instances instance_console_get
 //
-//	Get console log
+//	Get console output
 //
-//	Gets the console log for the instance.
+//	Gets the console log for the instance, or a PNG screenshot of its VGA console.
 //
 //	---
 //	produces:
@@ -544,9 +545,14 @@ func instanceConsolePost(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: type
+//	    description: Console type (console or vga)
+//	    type: string
+//	    example: console
 //	responses:
 //	  "200":
-//	     description: Raw console log
+//	     description: Raw console log or PNG screenshot
 //	     content:
 //	       application/octet-stream:
 //	         schema:
@@ -588,6 +594,13 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 		return resp
 	}
 
+	consoleType := queryParam(r, "type")
+	if consoleType == instance.ConsoleTypeVGA {
+		return instanceConsoleScreenshotGet(s, r, projectName, name)
+	} else if consoleType != "" && consoleType != instance.ConsoleTypeConsole {
+		return response.BadRequest(fmt.Errorf("Unknown console type %q", consoleType))
+	}
+
 	if !liblxc.RuntimeLiblxcVersionAtLeast(liblxc.Version(), 3, 0, 0) {
 		return response.BadRequest(fmt.Errorf("Querying the console buffer requires liblxc >= 3.0"))
 	}
@@ -641,6 +654,45 @@ func instanceConsoleLogGet(d *Daemon, r *http.Request) response.Response {
 	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
 }
 
+// instanceConsoleScreenshotGet returns a PNG screenshot of the VGA console of a virtual machine.
+func instanceConsoleScreenshotGet(s *state.State, r *http.Request, projectName string, name string) response.Response {
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if inst.Type() != instancetype.VM {
+		return response.BadRequest(fmt.Errorf("VGA console is only supported by virtual machines"))
+	}
+
+	if !inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("Instance is not running"))
+	}
+
+	screenshotFile, err := os.CreateTemp("", "incus_screenshot_")
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	defer func() { _ = screenshotFile.Close() }()
+
+	cleanup := func() { _ = os.Remove(screenshotFile.Name()) }
+
+	err = inst.(instance.VM).ConsoleScreenshot(screenshotFile)
+	if err != nil {
+		cleanup()
+		return response.SmartError(err)
+	}
+
+	ent := response.FileResponseEntry{
+		Path:     screenshotFile.Name(),
+		Filename: fmt.Sprintf("%s.png", name),
+		Cleanup:  cleanup,
+	}
+
+	return response.FileResponse(r, []response.FileResponseEntry{ent}, nil)
+}
+
 // swagger:operation DELETE /1.0/instances/{name}/console instances instance_console_delete
 //
 //	Clear the console log
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/gorilla/mux"
+
+	internalInstance "github.com/lxc/incus/internal/instance"
+	"github.com/lxc/incus/internal/server/instance"
+	"github.com/lxc/incus/internal/server/instance/instancetype"
+	"github.com/lxc/incus/internal/server/response"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+)
+
+// instanceDebugMemoryFormats maps the supported guest memory dump formats to their file extension.
+var instanceDebugMemoryFormats = map[string]string{
+	"elf":          "elf",
+	"kdump-zlib":   "kdump",
+	"kdump-lzo":    "kdump",
+	"kdump-snappy": "kdump",
+	"win-dmp":      "dmp",
+}
+
+var instanceDebugMemoryCmd = APIEndpoint{
+	Name: "instanceDebugMemory",
+	Path: "instances/{name}/debug/memory",
+
+	Post: APIEndpointAction{Handler: instanceDebugMemoryPost, AccessHandler: allowProjectPermission()},
+}
+
+// swagger:operation POST /1.0/instances/{name}/debug/memory instances instance_debug_memory_post
+//
+//	Dump the guest memory
+//
+//	Dumps the memory of a running virtual machine and streams it back.
+//	If the dump fails once streaming started, the error is sent in the X-Incus-Error trailer.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/octet-stream
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: memory
+//	    description: Memory dump request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/InstanceDebugMemoryPost"
+//	responses:
+//	  "200":
+//	     description: Raw memory dump
+//	     content:
+//	       application/octet-stream:
+//	         schema:
+//	           type: string
+//	           format: binary
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceDebugMemoryPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := projectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	// Forward the request if the instance is remote.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	req := api.InstanceDebugMemoryPost{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Format == "" {
+		req.Format = "elf"
+	}
+
+	ext, ok := instanceDebugMemoryFormats[req.Format]
+	if !ok {
+		return response.BadRequest(fmt.Errorf("Invalid memory dump format %q", req.Format))
+	}
+
+	inst, err := instance.LoadByProjectAndName(s, projectName, name)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if inst.Type() != instancetype.VM {
+		return response.BadRequest(fmt.Errorf("Memory dumps are only supported for virtual machines"))
+	}
+
+	if !inst.IsRunning() {
+		return response.BadRequest(fmt.Errorf("Instance is not running"))
+	}
+
+	vm := inst.(instance.VM)
+
+	// Leave the instance paused once the dump is complete.
+	if req.Pause && !inst.IsFrozen() {
+		err = inst.Freeze()
+		if err != nil {
+			return response.SmartError(fmt.Errorf("Failed pausing instance: %w", err))
+		}
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		pipeRead, pipeWrite, err := os.Pipe()
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = pipeRead.Close() }()
+
+		dumpErr := make(chan error, 1)
+		go func() {
+			dumpErr <- vm.DumpGuestMemory(pipeWrite, req.Format)
+			_ = pipeWrite.Close()
+		}()
+
+		// Wait for the dump to produce data so that early failures can still be reported.
+		buf := make([]byte, 32*1024)
+		n, err := pipeRead.Read(buf)
+		if n == 0 {
+			_ = pipeRead.Close()
+
+			errDump := <-dumpErr
+			if errDump != nil {
+				return errDump
+			}
+
+			if err != nil && err != io.EOF {
+				return err
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", name, ext))
+		w.Header().Set("Trailer", "X-Incus-Error")
+		w.WriteHeader(http.StatusOK)
+
+		_, err = w.Write(buf[:n])
+		if err == nil {
+			_, err = io.Copy(w, pipeRead)
+		}
+
+		// Closing the pipe makes the dump fail if the client went away.
+		_ = pipeRead.Close()
+
+		// The response has already started so errors are reported to the client through a trailer.
+		errDump := <-dumpErr
+		if errDump != nil {
+			logger.Error("Failed dumping guest memory", logger.Ctx{"project": projectName, "instance": name, "err": errDump})
+			w.Header().Set("X-Incus-Error", errDump.Error())
+		} else if err != nil {
+			logger.Warn("Failed sending guest memory dump", logger.Ctx{"project": projectName, "instance": name, "err": err})
+		}
+
+		return nil
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/lxc/incus/internal/server/response"
 	localUtil "github.com/lxc/incus/internal/server/util"
 	"github.com/lxc/incus/shared/api"
+	apiScriptlet "github.com/lxc/incus/shared/api/scriptlet"
 	"github.com/lxc/incus/shared/osarch"
 )
 
@@ -202,6 +203,11 @@ func instancePatch(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	err = instanceValidationRun(r.Context(), s, apiScriptlet.InstanceValidationReasonUpdate, projectName, name, c.Type().String(), req, apiProfiles)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Update container configuration
 	args := db.InstanceArgs{
 		Architecture: architecture,
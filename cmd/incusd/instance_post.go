@@ -259,7 +259,8 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 
-		// If no member was selected yet, pick the member with the least number of instances.
+		// If no member was selected yet, pick the member with the least number of instances, taking
+		// the instance's placement group into account.
 		if targetMemberInfo == nil {
 			var filteredCandidateMembers []db.NodeInfo
 
@@ -272,7 +273,8 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			}
 
 			err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-				targetMemberInfo, err = tx.GetNodeWithLeastInstances(ctx, filteredCandidateMembers)
+				expandedConfig := inst.ExpandedConfig()
+				targetMemberInfo, err = tx.GetNodeForPlacementGroup(ctx, filteredCandidateMembers, projectName, expandedConfig["placement.group"], expandedConfig["placement.policy"])
 				return err
 			})
 			if err != nil {
@@ -440,6 +442,22 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 		return response.Conflict(fmt.Errorf("Name %q already in use", req.Name))
 	}
 
+	// Run the validation scriptlet against the renamed instance.
+	renderRes, _, err := inst.Render()
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed getting instance info: %w", err))
+	}
+
+	instInfo, ok := renderRes.(*api.Instance)
+	if !ok {
+		return response.InternalError(fmt.Errorf("Unexpected result from instance render"))
+	}
+
+	err = instanceValidationRun(r.Context(), s, apiScriptlet.InstanceValidationReasonRename, projectName, req.Name, inst.Type().String(), instInfo.Writable(), inst.Profiles())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	run := func(*operations.Operation) error {
 		return inst.Rename(req.Name, true)
 	}
@@ -25,6 +25,7 @@ import (
 	localUtil "github.com/lxc/incus/internal/server/util"
 	"github.com/lxc/incus/internal/version"
 	"github.com/lxc/incus/shared/api"
+	apiScriptlet "github.com/lxc/incus/shared/api/scriptlet"
 	"github.com/lxc/incus/shared/osarch"
 )
 
@@ -149,6 +150,11 @@ func instancePut(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
+		err = instanceValidationRun(r.Context(), s, apiScriptlet.InstanceValidationReasonUpdate, projectName, name, inst.Type().String(), configRaw, apiProfiles)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
 		// Update container configuration
 		do = func(op *operations.Operation) error {
 			defer unlock()
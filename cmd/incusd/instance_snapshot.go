@@ -248,13 +248,14 @@ func instanceSnapshotsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Invalid instance name"))
 	}
 
+	var p *api.Project
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		dbProject, err := cluster.GetProject(context.Background(), tx.Tx(), projectName)
 		if err != nil {
 			return err
 		}
 
-		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		p, err = dbProject.ToAPI(ctx, tx.Tx())
 		if err != nil {
 			return err
 		}
@@ -291,6 +292,16 @@ func instanceSnapshotsPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	snapshots, err := inst.Snapshots()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = project.AllowSnapshotCount(p, len(snapshots))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	req := api.InstanceSnapshotsPost{}
 	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	internalInstance "github.com/lxc/incus/internal/instance"
+	"github.com/lxc/incus/internal/server/db"
+	dbCluster "github.com/lxc/incus/internal/server/db/cluster"
+	"github.com/lxc/incus/internal/server/instance"
+	"github.com/lxc/incus/internal/server/instance/instancetype"
+	"github.com/lxc/incus/internal/server/response"
+	"github.com/lxc/incus/internal/server/state"
+	"github.com/lxc/incus/internal/server/task"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+)
+
+// instanceUsageInterval is how often the resource usage of the local instances is sampled.
+const instanceUsageInterval = 5 * time.Minute
+
+// instanceUsageRetention is how long the resource usage samples are kept for.
+const instanceUsageRetention = 30 * 24 * time.Hour
+
+var instanceUsageCmd = APIEndpoint{
+	Name: "instanceUsage",
+	Path: "instances/{name}/usage",
+
+	Get: APIEndpointAction{Handler: instanceUsageGet, AccessHandler: allowProjectPermission()},
+}
+
+// swagger:operation GET /1.0/instances/{name}/usage instances instance_usage_get
+//
+//	Get the resource usage history
+//
+//	Gets the CPU, memory, disk and network usage of the instance over time.
+//
+//	The usage is sampled every 5 minutes by the server the instance is running on
+//	and kept for 30 days, including across moves between cluster members.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: since
+//	    description: Start of the history (RFC3339, defaults to 24 hours ago)
+//	    type: string
+//	    example: 2023-10-18T00:00:00Z
+//	  - in: query
+//	    name: step
+//	    description: Duration of each entry in seconds (defaults to 3600)
+//	    type: integer
+//	    example: 3600
+//	responses:
+//	  "200":
+//	    description: Usage history
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/InstanceUsage"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func instanceUsageGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	instanceType, err := urlInstanceTypeDetect(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	projectName := projectParam(r)
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if internalInstance.IsSnapshot(name) {
+		return response.BadRequest(fmt.Errorf("Invalid instance name"))
+	}
+
+	since, now, step, err := usageHistoryRange(r, "since", "", 24*time.Hour, time.Hour, instanceUsageInterval)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Handle requests targeted to an instance on a different node.
+	resp, err := forwardedResponseIfInstanceIsRemote(s, r, projectName, name, instanceType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if resp != nil {
+		return resp
+	}
+
+	var instanceID int64
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		inst, err := dbCluster.GetInstance(ctx, tx.Tx(), projectName, name)
+		if err != nil {
+			return err
+		}
+
+		if instanceType != instancetype.Any && inst.Type != instanceType {
+			return api.StatusErrorf(http.StatusNotFound, "Instance not found")
+		}
+
+		instanceID = int64(inst.ID)
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// The usage history is kept in the local database of the member running the instance.
+	// Also get the last samples before the start of the history to compute the usage of the first step.
+	var samples []db.InstanceUsage
+	err = s.DB.Node.Transaction(r.Context(), func(ctx context.Context, tx *db.NodeTx) error {
+		samples, err = tx.GetInstanceUsage(ctx, instanceID, since.Add(-2*instanceUsageInterval))
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	usage := api.InstanceUsage{
+		Since:   since,
+		Step:    int64(step.Seconds()),
+		Entries: instanceUsageAggregate(samples, since, now, step),
+	}
+
+	return response.SyncResponse(true, usage)
+}
+
+// instanceUsageAggregate turns the resource usage samples of an instance into one entry per step between
+// since and until.
+//
+// The CPU and network usage of an entry is the increase of the cumulative counters over the step.
+func instanceUsageAggregate(samples []db.InstanceUsage, since time.Time, until time.Time, step time.Duration) []api.InstanceUsageEntry {
+	entries := usageHistoryEntries(since, until, step, func(timestamp time.Time) api.InstanceUsageEntry {
+		return api.InstanceUsageEntry{Timestamp: timestamp}
+	})
+
+	memoryTotal := make([]int64, len(entries))
+
+	var prev *db.InstanceUsage
+	for i := range samples {
+		sample := samples[i]
+
+		index, ok := usageHistoryIndex(sample.Timestamp, since, until, step)
+		if !ok {
+			prev = &samples[i]
+			continue
+		}
+
+		entry := &entries[index]
+
+		entry.Samples++
+		memoryTotal[index] += sample.MemoryUsage
+
+		if sample.MemoryUsage > entry.MemoryUsagePeak {
+			entry.MemoryUsagePeak = sample.MemoryUsage
+		}
+
+		if sample.DiskUsage > entry.DiskUsage {
+			entry.DiskUsage = sample.DiskUsage
+		}
+
+		delta := instanceUsageDelta(prev, sample)
+		entry.CPUUsage += delta.CPUUsage
+		entry.NetworkReceived += delta.NetworkReceived
+		entry.NetworkSent += delta.NetworkSent
+
+		prev = &samples[i]
+	}
+
+	for i := range entries {
+		if entries[i].Samples > 0 {
+			entries[i].MemoryUsage = memoryTotal[i] / entries[i].Samples
+		}
+	}
+
+	return entries
+}
+
+// instanceUsageDelta returns the increase of the cumulative CPU and network counters of an instance since its
+// previous sample. When a counter goes backwards (the instance was restarted), the new value is counted as the
+// usage since the previous sample. Samples too far apart are from separate runs of the instance, so only the new
+// counters are counted.
+func instanceUsageDelta(prev *db.InstanceUsage, sample db.InstanceUsage) db.InstanceUsage {
+	if prev == nil || sample.InstanceID != prev.InstanceID || sample.Timestamp.Sub(prev.Timestamp) > 2*instanceUsageInterval {
+		prev = &db.InstanceUsage{}
+	}
+
+	delta := func(prev int64, cur int64) int64 {
+		if cur < prev {
+			return cur
+		}
+
+		return cur - prev
+	}
+
+	return db.InstanceUsage{
+		InstanceID:      sample.InstanceID,
+		Timestamp:       sample.Timestamp,
+		CPUUsage:        delta(prev.CPUUsage, sample.CPUUsage),
+		NetworkReceived: delta(prev.NetworkReceived, sample.NetworkReceived),
+		NetworkSent:     delta(prev.NetworkSent, sample.NetworkSent),
+	}
+}
+
+// instanceUsageSample records the resource usage of the running local instances and removes the expired samples.
+// Each member samples its own instances and stores the samples in its local database.
+func instanceUsageSample(ctx context.Context, s *state.State) error {
+	instances, err := instance.LoadNodeAll(s, instancetype.Any)
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	hostInterfaces, _ := net.Interfaces()
+	now := time.Now()
+
+	samples := make([]db.InstanceUsage, 0, len(instances))
+	for _, inst := range instances {
+		if !inst.IsRunning() {
+			continue
+		}
+
+		instState, err := inst.RenderState(hostInterfaces)
+		if err != nil {
+			logger.Warn("Failed getting instance usage", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			continue
+		}
+
+		sample := db.InstanceUsage{
+			InstanceID:  int64(inst.ID()),
+			Timestamp:   now,
+			CPUUsage:    instState.CPU.Usage,
+			MemoryUsage: instState.Memory.Usage,
+		}
+
+		for _, disk := range instState.Disk {
+			sample.DiskUsage += disk.Usage
+		}
+
+		for _, nic := range instState.Network {
+			if nic.Type == "loopback" {
+				continue
+			}
+
+			sample.NetworkReceived += nic.Counters.BytesReceived
+			sample.NetworkSent += nic.Counters.BytesSent
+		}
+
+		samples = append(samples, sample)
+	}
+
+	instanceIDs := make([]int64, 0, len(instances))
+	for _, inst := range instances {
+		instanceIDs = append(instanceIDs, int64(inst.ID()))
+	}
+
+	// Also drop the history of the instances which were deleted or moved to another member.
+	return s.DB.Node.Transaction(ctx, func(ctx context.Context, tx *db.NodeTx) error {
+		for _, sample := range samples {
+			err := tx.CreateInstanceUsage(ctx, sample)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.DeleteInstanceUsage(ctx, now.Add(-instanceUsageRetention), instanceIDs)
+	})
+}
+
+func instanceUsageTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := instanceUsageSample(ctx, d.State())
+		if err != nil {
+			logger.Error("Failed recording instance usage", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(instanceUsageInterval)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/internal/server/db"
+)
+
+func TestInstanceUsageAggregate(t *testing.T) {
+	since := time.Date(2023, 10, 18, 0, 0, 0, 0, time.UTC)
+	samples := []db.InstanceUsage{
+		// Baseline sample taken before the start of the history.
+		usageTestSample(1, since.Add(-5*time.Minute), 100, 10, 1000),
+		usageTestSample(1, since, 150, 20, 1500),
+		usageTestSample(1, since.Add(5*time.Minute), 250, 40, 1700),
+
+		// The instance was restarted, the counters start again from zero.
+		usageTestSample(1, since.Add(10*time.Minute), 30, 30, 200),
+
+		// The instance was stopped for a while.
+		usageTestSample(1, since.Add(40*time.Minute), 60, 50, 300),
+	}
+
+	entries := instanceUsageAggregate(samples, since, since.Add(time.Hour), 30*time.Minute)
+	assert.Len(t, entries, 2)
+
+	assert.Equal(t, since, entries[0].Timestamp)
+	assert.Equal(t, int64(3), entries[0].Samples)
+	assert.Equal(t, int64(50+100+30), entries[0].CPUUsage)
+	assert.Equal(t, int64(30), entries[0].MemoryUsage)
+	assert.Equal(t, int64(40), entries[0].MemoryUsagePeak)
+	assert.Equal(t, int64(1000), entries[0].DiskUsage)
+	assert.Equal(t, int64(500+200+200), entries[0].NetworkReceived)
+
+	assert.Equal(t, since.Add(30*time.Minute), entries[1].Timestamp)
+	assert.Equal(t, int64(1), entries[1].Samples)
+	assert.Equal(t, int64(60), entries[1].CPUUsage)
+	assert.Equal(t, int64(50), entries[1].MemoryUsage)
+	assert.Equal(t, int64(300), entries[1].NetworkReceived)
+}
+
+func TestInstanceUsageAggregateEmpty(t *testing.T) {
+	since := time.Date(2023, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	entries := instanceUsageAggregate(nil, since, since.Add(90*time.Minute), time.Hour)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, int64(0), entries[0].Samples)
+	assert.Equal(t, int64(0), entries[1].Samples)
+}
@@ -1081,6 +1081,13 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	if !clusterNotification {
+		err = instanceValidationRun(r.Context(), s, apiScriptlet.InstanceValidationReasonCreate, targetProjectName, req.Name, string(req.Type), req.InstancePut, profiles)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if clustered && !clusterNotification && targetMemberInfo == nil {
 		// Run instance placement scriptlet if enabled and no cluster member selected yet.
 		if s.GlobalConfig.InstancesPlacementScriptlet() != "" {
@@ -1106,9 +1113,12 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		}
 
 		// If no target member was selected yet, pick the member with the least number of instances.
+		// Instances sharing a placement group are placed according to the group's policy.
 		if targetMemberInfo == nil {
+			expandedConfig := db.ExpandInstanceConfig(req.Config, profiles)
+
 			err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-				targetMemberInfo, err = tx.GetNodeWithLeastInstances(ctx, candidateMembers)
+				targetMemberInfo, err = tx.GetNodeForPlacementGroup(ctx, candidateMembers, targetProjectName, expandedConfig["placement.group"], expandedConfig["placement.policy"])
 				return err
 			})
 			if err != nil {
@@ -44,6 +44,16 @@ func newMigrationSource(inst instance.Instance, stateful bool, instanceOnly bool
 			if err != nil {
 				return nil, migration.ErrNoLiveMigrationSource
 			}
+
+			c, ok := inst.(instance.Container)
+			if !ok {
+				return nil, fmt.Errorf("Instance is not container type")
+			}
+
+			err = c.CheckLiveMigration()
+			if err != nil {
+				return nil, fmt.Errorf("Unable to perform live container migration: %w", err)
+			}
 		}
 
 		ret.live = true
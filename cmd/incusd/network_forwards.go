@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"github.com/gorilla/mux"
 
 	clusterRequest "github.com/lxc/incus/internal/server/cluster/request"
+	"github.com/lxc/incus/internal/server/db"
 	"github.com/lxc/incus/internal/server/lifecycle"
 	"github.com/lxc/incus/internal/server/network"
 	"github.com/lxc/incus/internal/server/project"
@@ -260,11 +262,32 @@ func networkForwardsPost(d *Daemon, r *http.Request) response.Response {
 
 	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
 
+	// Check if the project allows for another network forward, unless this is a notification from the
+	// cluster member which already checked it.
+	if clientType != clusterRequest.ClientTypeNotifier {
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return project.AllowNetworkForwardCreation(tx, reqProject)
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	err = n.ForwardCreate(req, clientType)
 	if err != nil {
 		return response.SmartError(fmt.Errorf("Failed creating forward: %w", err))
 	}
 
+	// Record the project creating the forward on a network of the default project so it's counted against its limits.
+	if clientType != clusterRequest.ClientTypeNotifier && reqProject.Name != projectName {
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetworkForwardProject(ctx, n.ID(), req.ListenAddress, reqProject.Name)
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	lc := lifecycle.NetworkForwardCreated.Event(n, req.ListenAddress, request.CreateRequestor(r), nil)
 	s.Events.SendLifecycle(projectName, lc)
 
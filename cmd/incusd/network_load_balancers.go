@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"github.com/gorilla/mux"
 
 	clusterRequest "github.com/lxc/incus/internal/server/cluster/request"
+	"github.com/lxc/incus/internal/server/db"
 	"github.com/lxc/incus/internal/server/lifecycle"
 	"github.com/lxc/incus/internal/server/network"
 	"github.com/lxc/incus/internal/server/project"
@@ -261,11 +263,32 @@ func networkLoadBalancersPost(d *Daemon, r *http.Request) response.Response {
 
 	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
 
+	// Check if the project allows for another network load balancer, unless this is a notification from the
+	// cluster member which already checked it.
+	if clientType != clusterRequest.ClientTypeNotifier {
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return project.AllowNetworkLoadBalancerCreation(tx, reqProject)
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	err = n.LoadBalancerCreate(req, clientType)
 	if err != nil {
 		return response.SmartError(fmt.Errorf("Failed creating load balancer: %w", err))
 	}
 
+	// Record the project creating the load balancer on a network of the default project so it's counted against its limits.
+	if clientType != clusterRequest.ClientTypeNotifier && reqProject.Name != projectName {
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateNetworkLoadBalancerProject(ctx, n.ID(), req.ListenAddress, reqProject.Name)
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	lc := lifecycle.NetworkLoadBalancerCreated.Event(n, req.ListenAddress, request.CreateRequestor(r), nil)
 	s.Events.SendLifecycle(projectName, lc)
 
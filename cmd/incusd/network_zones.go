@@ -1,22 +1,31 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/lxc/incus/internal/server/cluster"
 	clusterRequest "github.com/lxc/incus/internal/server/cluster/request"
+	"github.com/lxc/incus/internal/server/db"
 	"github.com/lxc/incus/internal/server/lifecycle"
 	"github.com/lxc/incus/internal/server/network/zone"
 	"github.com/lxc/incus/internal/server/project"
 	"github.com/lxc/incus/internal/server/request"
 	"github.com/lxc/incus/internal/server/response"
+	"github.com/lxc/incus/internal/server/state"
+	"github.com/lxc/incus/internal/server/task"
 	localUtil "github.com/lxc/incus/internal/server/util"
 	"github.com/lxc/incus/internal/version"
 	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+	"github.com/lxc/incus/shared/util"
 )
 
 var networkZonesCmd = APIEndpoint{
@@ -35,6 +44,12 @@ var networkZoneCmd = APIEndpoint{
 	Patch:  APIEndpointAction{Handler: networkZonePut, AccessHandler: allowProjectPermission()},
 }
 
+var networkZoneDNSSECCmd = APIEndpoint{
+	Path: "network-zones/{zone}/dnssec",
+
+	Get: APIEndpointAction{Handler: networkZoneDNSSECGet, AccessHandler: allowProjectPermission()},
+}
+
 // API endpoints.
 
 // swagger:operation GET /1.0/network-zones network-zones network_zones_get
@@ -483,3 +498,132 @@ func networkZonePut(d *Daemon, r *http.Request) response.Response {
 
 	return response.EmptySyncResponse
 }
+
+// swagger:operation GET /1.0/network-zones/{zone}/dnssec network-zones network_zone_dnssec_get
+//
+//	Get the network zone DNSSEC keys
+//
+//	Gets the public part of the keys used to sign the network zone, along with the DS records to publish in the parent zone.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: DNSSEC keys
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkZoneDNSSEC"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkZoneDNSSECGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkZoneProject(s.DB.Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	zoneName, err := url.PathUnescape(mux.Vars(r)["zone"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	netzone, err := zone.LoadByNameAndProject(s, projectName, zoneName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info, err := netzone.DNSSEC()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, info)
+}
+
+// rotateDNSSECKeys generates and rolls over the signing keys of the network zones with DNSSEC enabled.
+func rotateDNSSECKeys(ctx context.Context, s *state.State) error {
+	var zoneNames []string
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		zones, err := tx.GetNetworkZones(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed loading network zones: %w", err)
+		}
+
+		for zoneName := range zones {
+			zoneNames = append(zoneNames, zoneName)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, zoneName := range zoneNames {
+		netzone, err := zone.LoadByName(s, zoneName)
+		if err != nil {
+			logger.Error("Failed loading network zone", logger.Ctx{"zone": zoneName, "err": err})
+			continue
+		}
+
+		if util.IsFalseOrEmpty(netzone.Info().Config["dnssec.enabled"]) {
+			continue
+		}
+
+		err = netzone.RotateDNSSECKeys()
+		if err != nil {
+			logger.Error("Failed rotating DNSSEC keys", logger.Ctx{"zone": zoneName, "err": err})
+		}
+	}
+
+	return nil
+}
+
+func autoRotateDNSSECKeysTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// Only the cluster leader rotates the keys, so that members don't race to generate them.
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if err == nil && s.LocalConfig.ClusterAddress() != leader {
+			return
+		}
+
+		err = rotateDNSSECKeys(ctx, s)
+		if err != nil {
+			logger.Error("Failed rotating DNSSEC keys", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Hour)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/lxc/incus/internal/server/resources"
 	"github.com/lxc/incus/internal/server/response"
 	"github.com/lxc/incus/internal/server/state"
+	"github.com/lxc/incus/internal/server/task"
 	localUtil "github.com/lxc/incus/internal/server/util"
 	"github.com/lxc/incus/internal/server/warnings"
 	"github.com/lxc/incus/internal/version"
@@ -66,6 +67,12 @@ var networkLeasesCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: networkLeasesGet, AccessHandler: allowProjectPermission()},
 }
 
+var networkFlowsCmd = APIEndpoint{
+	Path: "networks/{networkName}/flows",
+
+	Get: APIEndpointAction{Handler: networkFlowsGet, AccessHandler: allowProjectPermission()},
+}
+
 var networkStateCmd = APIEndpoint{
 	Path: "networks/{networkName}/state",
 
@@ -1434,6 +1441,91 @@ func networkLeasesGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, leases)
 }
 
+// swagger:operation GET /1.0/networks/{name}/flows networks networks_flows_get
+//
+//	Get the network flows
+//
+//	Returns the connections recorded by the flow log of the network.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: server01
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of network flows
+//	          items:
+//	            $ref: "#/definitions/NetworkFlow"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkFlowsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, reqProject, err := project.NetworkProject(s.DB.Cluster, projectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	networkName, err := url.PathUnescape(mux.Vars(r)["networkName"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Attempt to load the network.
+	n, err := network.LoadByName(s, projectName, networkName)
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading network: %w", err))
+	}
+
+	// Check if project allows access to network.
+	if !project.NetworkAllowed(reqProject.Config, networkName, n.IsManaged()) {
+		return response.SmartError(api.StatusErrorf(http.StatusNotFound, "Network not found"))
+	}
+
+	if !n.Info().FlowLogging {
+		return response.BadRequest(fmt.Errorf("Network driver %q does not support flow logging", n.Type()))
+	}
+
+	clientType := clusterRequest.UserAgentClientType(r.Header.Get("User-Agent"))
+	flows, err := n.Flows(reqProject.Name, clientType)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, flows)
+}
+
 func networkStartup(s *state.State) error {
 	var err error
 
@@ -1809,3 +1901,90 @@ func networkStateGet(d *Daemon, r *http.Request) response.Response {
 
 	return response.SyncResponse(true, state)
 }
+
+// networkFlowsUpdate records the connections of the local networks that have flow logging enabled.
+func networkFlowsUpdate(ctx context.Context, s *state.State) {
+	var projectNetworks map[string]map[int64]api.Network
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		projectNetworks, err = tx.GetCreatedNetworks(ctx)
+		return err
+	})
+	if err != nil {
+		logger.Warn("Failed loading networks for flow logging", logger.Ctx{"err": err})
+		return
+	}
+
+	for projectName, networks := range projectNetworks {
+		for _, netInfo := range networks {
+			if util.IsFalseOrEmpty(netInfo.Config["flows.logging"]) {
+				continue
+			}
+
+			n, err := network.LoadByName(s, projectName, netInfo.Name)
+			if err != nil {
+				logger.Warn("Failed loading network for flow logging", logger.Ctx{"project": projectName, "network": netInfo.Name, "err": err})
+				continue
+			}
+
+			err = n.FlowsUpdate()
+			if err != nil {
+				logger.Warn("Failed updating network flow log", logger.Ctx{"project": projectName, "network": netInfo.Name, "err": err})
+			}
+		}
+	}
+}
+
+func networkFlowsUpdateTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		networkFlowsUpdate(ctx, d.State())
+	}
+
+	return f, task.Every(30 * time.Second)
+}
+
+// networkPrefixDelegationUpdate requests or renews the IPv6 prefixes delegated to the uplink networks of the local member.
+func networkPrefixDelegationUpdate(ctx context.Context, s *state.State) {
+	var projectNetworks map[string]map[int64]api.Network
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		projectNetworks, err = tx.GetCreatedNetworks(ctx)
+		return err
+	})
+	if err != nil {
+		logger.Warn("Failed loading networks for prefix delegation", logger.Ctx{"err": err})
+		return
+	}
+
+	for projectName, networks := range projectNetworks {
+		for _, netInfo := range networks {
+			if util.IsFalseOrEmpty(netInfo.Config["ipv6.prefix_delegation.request"]) {
+				continue
+			}
+
+			n, err := network.LoadByName(s, projectName, netInfo.Name)
+			if err != nil {
+				logger.Warn("Failed loading network for prefix delegation", logger.Ctx{"project": projectName, "network": netInfo.Name, "err": err})
+				continue
+			}
+
+			err = network.PrefixDelegationUpdate(ctx, s, n)
+			if err != nil {
+				logger.Warn("Failed updating delegated prefix", logger.Ctx{"project": projectName, "network": netInfo.Name, "err": err})
+			}
+		}
+	}
+}
+
+func networkPrefixDelegationTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		// Each cluster member requests its own prefixes on its own interfaces.
+		networkPrefixDelegationUpdate(ctx, d.State())
+	}
+
+	return f, task.Every(time.Minute)
+}
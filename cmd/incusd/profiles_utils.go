@@ -13,6 +13,8 @@ import (
 	"github.com/lxc/incus/internal/server/project"
 	"github.com/lxc/incus/internal/server/state"
 	"github.com/lxc/incus/shared/api"
+	apiScriptlet "github.com/lxc/incus/shared/api/scriptlet"
+	"github.com/lxc/incus/shared/osarch"
 )
 
 func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64, profile *api.Profile, req api.ProfilePut) error {
@@ -78,6 +80,41 @@ func doProfileUpdate(s *state.State, p api.Project, profileName string, id int64
 		}
 	}
 
+	// Run the validation scriptlet against the instances using the profile, with the profile change applied.
+	if s.GlobalConfig.InstancesValidationScriptlet() != "" {
+		for _, inst := range insts {
+			profiles := make([]api.Profile, 0, len(inst.Profiles))
+			profileNames := make([]string, 0, len(inst.Profiles))
+			for _, instProfile := range inst.Profiles {
+				if instProfile.Name == profileName {
+					instProfile.Config = req.Config
+					instProfile.Devices = req.Devices
+				}
+
+				profiles = append(profiles, instProfile)
+				profileNames = append(profileNames, instProfile.Name)
+			}
+
+			// Ignore err as the arch string on error is correct (unknown)
+			architectureName, _ := osarch.ArchitectureName(inst.Architecture)
+
+			instPut := api.InstancePut{
+				Architecture: architectureName,
+				Config:       inst.Config,
+				Devices:      inst.Devices.CloneNative(),
+				Ephemeral:    inst.Ephemeral,
+				Profiles:     profileNames,
+				Stateful:     inst.Stateful,
+				Description:  inst.Description,
+			}
+
+			err = instanceValidationRun(context.TODO(), s, apiScriptlet.InstanceValidationReasonUpdate, inst.Project, inst.Name, inst.Type.String(), instPut, profiles)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// Update the database.
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		devices, err := cluster.APIToDevices(req.Devices)
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/internal/server/cluster"
+	"github.com/lxc/incus/internal/server/db"
+	dbCluster "github.com/lxc/incus/internal/server/db/cluster"
+	"github.com/lxc/incus/internal/server/instance"
+	"github.com/lxc/incus/internal/server/instance/instancetype"
+	"github.com/lxc/incus/internal/server/resources"
+	"github.com/lxc/incus/internal/server/response"
+	"github.com/lxc/incus/internal/server/state"
+	storagePools "github.com/lxc/incus/internal/server/storage"
+	storageDrivers "github.com/lxc/incus/internal/server/storage/drivers"
+	"github.com/lxc/incus/internal/server/task"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+)
+
+// projectUsageInterval is how often the resource usage of the projects is sampled.
+const projectUsageInterval = time.Hour
+
+// projectUsageRetention is how long the resource usage samples are kept for.
+const projectUsageRetention = 400 * 24 * time.Hour
+
+var projectUsageCmd = APIEndpoint{
+	Path: "projects/{name}/usage",
+
+	Get: APIEndpointAction{Handler: projectUsageGet, AccessHandler: allowAuthenticated},
+}
+
+// swagger:operation GET /1.0/projects/{name}/usage projects project_usage_get
+//
+//	Get the project usage history
+//
+//	Gets the instance hours, CPU hours and disk GB hours used by the project over time.
+//
+//	The resources allocated to each project are sampled every hour by the cluster leader
+//	and kept for 400 days.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: start
+//	    description: Start of the history (RFC3339, defaults to 30 days before the end)
+//	    type: string
+//	    example: 2023-10-01T00:00:00Z
+//	  - in: query
+//	    name: end
+//	    description: End of the history (RFC3339, defaults to now)
+//	    type: string
+//	    example: 2023-11-01T00:00:00Z
+//	  - in: query
+//	    name: step
+//	    description: Duration of each entry in seconds (defaults to 86400)
+//	    type: integer
+//	    example: 86400
+//	responses:
+//	  "200":
+//	    description: Usage history
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ProjectUsage"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func projectUsageGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Check user permissions.
+	if !s.Authorizer.UserHasPermission(r, name, "") {
+		return response.Forbidden(nil)
+	}
+
+	start, end, step, err := usageHistoryRange(r, "start", "end", 30*24*time.Hour, 24*time.Hour, projectUsageInterval)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var samples []db.ProjectUsage
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Check the project exists.
+		_, err := dbCluster.GetProject(ctx, tx.Tx(), name)
+		if err != nil {
+			return err
+		}
+
+		samples, err = tx.GetProjectUsage(ctx, name, start, end)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	usage := api.ProjectUsage{
+		Start:   start,
+		End:     end,
+		Step:    int64(step.Seconds()),
+		Entries: projectUsageAggregate(samples, start, end, step),
+	}
+
+	return response.SyncResponse(true, usage)
+}
+
+// projectUsageAggregate turns the resource usage samples of a project into one entry per step between start
+// and end. Each sample accounts for its allocations over its duration.
+func projectUsageAggregate(samples []db.ProjectUsage, start time.Time, end time.Time, step time.Duration) []api.ProjectUsageEntry {
+	entries := usageHistoryEntries(start, end, step, func(timestamp time.Time) api.ProjectUsageEntry {
+		return api.ProjectUsageEntry{Timestamp: timestamp}
+	})
+
+	for _, sample := range samples {
+		index, ok := usageHistoryIndex(sample.Timestamp, start, end, step)
+		if !ok {
+			continue
+		}
+
+		entry := &entries[index]
+		hours := sample.Duration.Hours()
+
+		entry.ContainerHours += float64(sample.Containers) * hours
+		entry.VirtualMachineHours += float64(sample.VirtualMachines) * hours
+		entry.CPUHours += float64(sample.CPU) * hours
+		entry.DiskGBHours += float64(sample.Disk) / 1000000000 * hours
+	}
+
+	return entries
+}
+
+// projectUsageCPUs returns the number of vCPUs of an instance from its limits.cpu, instances without it counting
+// as a single vCPU.
+func projectUsageCPUs(config map[string]string) (int64, error) {
+	cpus := config["limits.cpu"]
+	if cpus == "" {
+		return 1, nil
+	}
+
+	if strings.Contains(cpus, ",") || strings.Contains(cpus, "-") {
+		set, err := resources.ParseCpuset(cpus)
+		if err != nil {
+			return 0, err
+		}
+
+		return int64(len(set)), nil
+	}
+
+	return strconv.ParseInt(cpus, 10, 64)
+}
+
+// projectUsageCompute returns the resources allocated to each project from its instance records.
+//
+// Only the running instances are accounted for in the instance counts and vCPUs. The disk is the last known disk
+// space used by the storage volumes of each project.
+func projectUsageCompute(projectNames []string, instances []db.InstanceArgs, diskUsage map[string]int64, timestamp time.Time, duration time.Duration) ([]db.ProjectUsage, error) {
+	usages := make(map[string]*db.ProjectUsage, len(projectNames))
+	for _, projectName := range projectNames {
+		usages[projectName] = &db.ProjectUsage{
+			Project:   projectName,
+			Timestamp: timestamp,
+			Duration:  duration,
+			Disk:      diskUsage[projectName],
+		}
+	}
+
+	for _, inst := range instances {
+		usage := usages[inst.Project]
+		if usage == nil || inst.Snapshot {
+			continue
+		}
+
+		config := db.ExpandInstanceConfig(inst.Config, inst.Profiles)
+		if config["volatile.last_state.power"] != instance.PowerStateRunning {
+			continue
+		}
+
+		cpus, err := projectUsageCPUs(config)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing limits.cpu of instance %q in project %q: %w", inst.Name, inst.Project, err)
+		}
+
+		if inst.Type == instancetype.VM {
+			usage.VirtualMachines++
+		} else {
+			usage.Containers++
+		}
+
+		usage.CPU += cpus
+	}
+
+	result := make([]db.ProjectUsage, 0, len(projectNames))
+	for _, projectName := range projectNames {
+		result = append(result, *usages[projectName])
+	}
+
+	return result, nil
+}
+
+// projectUsageSample records the resources allocated to all the projects and removes the expired samples.
+//
+// The duration of the samples is the time since the previous ones, so that the usage is accounted for once even
+// if the leader changes. If there are no previous samples or they are older than twice the sampling interval,
+// the duration is a single sampling interval.
+func projectUsageSample(ctx context.Context, s *state.State) error {
+	instances := []db.InstanceArgs{}
+	err := s.DB.Cluster.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+		instances = append(instances, inst)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		last, err := tx.GetProjectUsageLastTimestamp(ctx)
+		if err != nil {
+			return err
+		}
+
+		duration := now.Sub(last)
+		if duration < projectUsageInterval/2 {
+			return nil // Already sampled recently, likely by a previous leader.
+		}
+
+		if duration > 2*projectUsageInterval {
+			duration = projectUsageInterval
+		}
+
+		projectNames, err := dbCluster.GetProjectNames(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading projects: %w", err)
+		}
+
+		diskUsage, err := tx.GetProjectsStorageUsage(ctx)
+		if err != nil {
+			return err
+		}
+
+		usages, err := projectUsageCompute(projectNames, instances, diskUsage, now, duration)
+		if err != nil {
+			return err
+		}
+
+		for _, usage := range usages {
+			err = tx.CreateProjectUsage(ctx, usage)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.DeleteProjectUsage(ctx, now.Add(-projectUsageRetention))
+	})
+}
+
+// storageVolumesUsageSample records the disk space used by the instance and custom storage volumes located on
+// this member. The volumes on remote storage pools are only sampled by the cluster leader.
+func storageVolumesUsageSample(ctx context.Context, s *state.State, leader bool) error {
+	volumes := map[int][]db.StorageVolumeArgs{}
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, volumeType := range []int{db.StoragePoolVolumeTypeContainer, db.StoragePoolVolumeTypeVM, db.StoragePoolVolumeTypeCustom} {
+			typeVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, volumeType, true)
+			if err != nil {
+				return fmt.Errorf("Failed loading storage volumes: %w", err)
+			}
+
+			for _, volume := range typeVolumes {
+				if volume.NodeID == -1 && !leader {
+					continue
+				}
+
+				volumes[volumeType] = append(volumes[volumeType], volume)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	used := map[int64]int64{}
+
+	for volumeType, typeVolumes := range volumes {
+		for _, volume := range typeVolumes {
+			l := logger.AddContext(logger.Ctx{"project": volume.ProjectName, "pool": volume.PoolName, "volume": volume.Name})
+
+			pool, err := storagePools.LoadByName(s, volume.PoolName)
+			if err != nil {
+				l.Warn("Failed loading storage pool", logger.Ctx{"err": err})
+				continue
+			}
+
+			var usage *storagePools.VolumeUsage
+			if volumeType == db.StoragePoolVolumeTypeCustom {
+				usage, err = pool.GetCustomVolumeUsage(volume.ProjectName, volume.Name)
+			} else {
+				var inst instance.Instance
+				inst, err = instance.LoadByProjectAndName(s, volume.ProjectName, volume.Name)
+				if err == nil {
+					usage, err = pool.GetInstanceUsage(inst)
+				}
+			}
+
+			if err != nil {
+				if !errors.Is(err, storageDrivers.ErrNotSupported) {
+					l.Warn("Failed getting storage volume usage", logger.Ctx{"err": err})
+				}
+
+				continue
+			}
+
+			used[volume.ID] = usage.Used
+		}
+	}
+
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		for volumeID, volumeUsed := range used {
+			err := tx.UpdateStorageVolumeUsage(ctx, volumeID, now, volumeUsed)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func projectUsageTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// Each member samples its own storage volumes, and the cluster leader records the project usage.
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		isLeader := err != nil || s.LocalConfig.ClusterAddress() == leader
+
+		err = storageVolumesUsageSample(ctx, s, isLeader)
+		if err != nil {
+			logger.Error("Failed recording storage volume usage", logger.Ctx{"err": err})
+		}
+
+		if !isLeader {
+			return
+		}
+
+		err = projectUsageSample(ctx, s)
+		if err != nil {
+			logger.Error("Failed recording project usage", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(projectUsageInterval)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/internal/server/db"
+	"github.com/lxc/incus/internal/server/instance/instancetype"
+	"github.com/lxc/incus/shared/api"
+)
+
+func TestProjectUsageAggregate(t *testing.T) {
+	start := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	samples := []db.ProjectUsage{
+		// Sample taken before the start of the history.
+		{Timestamp: start.Add(-time.Hour), Duration: time.Hour, Containers: 5, CPU: 5},
+		{Timestamp: start, Duration: time.Hour, Containers: 2, VirtualMachines: 1, CPU: 4, Disk: 10000000000},
+		{Timestamp: start.Add(time.Hour), Duration: time.Hour, Containers: 2, VirtualMachines: 1, CPU: 4, Disk: 10000000000},
+
+		// Sample taken half an hour after the previous one.
+		{Timestamp: start.Add(90 * time.Minute), Duration: 30 * time.Minute, Containers: 4, VirtualMachines: 1, CPU: 8, Disk: 20000000000},
+
+		// Sample in the second day.
+		{Timestamp: start.Add(25 * time.Hour), Duration: time.Hour, Containers: 1, CPU: 2},
+	}
+
+	entries := projectUsageAggregate(samples, start, start.Add(48*time.Hour), 24*time.Hour)
+	assert.Len(t, entries, 2)
+
+	assert.Equal(t, start, entries[0].Timestamp)
+	assert.Equal(t, 2+2+2.0, entries[0].ContainerHours)
+	assert.Equal(t, 2.5, entries[0].VirtualMachineHours)
+	assert.Equal(t, 4+4+4.0, entries[0].CPUHours)
+	assert.Equal(t, 10+10+10.0, entries[0].DiskGBHours)
+
+	assert.Equal(t, start.Add(24*time.Hour), entries[1].Timestamp)
+	assert.Equal(t, 1.0, entries[1].ContainerHours)
+	assert.Equal(t, 2.0, entries[1].CPUHours)
+	assert.Equal(t, 0.0, entries[1].DiskGBHours)
+}
+
+func TestProjectUsageCompute(t *testing.T) {
+	now := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)
+	running := map[string]string{"volatile.last_state.power": "RUNNING"}
+	profiles := []api.Profile{{Name: "default", ProfilePut: api.ProfilePut{Config: map[string]string{"limits.cpu": "0-3"}}}}
+
+	instances := []db.InstanceArgs{
+		// Running container with its limits.cpu from a profile.
+		{Project: "default", Name: "c1", Type: instancetype.Container, Config: running, Profiles: profiles},
+
+		// Running container without limits.cpu.
+		{Project: "default", Name: "c2", Type: instancetype.Container, Config: running},
+
+		// Running virtual machine.
+		{Project: "default", Name: "v1", Type: instancetype.VM, Config: map[string]string{"volatile.last_state.power": "RUNNING", "limits.cpu": "2"}},
+
+		// Stopped virtual machine.
+		{Project: "default", Name: "v2", Type: instancetype.VM, Config: map[string]string{"volatile.last_state.power": "STOPPED", "limits.cpu": "8"}},
+
+		// Instance of a project which isn't sampled.
+		{Project: "bar", Name: "c3", Type: instancetype.Container, Config: running},
+	}
+
+	diskUsage := map[string]int64{"default": 5000, "bar": 1000}
+
+	usages, err := projectUsageCompute([]string{"default", "foo"}, instances, diskUsage, now, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []db.ProjectUsage{
+		{Project: "default", Timestamp: now, Duration: time.Hour, Containers: 2, VirtualMachines: 1, CPU: 4 + 1 + 2, Disk: 5000},
+		{Project: "foo", Timestamp: now, Duration: time.Hour},
+	}, usages)
+
+	// Invalid limits.cpu.
+	instances = []db.InstanceArgs{{Project: "foo", Name: "c4", Type: instancetype.Container, Config: map[string]string{"volatile.last_state.power": "RUNNING", "limits.cpu": "many"}}}
+	_, err = projectUsageCompute([]string{"foo"}, instances, nil, now, time.Hour)
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lxc/incus/internal/server/db"
+	"github.com/lxc/incus/internal/server/response"
+	scriptletLoad "github.com/lxc/incus/internal/server/scriptlet/load"
+	localUtil "github.com/lxc/incus/internal/server/util"
+	"github.com/lxc/incus/shared/api"
+)
+
+var scriptletDataCmd = APIEndpoint{
+	Path: "scriptlets/{name}/data",
+
+	Get:   APIEndpointAction{Handler: scriptletDataGet},
+	Put:   APIEndpointAction{Handler: scriptletDataPut},
+	Patch: APIEndpointAction{Handler: scriptletDataPatch},
+}
+
+// scriptletName returns the scriptlet name from the request, validating that it is a known scriptlet.
+func scriptletName(r *http.Request) (string, error) {
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return "", err
+	}
+
+	if name != scriptletLoad.NameInstancePlacement {
+		return "", api.StatusErrorf(http.StatusNotFound, "Scriptlet %q not found", name)
+	}
+
+	return name, nil
+}
+
+// swagger:operation GET /1.0/scriptlets/{name}/data scriptlets scriptlet_data_get
+//
+//	Get the scriptlet data
+//
+//	Gets the key/value store available to the scriptlet.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Scriptlet data
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ScriptletData"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func scriptletDataGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := scriptletName(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	data := api.ScriptletData{}
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		data.Data, err = tx.GetScriptletData(ctx, name)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, data, data)
+}
+
+// swagger:operation PUT /1.0/scriptlets/{name}/data scriptlets scriptlet_data_put
+//
+//	Update the scriptlet data
+//
+//	Replaces the key/value store available to the scriptlet.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: data
+//	    description: Scriptlet data
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ScriptletData"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func scriptletDataPut(d *Daemon, r *http.Request) response.Response {
+	return doScriptletDataUpdate(d, r, false)
+}
+
+// swagger:operation PATCH /1.0/scriptlets/{name}/data scriptlets scriptlet_data_patch
+//
+//	Partially update the scriptlet data
+//
+//	Updates a subset of the key/value store available to the scriptlet.
+//	Keys set to an empty value are removed.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: data
+//	    description: Scriptlet data
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ScriptletData"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func scriptletDataPatch(d *Daemon, r *http.Request) response.Response {
+	return doScriptletDataUpdate(d, r, true)
+}
+
+func doScriptletDataUpdate(d *Daemon, r *http.Request, patch bool) response.Response {
+	s := d.State()
+
+	name, err := scriptletName(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.ScriptletData{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	for key := range req.Data {
+		if key == "" {
+			return response.BadRequest(fmt.Errorf("Scriptlet data keys cannot be empty"))
+		}
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		current := api.ScriptletData{}
+		current.Data, err = tx.GetScriptletData(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		// Validate the ETag against the current data.
+		err = localUtil.EtagCheck(r, current)
+		if err != nil {
+			return api.StatusErrorf(http.StatusPreconditionFailed, "%v", err)
+		}
+
+		if !patch {
+			err = tx.DeleteScriptletData(ctx, name)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.UpdateScriptletData(ctx, name, req.Data)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/lxc/incus/internal/revert"
 	"github.com/lxc/incus/internal/server/db"
+	dbCluster "github.com/lxc/incus/internal/server/db/cluster"
 	"github.com/lxc/incus/internal/server/lifecycle"
 	"github.com/lxc/incus/internal/server/project"
 	"github.com/lxc/incus/internal/server/request"
@@ -377,6 +378,24 @@ func storagePoolBucketsPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Failed loading storage pool: %w", err))
 	}
 
+	// Check if the project allows for another storage bucket.
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), bucketProjectName)
+		if err != nil {
+			return err
+		}
+
+		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		return project.AllowStorageBucketCreation(tx, p)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	revert := revert.New()
 	defer revert.Fail()
 
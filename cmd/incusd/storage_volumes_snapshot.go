@@ -125,13 +125,14 @@ func storagePoolVolumeSnapshotsTypePost(d *Daemon, r *http.Request) response.Res
 		return response.SmartError(err)
 	}
 
+	var p *api.Project
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		dbProject, err := dbCluster.GetProject(context.Background(), tx.Tx(), projectName)
 		if err != nil {
 			return err
 		}
 
-		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		p, err = dbProject.ToAPI(ctx, tx.Tx())
 		if err != nil {
 			return err
 		}
@@ -209,7 +210,13 @@ func storagePoolVolumeSnapshotsTypePost(d *Daemon, r *http.Request) response.Res
 			return err
 		}
 
-		return nil
+		// Check that the project allows for another snapshot of the volume.
+		count, err := tx.GetStorageVolumeSnapshotsCount(ctx, parentDBVolume.ID)
+		if err != nil {
+			return err
+		}
+
+		return project.AllowSnapshotCount(p, count)
 	})
 	if err != nil {
 		return response.SmartError(err)
@@ -1171,6 +1178,17 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 					continue
 				}
 
+				count, err := tx.GetStorageVolumeSnapshotsCount(ctx, v.ID)
+				if err != nil {
+					return fmt.Errorf("Failed counting snapshots of volume %q (project %q): %w", v.Name, v.ProjectName, err)
+				}
+
+				err = project.AllowSnapshotCount(projects[v.ProjectName], count)
+				if err != nil {
+					logger.Warn("Skipping scheduled custom volume snapshot", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName, "err": err})
+					continue
+				}
+
 				if v.NodeID < 0 {
 					// Keep a separate list of remote volumes in order to select a member to
 					// perform the snapshot later.
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// usageMaxEntries is the maximum number of entries returned by a single usage history request.
+const usageMaxEntries = 10000
+
+// usageHistoryRange parses the start, end and step of a usage history request.
+//
+// The end defaults to now and can only be set if endParam isn't empty. The start defaults to span before the end
+// and the step to defaultStep. The step can't be shorter than minStep, which is the sampling interval.
+func usageHistoryRange(r *http.Request, startParam string, endParam string, span time.Duration, defaultStep time.Duration, minStep time.Duration) (time.Time, time.Time, time.Duration, error) {
+	var err error
+
+	end := time.Now().UTC()
+	if endParam != "" && r.FormValue(endParam) != "" {
+		end, err = time.Parse(time.RFC3339, r.FormValue(endParam))
+		if err != nil {
+			return time.Time{}, time.Time{}, 0, fmt.Errorf("Invalid %s value %q: %w", endParam, r.FormValue(endParam), err)
+		}
+	}
+
+	start := end.Add(-span)
+	if r.FormValue(startParam) != "" {
+		start, err = time.Parse(time.RFC3339, r.FormValue(startParam))
+		if err != nil {
+			return time.Time{}, time.Time{}, 0, fmt.Errorf("Invalid %s value %q: %w", startParam, r.FormValue(startParam), err)
+		}
+	}
+
+	step := defaultStep
+	if r.FormValue("step") != "" {
+		seconds, err := strconv.ParseInt(r.FormValue("step"), 10, 64)
+		if err != nil {
+			return time.Time{}, time.Time{}, 0, fmt.Errorf("Invalid step value %q: %w", r.FormValue("step"), err)
+		}
+
+		step = time.Duration(seconds) * time.Second
+	}
+
+	if step < minStep {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("The step must be at least %d seconds", int64(minStep.Seconds()))
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("The start of the history must be before its end")
+	}
+
+	if end.Sub(start)/step > usageMaxEntries {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("Too many entries requested, use a larger step")
+	}
+
+	return start, end, step, nil
+}
+
+// usageHistoryEntries returns one empty entry per step between start and end.
+func usageHistoryEntries[T any](start time.Time, end time.Time, step time.Duration, newEntry func(timestamp time.Time) T) []T {
+	entries := []T{}
+	for timestamp := start; timestamp.Before(end); timestamp = timestamp.Add(step) {
+		entries = append(entries, newEntry(timestamp))
+	}
+
+	return entries
+}
+
+// usageHistoryIndex returns the index of the entry a sample taken at the given time belongs to, and false if the
+// sample is outside of the history.
+func usageHistoryIndex(timestamp time.Time, start time.Time, end time.Time, step time.Duration) (int, bool) {
+	if timestamp.Before(start) || !timestamp.Before(end) {
+		return -1, false
+	}
+
+	return int(timestamp.Sub(start) / step), true
+}
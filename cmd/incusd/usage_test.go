@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/internal/server/db"
+)
+
+// usageTestSample returns a usage sample of an instance taken at the given time.
+func usageTestSample(instanceID int64, timestamp time.Time, cpu int64, memory int64, received int64) db.InstanceUsage {
+	return db.InstanceUsage{
+		InstanceID:      instanceID,
+		Timestamp:       timestamp,
+		CPUUsage:        cpu,
+		MemoryUsage:     memory,
+		DiskUsage:       1000,
+		NetworkReceived: received,
+	}
+}
+
+func TestUsageHistoryRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		start string
+		end   string
+		step  time.Duration
+		err   string
+	}{
+		{
+			name:  "Explicit range and step",
+			query: "start=2023-10-01T00:00:00Z&end=2023-10-02T00:00:00Z&step=3600",
+			start: "2023-10-01T00:00:00Z",
+			end:   "2023-10-02T00:00:00Z",
+			step:  time.Hour,
+		},
+		{
+			name:  "Default start and step",
+			query: "end=2023-10-02T00:00:00Z",
+			start: "2023-10-01T00:00:00Z",
+			end:   "2023-10-02T00:00:00Z",
+			step:  6 * time.Hour,
+		},
+		{
+			name:  "Invalid start",
+			query: "start=yesterday",
+			err:   `Invalid start value "yesterday": parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`,
+		},
+		{
+			name:  "Step shorter than the sampling interval",
+			query: "step=60",
+			err:   "The step must be at least 3600 seconds",
+		},
+		{
+			name:  "Start after end",
+			query: "start=2023-10-03T00:00:00Z&end=2023-10-02T00:00:00Z",
+			err:   "The start of the history must be before its end",
+		},
+		{
+			name:  "Too many entries",
+			query: "start=2000-01-01T00:00:00Z&end=2023-10-02T00:00:00Z&step=3600",
+			err:   "Too many entries requested, use a larger step",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/?"+test.query, nil)
+
+			start, end, step, err := usageHistoryRange(r, "start", "end", 24*time.Hour, 6*time.Hour, time.Hour)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.start, start.Format(time.RFC3339))
+			assert.Equal(t, test.end, end.Format(time.RFC3339))
+			assert.Equal(t, test.step, step)
+		})
+	}
+}
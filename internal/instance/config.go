@@ -154,6 +154,43 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What to do when evacuating the instance
 	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop")),
 
+	// gendoc:generate(entity=instance, group=miscellaneous, key=cluster.rebalance)
+	// Specify whether automatic cluster rebalancing may move the instance:
+	//
+	//   - `auto`: The instance is moved when rebalancing the cluster, provided it can be migrated.
+	//   - `manual`: The instance is never moved by rebalancing, only when requested.
+	//
+	// See {ref}`cluster-rebalance` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `auto`
+	//  liveupdate: yes
+	//  shortdesc: Whether automatic cluster rebalancing may move the instance
+	"cluster.rebalance": validate.Optional(validate.IsOneOf("auto", "manual")),
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=placement.group)
+	// Instances of the same project that share a placement group are placed according to
+	// the group's `placement.policy` when the cluster picks a member for them.
+	//
+	// See {ref}`clustering-instance-placement-groups` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Placement group the instance belongs to
+	"placement.group": validate.IsAny,
+
+	// gendoc:generate(entity=instance, group=miscellaneous, key=placement.policy)
+	// Possible values are `spread` (place instances of the group on different cluster members)
+	// and `pack` (place instances of the group on the same cluster member).
+	//
+	// See {ref}`clustering-instance-placement-groups` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `spread`
+	//  liveupdate: yes
+	//  shortdesc: How to place the instances of the placement group
+	"placement.policy": validate.Optional(validate.IsOneOf("spread", "pack")),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
 	//
@@ -166,14 +203,21 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	"limits.cpu": validate.Optional(validate.IsValidCPUSet),
 
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.cpu.nodes)
-	// A comma-separated list of NUMA node IDs or ranges to place the instance CPUs on.
+	// A comma-separated list of NUMA node IDs or ranges to place the instance CPUs on,
+	// or `balanced` to let Incus pick the least used NUMA nodes when the instance starts.
 	//
-	// See {ref}`instance-options-limits-cpu-container` for more information.
+	// See {ref}`instance-options-limits-cpu-numa` for more information.
 	// ---
 	//  type: string
 	//  liveupdate: yes
 	//  shortdesc: Which NUMA nodes to place the instance CPUs on
-	"limits.cpu.nodes": validate.Optional(validate.IsValidCPUSet),
+	"limits.cpu.nodes": validate.Optional(func(value string) error {
+		if value == "balanced" {
+			return nil
+		}
+
+		return validate.IsValidCPUSet(value)
+	}),
 
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.disk.priority)
 	// Controls how much priority to give to the instance's I/O requests when under load.
@@ -315,6 +359,14 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: Template hook
 	"volatile.apply_template": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.apply_state)
+	// The runtime state included in the image the container was created from is resumed upon first startup.
+	// ---
+	//  type: bool
+	//  condition: container
+	//  shortdesc: Whether to resume the image runtime state
+	"volatile.apply_state": validate.Optional(validate.IsBool),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.base_image)
 	// The hash of the image that the instance was created from (empty if the instance was not created from an image).
 	// ---
@@ -329,6 +381,20 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: `instance-id` (UUID) exposed to `cloud-init`
 	"volatile.cloud-init.instance-id": validate.Optional(validate.IsUUID),
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.cpu.nodes)
+	// The NUMA nodes selected for the instance when {config:option}`instance-resource-limits:limits.cpu.nodes` is set.
+	// ---
+	//  type: string
+	//  shortdesc: NUMA nodes the running instance is placed on
+	"volatile.cpu.nodes": validate.Optional(validate.IsValidCPUSet),
+
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.cpu.set)
+	// The CPUs the vCPUs of the virtual machine are pinned to when {config:option}`instance-resource-limits:limits.cpu.nodes` is set.
+	// ---
+	//  type: string
+	//  shortdesc: CPUs the running instance is pinned to
+	"volatile.cpu.set": validate.Optional(validate.IsValidCPUSet),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.evacuate.origin)
 	// The cluster member that the instance lived on before evacuation.
 	// ---
@@ -336,6 +402,13 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: The origin of the evacuated instance
 	"volatile.evacuate.origin": validate.IsAny,
 
+	// gendoc:generate(entity=instance, group=volatile, key=volatile.rebalance.last_move)
+	// The time (as a UNIX timestamp) at which the instance was last moved by automatic cluster rebalancing.
+	// ---
+	//  type: integer
+	//  shortdesc: When the instance was last moved by rebalancing
+	"volatile.rebalance.last_move": validate.Optional(validate.IsInt64),
+
 	// gendoc:generate(entity=instance, group=volatile, key=volatile.last_state.power)
 	//
 	// ---
@@ -850,6 +923,25 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 
 // InstanceConfigKeysVM is a map of config key to validator. (keys applying to VM only).
 var InstanceConfigKeysVM = map[string]func(value string) error{
+	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.hotplug)
+	// Set this option to `true` to allow memory hotplug up to the host's total memory, or to a fixed value in bytes to set the maximum memory size of the VM.
+	// When enabled, increasing {config:option}`instance-resource-limits:limits.memory` while the VM is running adds memory to it.
+	// The agent brings the added memory online in the guest.
+	// ---
+	//  type: string
+	//  defaultdesc: `false`
+	//  liveupdate: no
+	//  condition: virtual machine
+	//  shortdesc: Whether to allow adding memory to the running VM, or the maximum memory size
+	"limits.memory.hotplug": validate.Optional(func(value string) error {
+		if validate.IsBool(value) == nil {
+			return nil
+		}
+
+		_, err := units.ParseByteSizeString(value)
+		return err
+	}),
+
 	// gendoc:generate(entity=instance, group=resource-limits, key=limits.memory.hugepages)
 	// If this option is set to `false`, regular system memory is used.
 	// ---
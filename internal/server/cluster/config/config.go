@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/lxc/incus/internal/server/config"
 	"github.com/lxc/incus/internal/server/db"
 	scriptletLoad "github.com/lxc/incus/internal/server/scriptlet/load"
+	"github.com/lxc/incus/shared/util"
 	"github.com/lxc/incus/shared/validate"
 )
 
@@ -54,6 +56,14 @@ func (c *Config) BGPASN() int64 {
 	return c.m.GetInt64("core.bgp_asn")
 }
 
+// DNSResolver returns whether the DNS server answers regular queries, the upstream servers and the allowed client subnets.
+func (c *Config) DNSResolver() (bool, []string, []string) {
+	forwarders := util.SplitNTrimSpace(c.m.GetString("core.dns_forwarders"), ",", -1, true)
+	subnets := util.SplitNTrimSpace(c.m.GetString("core.dns_resolver_subnets"), ",", -1, true)
+
+	return c.m.GetBool("core.dns_resolver"), forwarders, subnets
+}
+
 // HTTPSAllowedHeaders returns the relevant CORS setting.
 func (c *Config) HTTPSAllowedHeaders() string {
 	return c.m.GetString("core.https_allowed_headers")
@@ -177,6 +187,16 @@ func (c *Config) InstancesPlacementScriptlet() string {
 	return c.m.GetString("instances.placement.scriptlet")
 }
 
+// InstancesValidationScriptlet returns the instances validation scriptlet source code.
+func (c *Config) InstancesValidationScriptlet() string {
+	return c.m.GetString("instances.validation.scriptlet")
+}
+
+// ProjectsValidationScriptlet returns the projects validation scriptlet source code.
+func (c *Config) ProjectsValidationScriptlet() string {
+	return c.m.GetString("projects.validation.scriptlet")
+}
+
 // LokiServer returns all the Loki settings needed to connect to a server.
 func (c *Config) LokiServer() (string, string, string, string, []string, string, []string) {
 	var types []string
@@ -233,6 +253,29 @@ func (c *Config) ClusterHealingThreshold() time.Duration {
 	return healingThreshold
 }
 
+// ClusterRebalanceInterval returns how often automatic rebalancing of the cluster is considered.
+// If this feature is disabled, it returns 0.
+func (c *Config) ClusterRebalanceInterval() time.Duration {
+	n := c.m.GetInt64("cluster.rebalance.interval")
+	return time.Duration(n) * time.Minute
+}
+
+// ClusterRebalanceThreshold returns the load difference (in percent) between the busiest and the least busy
+// cluster members needed to move instances.
+func (c *Config) ClusterRebalanceThreshold() int64 {
+	return c.m.GetInt64("cluster.rebalance.threshold")
+}
+
+// ClusterRebalanceBatch returns the maximum number of instances to move during one rebalancing run.
+func (c *Config) ClusterRebalanceBatch() int64 {
+	return c.m.GetInt64("cluster.rebalance.batch")
+}
+
+// ClusterRebalanceCooldown returns the amount of time during which a moved instance isn't moved again.
+func (c *Config) ClusterRebalanceCooldown() string {
+	return c.m.GetString("cluster.rebalance.cooldown")
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]string {
@@ -346,6 +389,20 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Threshold when to evacuate an offline cluster member
 	"cluster.healing_threshold": {Type: config.Int64, Default: "0"},
 
+	// gendoc:generate(entity=server, group=cluster, key=cluster.healing_fence_command)
+	// Path to an executable that is run on the cluster leader before the instances of an offline cluster member
+	// are restarted elsewhere. It's called with the name and address of the member as arguments and must
+	// make sure that the member is powered off (for example, through its BMC). The executable must exist on all
+	// cluster members. If the command fails, the member isn't healed and fencing is retried with an increasing
+	// delay, up to five times while the member stays offline.
+	//
+	// See {ref}`cluster-automatic-evacuation` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Command used to fence an offline cluster member before healing
+	"cluster.healing_fence_command": {Validator: validate.Optional(fenceCommandValidator)},
+
 	// gendoc:generate(entity=server, group=cluster, key=cluster.join_token_expiry)
 	//
 	// ---
@@ -375,6 +432,44 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Number of database stand-by members
 	"cluster.max_standby": {Type: config.Int64, Default: "2", Validator: maxStandByValidator},
 
+	// gendoc:generate(entity=server, group=cluster, key=cluster.rebalance.batch)
+	// Specify the maximum number of instances that are moved during one automatic rebalancing run.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `1`
+	//  shortdesc: Maximum number of instances to move during one rebalancing run
+	"cluster.rebalance.batch": {Type: config.Int64, Default: "1", Validator: validate.IsInRange(1, 1000)},
+
+	// gendoc:generate(entity=server, group=cluster, key=cluster.rebalance.cooldown)
+	// Specify the amount of time during which an instance that was moved by automatic rebalancing isn't moved again.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `6H`
+	//  shortdesc: Time during which a moved instance isn't moved again
+	"cluster.rebalance.cooldown": {Type: config.String, Default: "6H", Validator: expiryValidator},
+
+	// gendoc:generate(entity=server, group=cluster, key=cluster.rebalance.interval)
+	// Specify how often (in minutes) to consider moving instances between cluster members to balance their load.
+	// To disable automatic rebalancing, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: How often to consider rebalancing the cluster
+	"cluster.rebalance.interval": {Type: config.Int64, Default: "0", Validator: validate.IsInRange(0, 10080)},
+
+	// gendoc:generate(entity=server, group=cluster, key=cluster.rebalance.threshold)
+	// Specify the difference in load (in percent) between the busiest and the least busy cluster members
+	// needed to move instances.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `20`
+	//  shortdesc: Load difference between cluster members that triggers rebalancing
+	"cluster.rebalance.threshold": {Type: config.Int64, Default: "20", Validator: validate.IsInRange(1, 100)},
+
 	// gendoc:generate(entity=server, group=core, key=core.metrics_authentication)
 	//
 	// ---
@@ -392,6 +487,34 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: BGP Autonomous System Number for the local server
 	"core.bgp_asn": {Type: config.Int64, Default: "0", Validator: validate.Optional(validate.IsInRange(0, 4294967294))},
 
+	// gendoc:generate(entity=server, group=core, key=core.dns_forwarders)
+	// Specify a comma-separated list of DNS servers, optionally with a port.
+	// If this option is not specified, the servers from `/etc/resolv.conf` are used.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Upstream DNS servers to forward queries to
+	"core.dns_forwarders": {Validator: validate.Optional(validate.IsListOf(validate.IsListenAddress(false, false, false)))},
+
+	// gendoc:generate(entity=server, group=core, key=core.dns_resolver)
+	// See {ref}`network-dns-resolver`.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether the DNS server answers regular queries and forwards them
+	"core.dns_resolver": {Type: config.Bool, Default: "false"},
+
+	// gendoc:generate(entity=server, group=core, key=core.dns_resolver_subnets)
+	// Specify a comma-separated list of subnets in CIDR notation.
+	// The resolver only answers clients within those subnets, so it can't be used until this option is set.
+	// Those clients can resolve the names of all the hosted zones.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Client subnets allowed to use the DNS resolver
+	"core.dns_resolver_subnets": {Validator: validate.Optional(validate.IsListOf(validate.IsNetwork))},
+
 	// gendoc:generate(entity=server, group=core, key=core.https_allowed_headers)
 	//
 	// ---
@@ -549,6 +672,24 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Instance placement scriptlet for automatic instance placement
 	"instances.placement.scriptlet": {Validator: validate.Optional(scriptletLoad.InstancePlacementValidate)},
 
+	// gendoc:generate(entity=server, group=miscellaneous, key=instances.validation.scriptlet)
+	// When using custom validation logic for instance creation and updates, this option stores the scriptlet.
+	// See {ref}`instances-validation-scriptlet` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Instance validation scriptlet for instance creation and updates
+	"instances.validation.scriptlet": {Validator: validate.Optional(scriptletLoad.InstanceValidationValidate)},
+
+	// gendoc:generate(entity=server, group=miscellaneous, key=projects.validation.scriptlet)
+	// When using custom validation logic for project creation and updates, this option stores the scriptlet.
+	// See {ref}`projects-validation-scriptlet` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Project validation scriptlet for project creation and updates
+	"projects.validation.scriptlet": {Validator: validate.Optional(scriptletLoad.ProjectValidationValidate)},
+
 	// gendoc:generate(entity=server, group=loki, key=loki.auth.username)
 	//
 	// ---
@@ -600,13 +741,13 @@ var ConfigSchema = config.Schema{
 
 	// gendoc:generate(entity=server, group=loki, key=loki.types)
 	// Specify a comma-separated list of events to send to the Loki server.
-	// The events can be any combination of `lifecycle`, `logging`, and `network-acl`.
+	// The events can be any combination of `lifecycle`, `logging`, `network-acl`, and `network-flow`.
 	// ---
 	//  type: string
 	//  scope: global
 	//  defaultdesc: `lifecycle,logging`
 	//  shortdesc: Events to send to the Loki server
-	"loki.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "logging", "network-acl"))), Default: "lifecycle,logging"},
+	"loki.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "logging", "network-acl", "network-flow"))), Default: "lifecycle,logging"},
 
 	// gendoc:generate(entity=server, group=oidc, key=oidc.client.id)
 	//
@@ -675,6 +816,24 @@ func logLevelValidator(value string) error {
 	return nil
 }
 
+func fenceCommandValidator(value string) error {
+	err := validate.IsAbsFilePath(value)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(value)
+	if err != nil {
+		return fmt.Errorf("Failed to access fence command: %w", err)
+	}
+
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("Fence command %q isn't an executable file", value)
+	}
+
+	return nil
+}
+
 func offlineThresholdDefault() string {
 	return strconv.Itoa(db.DefaultOfflineThreshold)
 }
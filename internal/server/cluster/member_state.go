@@ -99,5 +99,42 @@ func MemberState(ctx context.Context, s *state.State, memberName string) (*api.C
 		}
 	}
 
+	memberState.Healing, err = MemberHealing(ctx, s, memberName)
+	if err != nil {
+		return nil, err
+	}
+
 	return &memberState, nil
 }
+
+// MemberHealing retrieves the actions taken while healing the cluster member.
+func MemberHealing(ctx context.Context, s *state.State, memberName string) ([]api.ClusterMemberHealingAction, error) {
+	var actions []db.NodeHealingAction
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		member, err := tx.GetNodeByName(ctx, memberName)
+		if err != nil {
+			return err
+		}
+
+		actions, err = tx.GetNodeHealingActions(ctx, member.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading healing actions: %w", err)
+	}
+
+	healing := make([]api.ClusterMemberHealingAction, 0, len(actions))
+	for _, action := range actions {
+		healing = append(healing, api.ClusterMemberHealingAction{
+			Date:     action.Date,
+			Action:   action.Action,
+			Project:  action.Project,
+			Instance: action.Instance,
+			Target:   action.Target,
+			Message:  action.Message,
+		})
+	}
+
+	return healing, nil
+}
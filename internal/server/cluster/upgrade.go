@@ -209,3 +209,29 @@ func UpgradeMembersWithoutRole(gateway *Gateway, members []db.NodeInfo) error {
 
 	return nil
 }
+
+// UpgradeBlockers returns the reasons preventing a rolling upgrade of the given cluster members from starting.
+// An empty list means that the upgrade can proceed.
+func UpgradeBlockers(members []db.NodeInfo, offlineThreshold time.Duration) []string {
+	blockers := []string{}
+
+	if len(members) < 2 {
+		blockers = append(blockers, "Rolling upgrades require at least two cluster members")
+	}
+
+	for _, member := range members {
+		if member.IsOffline(offlineThreshold) {
+			blockers = append(blockers, fmt.Sprintf("Cluster member %q is offline", member.Name))
+		}
+
+		if member.State == db.ClusterMemberStateEvacuated {
+			blockers = append(blockers, fmt.Sprintf("Cluster member %q is evacuated", member.Name))
+		}
+
+		if member.Version() != members[0].Version() {
+			blockers = append(blockers, fmt.Sprintf("Cluster member %q has a different schema or API version", member.Name))
+		}
+	}
+
+	return blockers
+}
@@ -202,3 +202,50 @@ func TestUpgradeMembersWithoutRole(t *testing.T) {
 	assert.Equal(t, uint64(3), nodes[2].ID)
 	assert.Equal(t, "5.6.7.8", nodes[2].Address)
 }
+
+func TestUpgradeBlockers(t *testing.T) {
+	now := time.Now()
+	threshold := 20 * time.Second
+
+	member := func(name string, heartbeat time.Time, state int, schema int) db.NodeInfo {
+		return db.NodeInfo{Name: name, Heartbeat: heartbeat, State: state, Schema: schema, APIExtensions: 10}
+	}
+
+	tests := []struct {
+		name     string
+		members  []db.NodeInfo
+		blockers []string
+	}{
+		{
+			name:     "Healthy cluster",
+			members:  []db.NodeInfo{member("n1", now, db.ClusterMemberStateCreated, 1), member("n2", now, db.ClusterMemberStateCreated, 1)},
+			blockers: []string{},
+		},
+		{
+			name:     "Single member",
+			members:  []db.NodeInfo{member("n1", now, db.ClusterMemberStateCreated, 1)},
+			blockers: []string{"Rolling upgrades require at least two cluster members"},
+		},
+		{
+			name:     "Offline member",
+			members:  []db.NodeInfo{member("n1", now, db.ClusterMemberStateCreated, 1), member("n2", now.Add(-time.Minute), db.ClusterMemberStateCreated, 1)},
+			blockers: []string{`Cluster member "n2" is offline`},
+		},
+		{
+			name:     "Evacuated member",
+			members:  []db.NodeInfo{member("n1", now, db.ClusterMemberStateEvacuated, 1), member("n2", now, db.ClusterMemberStateCreated, 1)},
+			blockers: []string{`Cluster member "n1" is evacuated`},
+		},
+		{
+			name:     "Mismatched versions",
+			members:  []db.NodeInfo{member("n1", now, db.ClusterMemberStateCreated, 1), member("n2", now, db.ClusterMemberStateCreated, 2)},
+			blockers: []string{`Cluster member "n2" has a different schema or API version`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.blockers, cluster.UpgradeBlockers(test.members, threshold))
+		})
+	}
+}
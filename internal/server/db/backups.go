@@ -196,6 +196,16 @@ func (c *Cluster) CreateInstanceBackup(args InstanceBackup) error {
 	return err
 }
 
+// UpdateInstanceBackupSize sets the size in bytes of the instance backup with the given name.
+func (c *ClusterTx) UpdateInstanceBackupSize(ctx context.Context, name string, size int64) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE instances_backups SET size = ? WHERE name = ?", size, name)
+	if err != nil {
+		return fmt.Errorf("Failed updating instance backup size: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteInstanceBackup removes the instance backup with the given name from the database.
 func (c *Cluster) DeleteInstanceBackup(name string) error {
 	id, err := c.getInstanceBackupID(name)
@@ -430,6 +440,16 @@ func (c *Cluster) CreateStoragePoolVolumeBackup(args StoragePoolVolumeBackup) er
 	return err
 }
 
+// UpdateStoragePoolVolumeBackupSize sets the size in bytes of the storage volume backup with the given name.
+func (c *ClusterTx) UpdateStoragePoolVolumeBackupSize(ctx context.Context, name string, size int64) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE storage_volumes_backups SET size = ? WHERE name = ?", size, name)
+	if err != nil {
+		return fmt.Errorf("Failed updating storage volume backup size: %w", err)
+	}
+
+	return nil
+}
+
 // Returns the ID of the storage volume backup with the given name.
 func (c *Cluster) getStoragePoolVolumeBackupID(name string) (int, error) {
 	q := "SELECT id FROM storage_volumes_backups WHERE name=?"
@@ -121,6 +121,7 @@ CREATE TABLE "instances_backups" (
     expiry_date DATETIME,
     container_only INTEGER NOT NULL default 0,
     optimized_storage INTEGER NOT NULL default 0,
+    size INTEGER NOT NULL DEFAULT 0,
     FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE,
     UNIQUE (instance_id, name)
 );
@@ -246,6 +247,7 @@ CREATE TABLE "networks_forwards" (
 	listen_address TEXT NOT NULL,
 	description TEXT NOT NULL,
 	ports TEXT NOT NULL,
+    project_id INTEGER REFERENCES projects (id) ON DELETE SET NULL,
 	UNIQUE (network_id, node_id, listen_address),
 	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
 	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
@@ -266,6 +268,7 @@ CREATE TABLE "networks_load_balancers" (
 	description TEXT NOT NULL,
 	backends TEXT NOT NULL,
 	ports TEXT NOT NULL,
+    project_id INTEGER REFERENCES projects (id) ON DELETE SET NULL,
 	UNIQUE (network_id, node_id, listen_address),
 	FOREIGN KEY (network_id) REFERENCES "networks" (id) ON DELETE CASCADE,
 	FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE
@@ -325,6 +328,17 @@ CREATE TABLE "networks_zones_config" (
 	UNIQUE (network_zone_id, key),
 	FOREIGN KEY (network_zone_id) REFERENCES "networks_zones" (id) ON DELETE CASCADE
 );
+CREATE TABLE networks_zones_dnssec_keys (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_zone_id INTEGER NOT NULL,
+	flags INTEGER NOT NULL,
+	algorithm INTEGER NOT NULL,
+	public_key TEXT NOT NULL,
+	private_key TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	retired_at DATETIME,
+	FOREIGN KEY (network_zone_id) REFERENCES networks_zones (id) ON DELETE CASCADE
+);
 CREATE TABLE "networks_zones_records" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	network_zone_id INTEGER NOT NULL,
@@ -377,12 +391,35 @@ CREATE TABLE nodes_failure_domains (
     name TEXT NOT NULL,
     UNIQUE (name)
 );
+CREATE TABLE nodes_healing (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	node_id INTEGER NOT NULL,
+	date DATETIME NOT NULL,
+	action TEXT NOT NULL,
+	project TEXT NOT NULL,
+	instance TEXT NOT NULL,
+	target TEXT NOT NULL,
+	message TEXT NOT NULL,
+	FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
 CREATE TABLE "nodes_roles" (
     node_id INTEGER NOT NULL,
     role INTEGER NOT NULL,
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
     UNIQUE (node_id, role)
 );
+CREATE TABLE nodes_upgrade (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	node_id INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	state TEXT NOT NULL,
+	error TEXT NOT NULL,
+	date DATETIME NOT NULL,
+	version TEXT NOT NULL DEFAULT '',
+	offline INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (node_id),
+	FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
 CREATE TABLE "operations" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     uuid TEXT NOT NULL,
@@ -440,6 +477,26 @@ CREATE TABLE "projects_config" (
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
     UNIQUE (project_id, key)
 );
+CREATE TABLE projects_usage (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	duration INTEGER NOT NULL,
+	containers INTEGER NOT NULL,
+	virtual_machines INTEGER NOT NULL,
+	cpu INTEGER NOT NULL,
+	disk INTEGER NOT NULL,
+	FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+CREATE INDEX projects_usage_project_id_timestamp ON projects_usage (project_id,
+    timestamp);
+CREATE TABLE scriptlets_data (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	scriptlet TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value TEXT NOT NULL,
+	UNIQUE (scriptlet, key)
+);
 CREATE TABLE "storage_buckets" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	name TEXT NOT NULL,
@@ -556,6 +613,7 @@ CREATE TABLE "storage_volumes_backups" (
     expiry_date DATETIME,
     volume_only INTEGER NOT NULL default 0,
     optimized_storage INTEGER NOT NULL default 0,
+    size INTEGER NOT NULL DEFAULT 0,
     FOREIGN KEY (storage_volume_id) REFERENCES "storage_volumes" (id) ON DELETE CASCADE,
     UNIQUE (storage_volume_id, name)
 );
@@ -601,6 +659,14 @@ CREATE TABLE "storage_volumes_snapshots_config" (
     UNIQUE (storage_volume_snapshot_id, key)
 );
 CREATE UNIQUE INDEX storage_volumes_unique_storage_pool_id_node_id_project_id_name_type ON "storage_volumes" (storage_pool_id, IFNULL(node_id, -1), project_id, name, type);
+CREATE TABLE storage_volumes_usage (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	storage_volume_id INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	used INTEGER NOT NULL,
+	UNIQUE (storage_volume_id),
+	FOREIGN KEY (storage_volume_id) REFERENCES storage_volumes (id) ON DELETE CASCADE
+);
 CREATE TABLE "warnings" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	node_id INTEGER,
@@ -621,5 +687,5 @@ CREATE TABLE "warnings" (
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
 
-INSERT INTO schema (version, updated_at) VALUES (69, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (77, strftime("%s"))
 `
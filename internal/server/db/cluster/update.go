@@ -106,6 +106,245 @@ var updates = map[int]schema.Update{
 	67: updateFromV66,
 	68: updateFromV67,
 	69: updateFromV68,
+	70: updateFromV69,
+	71: updateFromV70,
+	72: updateFromV71,
+	73: updateFromV72,
+	74: updateFromV73,
+	75: updateFromV74,
+	76: updateFromV75,
+	77: updateFromV76,
+}
+
+// updateFromV76 adds the project_id column to networks_forwards and networks_load_balancers, recording the project
+// which created them on networks shared with other projects. A NULL value means the project of the network.
+func updateFromV76(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+ALTER TABLE networks_forwards ADD COLUMN project_id INTEGER REFERENCES projects (id) ON DELETE SET NULL;
+ALTER TABLE networks_load_balancers ADD COLUMN project_id INTEGER REFERENCES projects (id) ON DELETE SET NULL;
+`)
+	if err != nil {
+		return fmt.Errorf("Failed adding project_id column to network forward and load balancer tables: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV75 renames the cluster groups containing equal signs, which are now used in cluster member label
+// selectors, replacing them with dashes. The project restrictions referring to them are updated accordingly.
+func updateFromV75(ctx context.Context, tx *sql.Tx) error {
+	names, err := query.SelectStrings(ctx, tx, "SELECT name FROM cluster_groups")
+	if err != nil {
+		return fmt.Errorf("Failed getting cluster groups: %w", err)
+	}
+
+	existing := make(map[string]bool, len(names))
+	for _, name := range names {
+		existing[name] = true
+	}
+
+	renames := map[string]string{}
+	for _, name := range names {
+		if !strings.Contains(name, "=") {
+			continue
+		}
+
+		base := strings.ReplaceAll(name, "=", "-")
+		newName := base
+		for i := 1; existing[newName]; i++ {
+			newName = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		_, err = tx.Exec("UPDATE cluster_groups SET name = ? WHERE name = ?", newName, name)
+		if err != nil {
+			return fmt.Errorf("Failed renaming cluster group %q: %w", name, err)
+		}
+
+		existing[newName] = true
+		renames[name] = newName
+
+		logger.Warn("Renamed cluster group containing an equal sign", logger.Ctx{"name": name, "newName": newName})
+	}
+
+	if len(renames) == 0 {
+		return nil
+	}
+
+	type projectConfig struct {
+		id    int64
+		value string
+	}
+
+	var configs []projectConfig
+	err = query.Scan(ctx, tx, "SELECT id, value FROM projects_config WHERE key = 'restricted.cluster.groups'", func(scan func(dest ...any) error) error {
+		config := projectConfig{}
+
+		err := scan(&config.id, &config.value)
+		if err != nil {
+			return err
+		}
+
+		configs = append(configs, config)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed getting project cluster group restrictions: %w", err)
+	}
+
+	for _, config := range configs {
+		groups := strings.Split(config.value, ",")
+		changed := false
+		for i, group := range groups {
+			newName, ok := renames[strings.TrimSpace(group)]
+			if ok {
+				groups[i] = newName
+				changed = true
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		_, err = tx.Exec("UPDATE projects_config SET value = ? WHERE id = ?", strings.Join(groups, ","), config.id)
+		if err != nil {
+			return fmt.Errorf("Failed updating project cluster group restrictions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// updateFromV74 adds the size column to instances_backups and storage_volumes_backups.
+func updateFromV74(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+ALTER TABLE instances_backups ADD COLUMN size INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE storage_volumes_backups ADD COLUMN size INTEGER NOT NULL DEFAULT 0;
+`)
+	if err != nil {
+		return fmt.Errorf("Failed adding size column to backup tables: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV73 adds the nodes_upgrade table.
+func updateFromV73(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE nodes_upgrade (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	node_id INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	state TEXT NOT NULL,
+	error TEXT NOT NULL,
+	date DATETIME NOT NULL,
+	version TEXT NOT NULL DEFAULT '',
+	offline INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (node_id),
+	FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating nodes_upgrade table: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV72 adds the projects_usage and storage_volumes_usage tables.
+func updateFromV72(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE projects_usage (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	project_id INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	duration INTEGER NOT NULL,
+	containers INTEGER NOT NULL,
+	virtual_machines INTEGER NOT NULL,
+	cpu INTEGER NOT NULL,
+	disk INTEGER NOT NULL,
+	FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+
+CREATE INDEX projects_usage_project_id_timestamp ON projects_usage (project_id, timestamp);
+
+CREATE TABLE storage_volumes_usage (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	storage_volume_id INTEGER NOT NULL,
+	timestamp INTEGER NOT NULL,
+	used INTEGER NOT NULL,
+	UNIQUE (storage_volume_id),
+	FOREIGN KEY (storage_volume_id) REFERENCES storage_volumes (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating project usage tables: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV71 adds the nodes_healing table.
+func updateFromV71(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE nodes_healing (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	node_id INTEGER NOT NULL,
+	date DATETIME NOT NULL,
+	action TEXT NOT NULL,
+	project TEXT NOT NULL,
+	instance TEXT NOT NULL,
+	target TEXT NOT NULL,
+	message TEXT NOT NULL,
+	FOREIGN KEY (node_id) REFERENCES nodes (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating nodes_healing table: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV70 adds the scriptlets_data table.
+func updateFromV70(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE scriptlets_data (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	scriptlet TEXT NOT NULL,
+	key TEXT NOT NULL,
+	value TEXT NOT NULL,
+	UNIQUE (scriptlet, key)
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating scriptlets_data table: %w", err)
+	}
+
+	return nil
+}
+
+// updateFromV69 adds the networks_zones_dnssec_keys table.
+func updateFromV69(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.Exec(`
+CREATE TABLE networks_zones_dnssec_keys (
+	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+	network_zone_id INTEGER NOT NULL,
+	flags INTEGER NOT NULL,
+	algorithm INTEGER NOT NULL,
+	public_key TEXT NOT NULL,
+	private_key TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	retired_at DATETIME,
+	FOREIGN KEY (network_zone_id) REFERENCES networks_zones (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return fmt.Errorf("Failed creating networks_zones_dnssec_keys table: %w", err)
+	}
+
+	return nil
 }
 
 // updateFromV68 fixes unique index for record name to make it zone specific.
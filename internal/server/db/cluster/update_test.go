@@ -763,3 +763,31 @@ func TestUpdateFromV34(t *testing.T) {
 	assert.Equal(t, id, 2)
 	assert.Equal(t, nodeID, nil)
 }
+
+func TestUpdateFromV75(t *testing.T) {
+	schema := cluster.Schema()
+	db, err := schema.ExerciseUpdate(76, func(db *sql.DB) {
+		_, err := db.Exec(`
+INSERT INTO cluster_groups (name, description) VALUES ('rack=a1', ''), ('rack-a1', ''), ('gpu', '');
+INSERT INTO projects (name, description) VALUES ('p1', '');
+INSERT INTO projects_config (project_id, key, value) SELECT id, 'restricted.cluster.groups', 'gpu, rack=a1' FROM projects WHERE name = 'p1';
+`)
+		require.NoError(t, err)
+	})
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback() }()
+
+	// The group containing an equal sign is renamed without clashing with the existing ones.
+	names, err := query.SelectStrings(context.Background(), tx, "SELECT name FROM cluster_groups ORDER BY name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "gpu", "rack-a1", "rack-a1-1"}, names)
+
+	// The project restrictions use the new name.
+	values, err := query.SelectStrings(context.Background(), tx, "SELECT value FROM projects_config WHERE key = 'restricted.cluster.groups'")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpu,rack-a1-1"}, values)
+}
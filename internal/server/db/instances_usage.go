@@ -0,0 +1,90 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lxc/incus/internal/server/db/query"
+)
+
+// InstanceUsage is a resource usage sample of an instance.
+//
+// The samples are stored in the node database of the member running the instance.
+//
+// The CPU and network values are the cumulative counters reported by the instance at the time of the sample.
+type InstanceUsage struct {
+	InstanceID      int64
+	Timestamp       time.Time
+	CPUUsage        int64
+	MemoryUsage     int64
+	DiskUsage       int64
+	NetworkReceived int64
+	NetworkSent     int64
+}
+
+// CreateInstanceUsage records a resource usage sample of an instance.
+func (n *NodeTx) CreateInstanceUsage(ctx context.Context, usage InstanceUsage) error {
+	stmt := `
+INSERT INTO instances_usage (instance_id, timestamp, cpu_usage, memory_usage, disk_usage, network_received, network_sent)
+  VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+	_, err := n.tx.ExecContext(ctx, stmt, usage.InstanceID, usage.Timestamp.Unix(), usage.CPUUsage, usage.MemoryUsage, usage.DiskUsage, usage.NetworkReceived, usage.NetworkSent)
+	if err != nil {
+		return fmt.Errorf("Failed recording instance usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetInstanceUsage returns the resource usage samples of an instance taken since the given time, oldest first.
+func (n *NodeTx) GetInstanceUsage(ctx context.Context, instanceID int64, since time.Time) ([]InstanceUsage, error) {
+	samples := []InstanceUsage{}
+
+	sql := `
+SELECT timestamp, cpu_usage, memory_usage, disk_usage, network_received, network_sent
+  FROM instances_usage
+  WHERE instance_id = ? AND timestamp >= ?
+  ORDER BY timestamp
+`
+	err := query.Scan(ctx, n.tx, sql, func(scan func(dest ...any) error) error {
+		usage := InstanceUsage{InstanceID: instanceID}
+
+		var timestamp int64
+		err := scan(&timestamp, &usage.CPUUsage, &usage.MemoryUsage, &usage.DiskUsage, &usage.NetworkReceived, &usage.NetworkSent)
+		if err != nil {
+			return err
+		}
+
+		usage.Timestamp = time.Unix(timestamp, 0).UTC()
+		samples = append(samples, usage)
+
+		return nil
+	}, instanceID, since.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching instance usage: %w", err)
+	}
+
+	return samples, nil
+}
+
+// DeleteInstanceUsage removes the resource usage samples taken before the given time, as well as all the
+// samples of the instances which aren't in the given list, such as deleted instances or instances which moved
+// to another member.
+func (n *NodeTx) DeleteInstanceUsage(ctx context.Context, before time.Time, instanceIDs []int64) error {
+	args := make([]any, 0, len(instanceIDs)+1)
+	args = append(args, before.Unix())
+	for _, id := range instanceIDs {
+		args = append(args, id)
+	}
+
+	stmt := fmt.Sprintf("DELETE FROM instances_usage WHERE timestamp < ? OR instance_id NOT IN %s", query.Params(len(instanceIDs)))
+	_, err := n.tx.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("Failed deleting expired instance usage: %w", err)
+	}
+
+	return nil
+}
@@ -67,6 +67,17 @@ func (c *Cluster) CreateNetworkForward(networkID int64, memberSpecific bool, inf
 	return forwardID, err
 }
 
+// UpdateNetworkForwardProject records the project which created the network forward with the given listen address
+// on a network of another project.
+func (c *ClusterTx) UpdateNetworkForwardProject(ctx context.Context, networkID int64, listenAddress string, projectName string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE networks_forwards SET project_id = (SELECT id FROM projects WHERE name = ?) WHERE network_id = ? AND listen_address = ?", projectName, networkID, listenAddress)
+	if err != nil {
+		return fmt.Errorf("Failed recording network forward project: %w", err)
+	}
+
+	return nil
+}
+
 // networkForwardConfigAdd inserts Network forward config keys.
 func networkForwardConfigAdd(tx *sql.Tx, forwardID int64, config map[string]string) error {
 	stmt, err := tx.Prepare(`
@@ -74,6 +74,17 @@ func (c *Cluster) CreateNetworkLoadBalancer(networkID int64, memberSpecific bool
 	return loadBalancerID, err
 }
 
+// UpdateNetworkLoadBalancerProject records the project which created the network load balancer with the given listen address
+// on a network of another project.
+func (c *ClusterTx) UpdateNetworkLoadBalancerProject(ctx context.Context, networkID int64, listenAddress string, projectName string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE networks_load_balancers SET project_id = (SELECT id FROM projects WHERE name = ?) WHERE network_id = ? AND listen_address = ?", projectName, networkID, listenAddress)
+	if err != nil {
+		return fmt.Errorf("Failed recording network load balancer project: %w", err)
+	}
+
+	return nil
+}
+
 // networkLoadBalancerConfigAdd inserts Network Load Balancer config keys.
 func networkLoadBalancerConfigAdd(tx *sql.Tx, loadBalancerID int64, config map[string]string) error {
 	stmt, err := tx.Prepare(`
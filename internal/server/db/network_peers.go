@@ -27,7 +27,7 @@ func (c *Cluster) CreateNetworkPeer(networkID int64, info *api.NetworkPeersPost)
 		result, err := tx.tx.Exec(`
 		INSERT INTO networks_peers
 		(network_id, name, description, target_network_project, target_network_name)
-		VALUES (?, ?, ?, ?, ?)
+		VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))
 		`, networkID, info.Name, info.Description, info.TargetProject, info.TargetNetwork)
 		if err != nil {
 			return err
@@ -162,6 +162,7 @@ func (c *Cluster) GetNetworkPeer(networkID int64, peerName string) (int64, *api.
 		local_peer.description,
 		IFNULL(local_peer.target_network_project, ""),
 		IFNULL(local_peer.target_network_name, ""),
+		IFNULL(local_peer.target_network_id, -1),
 		IFNULL(target_peer_network.name, "") AS target_peer_network_name,
 		IFNULL(target_peer_project.name, "") AS target_peer_network_project
 	FROM networks_peers AS local_peer
@@ -179,11 +180,12 @@ func (c *Cluster) GetNetworkPeer(networkID int64, peerName string) (int64, *api.
 	var err error
 	var peerID int64 = int64(-1)
 	var peer api.NetworkPeer
+	var targetNetworkID int64
 	var targetPeerNetworkName string
 	var targetPeerNetworkProject string
 
 	err = c.Transaction(context.TODO(), func(ctx context.Context, tx *ClusterTx) error {
-		err = tx.tx.QueryRowContext(ctx, q, networkID, peerName).Scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject)
+		err = tx.tx.QueryRowContext(ctx, q, networkID, peerName).Scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetNetworkID, &targetPeerNetworkName, &targetPeerNetworkProject)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return api.StatusErrorf(http.StatusNotFound, "Network peer not found")
@@ -203,7 +205,7 @@ func (c *Cluster) GetNetworkPeer(networkID int64, peerName string) (int64, *api.
 		return -1, nil, err
 	}
 
-	networkPeerPopulatePeerInfo(&peer, targetPeerNetworkProject, targetPeerNetworkName)
+	networkPeerPopulatePeerInfo(&peer, targetNetworkID, targetPeerNetworkProject, targetPeerNetworkName)
 
 	return peerID, &peer, nil
 }
@@ -211,7 +213,8 @@ func (c *Cluster) GetNetworkPeer(networkID int64, peerName string) (int64, *api.
 // networkPeerPopulatePeerInfo populates the supplied peer's Status, TargetProject and TargetNetwork fields.
 // It uses the state of the targetPeerNetworkProject and targetPeerNetworkName arguments to decide whether the
 // peering is mutually created and whether to use those values rather than the values contained in the peer.
-func networkPeerPopulatePeerInfo(peer *api.NetworkPeer, targetPeerNetworkProject string, targetPeerNetworkName string) {
+// A targetNetworkID of -1 indicates the peer has never been linked to a target network.
+func networkPeerPopulatePeerInfo(peer *api.NetworkPeer, targetNetworkID int64, targetPeerNetworkProject string, targetPeerNetworkName string) {
 	// Peer has mutual peering from target network.
 	if targetPeerNetworkName != "" && targetPeerNetworkProject != "" {
 		if peer.TargetNetwork != "" || peer.TargetProject != "" {
@@ -229,6 +232,9 @@ func networkPeerPopulatePeerInfo(peer *api.NetworkPeer, targetPeerNetworkProject
 		if peer.TargetNetwork != "" || peer.TargetProject != "" {
 			// Peer isn't linked to a mutual peer on the target network yet but has joining details.
 			peer.Status = api.NetworkStatusPending
+		} else if targetNetworkID < 0 {
+			// Peer doesn't use a target network (such as WireGuard peers) so is created as soon as it exists.
+			peer.Status = api.NetworkStatusCreated
 		} else {
 			// Peer isn't linked to a mutual peer on the target network yet and has no joining details.
 			// Perhaps it was formely joined (and had its joining details cleared) and subsequently
@@ -283,6 +289,7 @@ func (c *Cluster) GetNetworkPeers(networkID int64) (map[int64]*api.NetworkPeer,
 		local_peer.description,
 		IFNULL(local_peer.target_network_project, ""),
 		IFNULL(local_peer.target_network_name, ""),
+		IFNULL(local_peer.target_network_id, -1),
 		IFNULL(target_peer_network.name, "") AS target_peer_network_name,
 		IFNULL(target_peer_project.name, "") AS target_peer_network_project
 	FROM networks_peers AS local_peer
@@ -303,15 +310,16 @@ func (c *Cluster) GetNetworkPeers(networkID int64) (map[int64]*api.NetworkPeer,
 		err = query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
 			var peerID int64 = int64(-1)
 			var peer api.NetworkPeer
+			var targetNetworkID int64
 			var targetPeerNetworkName string
 			var targetPeerNetworkProject string
 
-			err := scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetPeerNetworkName, &targetPeerNetworkProject)
+			err := scan(&peerID, &peer.Name, &peer.Description, &peer.TargetProject, &peer.TargetNetwork, &targetNetworkID, &targetPeerNetworkName, &targetPeerNetworkProject)
 			if err != nil {
 				return err
 			}
 
-			networkPeerPopulatePeerInfo(&peer, targetPeerNetworkProject, targetPeerNetworkName)
+			networkPeerPopulatePeerInfo(&peer, targetNetworkID, targetPeerNetworkProject, targetPeerNetworkName)
 
 			peers[peerID] = &peer
 
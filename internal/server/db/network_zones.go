@@ -6,9 +6,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/lxc/incus/internal/server/db/query"
 	"github.com/lxc/incus/shared/api"
@@ -44,6 +46,33 @@ func (c *ClusterTx) GetNetworkZones(ctx context.Context) (map[string]string, err
 	return zoneProjects, nil
 }
 
+// GetNetworkZoneNameForCandidates returns the longest of the candidate names which is an existing Network zone,
+// or an empty string if none is.
+func (c *ClusterTx) GetNetworkZoneNameForCandidates(ctx context.Context, candidates []string) (string, error) {
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	q := fmt.Sprintf("SELECT name FROM networks_zones WHERE name IN %s ORDER BY length(name) DESC LIMIT 1", query.Params(len(candidates)))
+
+	args := make([]any, 0, len(candidates))
+	for _, candidate := range candidates {
+		args = append(args, candidate)
+	}
+
+	var name string
+	err := c.tx.QueryRowContext(ctx, q, args...).Scan(&name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	return name, nil
+}
+
 // GetNetworkZonesByProject returns the names of existing Network zones.
 func (c *Cluster) GetNetworkZonesByProject(project string) ([]string, error) {
 	q := `SELECT name FROM networks_zones
@@ -518,3 +547,74 @@ func (c *Cluster) DeleteNetworkZoneRecord(id int64) error {
 		return err
 	})
 }
+
+// NetworkZoneDNSSECKey represents a DNSSEC key of a network zone.
+type NetworkZoneDNSSECKey struct {
+	ID         int64
+	Flags      uint16
+	Algorithm  uint8
+	PublicKey  string
+	PrivateKey string
+	CreatedAt  time.Time
+
+	// Time at which the key was replaced, zero for keys that are still active.
+	RetiredAt time.Time
+}
+
+// GetNetworkZoneDNSSECKeys returns the DNSSEC keys of the network zone, oldest first.
+func (c *ClusterTx) GetNetworkZoneDNSSECKeys(ctx context.Context, zone int64) ([]NetworkZoneDNSSECKey, error) {
+	q := `SELECT id, flags, algorithm, public_key, private_key, created_at, retired_at
+		FROM networks_zones_dnssec_keys
+		WHERE network_zone_id=?
+		ORDER BY created_at, id
+	`
+
+	keys := []NetworkZoneDNSSECKey{}
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var key NetworkZoneDNSSECKey
+		var retiredAt sql.NullTime
+
+		err := scan(&key.ID, &key.Flags, &key.Algorithm, &key.PublicKey, &key.PrivateKey, &key.CreatedAt, &retiredAt)
+		if err != nil {
+			return err
+		}
+
+		if retiredAt.Valid {
+			key.RetiredAt = retiredAt.Time
+		}
+
+		keys = append(keys, key)
+
+		return nil
+	}, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// CreateNetworkZoneDNSSECKey adds a new DNSSEC key to the network zone.
+func (c *ClusterTx) CreateNetworkZoneDNSSECKey(ctx context.Context, zone int64, key NetworkZoneDNSSECKey) (int64, error) {
+	result, err := c.tx.ExecContext(ctx, `
+		INSERT INTO networks_zones_dnssec_keys (network_zone_id, flags, algorithm, public_key, private_key, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, zone, key.Flags, key.Algorithm, key.PublicKey, key.PrivateKey, key.CreatedAt)
+	if err != nil {
+		return -1, err
+	}
+
+	return result.LastInsertId()
+}
+
+// RetireNetworkZoneDNSSECKey marks the DNSSEC key as replaced.
+func (c *ClusterTx) RetireNetworkZoneDNSSECKey(ctx context.Context, id int64, retiredAt time.Time) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE networks_zones_dnssec_keys SET retired_at=? WHERE id=?", retiredAt, id)
+	return err
+}
+
+// DeleteNetworkZoneDNSSECKey deletes the DNSSEC key.
+func (c *ClusterTx) DeleteNetworkZoneDNSSECKey(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_zones_dnssec_keys WHERE id=?", id)
+	return err
+}
@@ -543,11 +543,12 @@ type NetworkType int
 
 // Network types.
 const (
-	NetworkTypeBridge   NetworkType = iota // Network type bridge.
-	NetworkTypeMacvlan                     // Network type macvlan.
-	NetworkTypeSriov                       // Network type sriov.
-	NetworkTypeOVN                         // Network type ovn.
-	NetworkTypePhysical                    // Network type physical.
+	NetworkTypeBridge    NetworkType = iota // Network type bridge.
+	NetworkTypeMacvlan                      // Network type macvlan.
+	NetworkTypeSriov                        // Network type sriov.
+	NetworkTypeOVN                          // Network type ovn.
+	NetworkTypePhysical                     // Network type physical.
+	NetworkTypeWireguard                    // Network type wireguard.
 )
 
 // NetworkNode represents a network node.
@@ -689,6 +690,8 @@ func networkFillType(network *api.Network, netType NetworkType) {
 		network.Type = "ovn"
 	case NetworkTypePhysical:
 		network.Type = "physical"
+	case NetworkTypeWireguard:
+		network.Type = "wireguard"
 	default:
 		network.Type = "" // Unknown
 	}
@@ -910,5 +913,10 @@ var NodeSpecificNetworkConfig = []string{
 	"bgp.ipv4.nexthop",
 	"bgp.ipv6.nexthop",
 	"bridge.external_interfaces",
+	"ipv6.prefix_delegation.interface",
 	"parent",
+	"volatile.ipv6.prefix_delegation.expiry",
+	"volatile.ipv6.prefix_delegation.prefix",
+	"volatile.ipv6.prefix_delegation.renew",
+	"volatile.ipv6.prefix_delegation.server",
 }
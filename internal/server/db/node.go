@@ -78,6 +78,44 @@ func (n NodeInfo) IsOffline(threshold time.Duration) bool {
 	return nodeIsOffline(threshold, n.Heartbeat)
 }
 
+// ClusterMemberLabelPrefix is the prefix of the member configuration keys holding its labels.
+const ClusterMemberLabelPrefix = "label."
+
+// Labels returns the labels of the node, keyed by label name.
+func (n NodeInfo) Labels() map[string]string {
+	labels := map[string]string{}
+	for k, v := range n.Config {
+		if strings.HasPrefix(k, ClusterMemberLabelPrefix) {
+			labels[strings.TrimPrefix(k, ClusterMemberLabelPrefix)] = v
+		}
+	}
+
+	return labels
+}
+
+// InClusterGroup returns true if the node is part of the given cluster group.
+// Groups of the form <label>=<value> are dynamic and match the nodes with that label value.
+func (n NodeInfo) InClusterGroup(group string) bool {
+	label, value, ok := ParseClusterGroupSelector(group)
+	if ok {
+		labelValue, found := n.Config[ClusterMemberLabelPrefix+label]
+		return found && labelValue == value
+	}
+
+	return util.ValueInSlice(group, n.Groups)
+}
+
+// ParseClusterGroupSelector returns the label name and value of a dynamic cluster group of the
+// form <label>=<value>. The last return value is false if the group isn't a label selector.
+func ParseClusterGroupSelector(group string) (string, string, bool) {
+	label, value, found := strings.Cut(group, "=")
+	if !found || label == "" {
+		return "", "", false
+	}
+
+	return label, value, true
+}
+
 // NodeInfoArgs provides information about the cluster environment for use with NodeInfo.ToAPI().
 type NodeInfoArgs struct {
 	LeaderAddress        string
@@ -1064,12 +1102,12 @@ func (c *ClusterTx) GetCandidateMembers(ctx context.Context, allMembers []NodeIn
 		}
 
 		// Skip group-only members if targeted cluster group doesn't match.
-		if member.Config["scheduler.instance"] == "group" && !util.ValueInSlice(targetClusterGroup, member.Groups) {
+		if member.Config["scheduler.instance"] == "group" && !member.InClusterGroup(targetClusterGroup) {
 			continue
 		}
 
 		// Skip if a group is requested and member isn't part of it.
-		if targetClusterGroup != "" && !util.ValueInSlice(targetClusterGroup, member.Groups) {
+		if targetClusterGroup != "" && !member.InClusterGroup(targetClusterGroup) {
 			continue
 		}
 
@@ -1077,7 +1115,7 @@ func (c *ClusterTx) GetCandidateMembers(ctx context.Context, allMembers []NodeIn
 		if allowedClusterGroups != nil {
 			found := false
 			for _, allowedClusterGroup := range allowedClusterGroups {
-				if util.ValueInSlice(allowedClusterGroup, member.Groups) {
+				if member.InClusterGroup(allowedClusterGroup) {
 					found = true
 					break
 				}
@@ -1145,6 +1183,55 @@ func (c *ClusterTx) GetNodeWithLeastInstances(ctx context.Context, members []Nod
 	return member, nil
 }
 
+// GetNodeForPlacementGroup returns the member to place an instance of the given placement group on.
+// With the "spread" policy, members running the fewest instances of the group in the project are preferred.
+// With the "pack" policy, members running the most instances of the group in the project are preferred.
+// Ties are broken by picking the member with the least number of instances.
+// If group is empty, this is equivalent to GetNodeWithLeastInstances.
+func (c *ClusterTx) GetNodeForPlacementGroup(ctx context.Context, members []NodeInfo, project string, group string, policy string) (*NodeInfo, error) {
+	if group == "" || len(members) == 0 {
+		return c.GetNodeWithLeastInstances(ctx, members)
+	}
+
+	instances, err := cluster.GetInstances(ctx, c.tx, cluster.InstanceFilter{Project: &project})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instances: %w", err)
+	}
+
+	instanceArgs, err := c.InstancesToInstanceArgs(ctx, true, instances...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the instances of the placement group on each member.
+	groupCounts := make(map[string]int, len(members))
+	for _, inst := range instanceArgs {
+		if ExpandInstanceConfig(inst.Config, inst.Profiles)["placement.group"] == group {
+			groupCounts[inst.Node]++
+		}
+	}
+
+	var preferred []NodeInfo
+	bestCount := -1
+	for _, member := range members {
+		count := groupCounts[member.Name]
+
+		better := bestCount == -1 || count < bestCount
+		if policy == "pack" {
+			better = count > bestCount
+		}
+
+		if better {
+			bestCount = count
+			preferred = []NodeInfo{member}
+		} else if count == bestCount {
+			preferred = append(preferred, member)
+		}
+	}
+
+	return c.GetNodeWithLeastInstances(ctx, preferred)
+}
+
 // SetNodeVersion updates the schema and API version of the node with the
 // given id. This is used only in tests.
 func (c *ClusterTx) SetNodeVersion(id int64, version [2]int) error {
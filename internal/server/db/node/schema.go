@@ -20,6 +20,18 @@ CREATE TABLE "config" (
     value TEXT NOT NULL,
     UNIQUE (key)
 );
+CREATE TABLE instances_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_id INTEGER NOT NULL,
+    timestamp INTEGER NOT NULL,
+    cpu_usage INTEGER NOT NULL DEFAULT 0,
+    memory_usage INTEGER NOT NULL DEFAULT 0,
+    disk_usage INTEGER NOT NULL DEFAULT 0,
+    network_received INTEGER NOT NULL DEFAULT 0,
+    network_sent INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX instances_usage_instance_id_timestamp ON instances_usage (instance_id,
+    timestamp);
 CREATE TABLE patches (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name VARCHAR(255) NOT NULL,
@@ -34,5 +46,5 @@ CREATE TABLE raft_nodes (
     UNIQUE (address)
 );
 
-INSERT INTO schema (version, updated_at) VALUES (43, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (44, strftime("%s"))
 `
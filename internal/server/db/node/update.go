@@ -100,6 +100,7 @@ var updates = map[int]schema.Update{
 	41: updateFromV40,
 	42: updateFromV41,
 	43: updateFromV42,
+	44: updateFromV43,
 }
 
 // UpdateFromPreClustering is the last schema version where clustering support
@@ -108,6 +109,25 @@ const UpdateFromPreClustering = 36
 
 // Schema updates begin here
 
+// updateFromV43 adds the instances_usage table holding the usage samples of the local instances.
+func updateFromV43(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE instances_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    instance_id INTEGER NOT NULL,
+    timestamp INTEGER NOT NULL,
+    cpu_usage INTEGER NOT NULL DEFAULT 0,
+    memory_usage INTEGER NOT NULL DEFAULT 0,
+    disk_usage INTEGER NOT NULL DEFAULT 0,
+    network_received INTEGER NOT NULL DEFAULT 0,
+    network_sent INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX instances_usage_instance_id_timestamp ON instances_usage (instance_id, timestamp);
+`
+	_, err := tx.Exec(stmt)
+	return err
+}
+
 // updateFromV42 ensures key and value fields in config table are TEXT NOT NULL.
 func updateFromV42(ctx context.Context, tx *sql.Tx) error {
 	stmt := `
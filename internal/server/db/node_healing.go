@@ -0,0 +1,77 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lxc/incus/internal/server/db/query"
+)
+
+// nodeHealingMaxEntries is the number of healing actions kept for each cluster member.
+const nodeHealingMaxEntries = 100
+
+// NodeHealingAction is an action taken while healing an offline cluster member.
+type NodeHealingAction struct {
+	Date     time.Time
+	Action   string
+	Project  string
+	Instance string
+	Target   string
+	Message  string
+}
+
+// CreateNodeHealingAction records a healing action for the cluster member with the given ID.
+// Only the most recent actions of each member are kept.
+func (c *ClusterTx) CreateNodeHealingAction(ctx context.Context, nodeID int64, action NodeHealingAction) error {
+	stmt := `
+INSERT INTO nodes_healing (node_id, date, action, project, instance, target, message)
+  VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+	_, err := c.tx.ExecContext(ctx, stmt, nodeID, action.Date, action.Action, action.Project, action.Instance, action.Target, action.Message)
+	if err != nil {
+		return fmt.Errorf("Failed recording healing action: %w", err)
+	}
+
+	stmt = `
+DELETE FROM nodes_healing
+  WHERE node_id = ? AND id NOT IN (SELECT id FROM nodes_healing WHERE node_id = ? ORDER BY id DESC LIMIT ?)
+`
+	_, err = c.tx.ExecContext(ctx, stmt, nodeID, nodeID, nodeHealingMaxEntries)
+	if err != nil {
+		return fmt.Errorf("Failed pruning healing actions: %w", err)
+	}
+
+	return nil
+}
+
+// GetNodeHealingActions returns the healing actions of the cluster member with the given ID, oldest first.
+func (c *ClusterTx) GetNodeHealingActions(ctx context.Context, nodeID int64) ([]NodeHealingAction, error) {
+	actions := []NodeHealingAction{}
+
+	sql := `
+SELECT date, action, project, instance, target, message
+  FROM nodes_healing
+  WHERE node_id = ?
+  ORDER BY id
+`
+	err := query.Scan(ctx, c.tx, sql, func(scan func(dest ...any) error) error {
+		action := NodeHealingAction{}
+
+		err := scan(&action.Date, &action.Action, &action.Project, &action.Instance, &action.Target, &action.Message)
+		if err != nil {
+			return err
+		}
+
+		actions = append(actions, action)
+
+		return nil
+	}, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching healing actions: %w", err)
+	}
+
+	return actions, nil
+}
@@ -4,6 +4,7 @@ package db_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -388,6 +389,54 @@ INSERT INTO operations (id, uuid, node_id, type, project_id) VALUES (1, 'abc', 1
 	assert.Equal(t, "buzz", member.Name)
 }
 
+// Instances of a placement group are spread across or packed onto members
+// depending on the policy, regardless of the total number of instances.
+func TestGetNodeForPlacementGroup(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	id, err := tx.CreateNode("buzz", "1.2.3.4:666")
+	require.NoError(t, err)
+
+	// Add an instance of the "db" group to the default node (ID 1) and two
+	// ungrouped instances to the newly created node.
+	_, err = tx.Tx().Exec(`
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (1, 1, 'db1', 1, 1, 1, '');
+INSERT INTO instances_config (instance_id, key, value) VALUES (1, 'placement.group', 'db');
+`)
+	require.NoError(t, err)
+
+	_, err = tx.Tx().Exec(`
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (2, ?, 'foo', 1, 1, 1, '')
+`, id)
+	require.NoError(t, err)
+
+	_, err = tx.Tx().Exec(`
+INSERT INTO instances (id, node_id, name, architecture, type, project_id, description) VALUES (3, ?, 'bar', 1, 1, 1, '')
+`, id)
+	require.NoError(t, err)
+
+	allMembers, err := tx.GetNodes(context.Background())
+	require.NoError(t, err)
+
+	members, err := tx.GetCandidateMembers(context.Background(), allMembers, nil, "", nil, time.Duration(db.DefaultOfflineThreshold)*time.Second)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	member, err := tx.GetNodeForPlacementGroup(context.Background(), members, "default", "db", "spread")
+	require.NoError(t, err)
+	assert.Equal(t, "buzz", member.Name)
+
+	member, err = tx.GetNodeForPlacementGroup(context.Background(), members, "default", "db", "pack")
+	require.NoError(t, err)
+	assert.Equal(t, "none", member.Name)
+
+	// Without a placement group, the member with the least instances is picked.
+	member, err = tx.GetNodeForPlacementGroup(context.Background(), members, "default", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "none", member.Name)
+}
+
 // If specific architectures were selected, return only nodes with those
 // architectures.
 func TestGetNodeWithLeastInstances_Architecture(t *testing.T) {
@@ -484,3 +533,50 @@ INSERT INTO instances (id, node_id, name, architecture, type, project_id, descri
 	require.NoError(t, err)
 	assert.Equal(t, "buzz", member.Name)
 }
+
+// Healing actions are returned oldest first and only the most recent ones are kept.
+func TestNodeHealingActions(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	id, err := tx.CreateNode("buzz", "1.2.3.4:666")
+	require.NoError(t, err)
+
+	for i := 0; i < 105; i++ {
+		err = tx.CreateNodeHealingAction(context.Background(), id, db.NodeHealingAction{
+			Date:     time.Now().UTC(),
+			Action:   "instance-moved",
+			Project:  "default",
+			Instance: fmt.Sprintf("c%d", i),
+			Target:   "none",
+		})
+		require.NoError(t, err)
+	}
+
+	actions, err := tx.GetNodeHealingActions(context.Background(), id)
+	require.NoError(t, err)
+	require.Len(t, actions, 100)
+	assert.Equal(t, "c5", actions[0].Instance)
+	assert.Equal(t, "c104", actions[99].Instance)
+
+	// Other members have no healing actions.
+	actions, err = tx.GetNodeHealingActions(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, actions)
+}
+
+func TestNodeInfoInClusterGroup(t *testing.T) {
+	member := db.NodeInfo{
+		Name:   "buzz",
+		Config: map[string]string{"label.rack": "a1", "label.zone": ""},
+		Groups: []string{"default", "gpu"},
+	}
+
+	assert.True(t, member.InClusterGroup("gpu"))
+	assert.False(t, member.InClusterGroup("storage"))
+	assert.True(t, member.InClusterGroup("rack=a1"))
+	assert.False(t, member.InClusterGroup("rack=a2"))
+	assert.True(t, member.InClusterGroup("zone="))
+	assert.False(t, member.InClusterGroup("class="))
+	assert.Equal(t, map[string]string{"rack": "a1", "zone": ""}, member.Labels())
+}
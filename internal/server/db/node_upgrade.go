@@ -0,0 +1,144 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lxc/incus/internal/server/db/query"
+)
+
+// States of a cluster member during a rolling upgrade.
+const (
+	NodeUpgradePending    = "pending"
+	NodeUpgradeEvacuating = "evacuating"
+	NodeUpgradeUpgrading  = "upgrading"
+	NodeUpgradeRejoining  = "rejoining"
+	NodeUpgradeRestoring  = "restoring"
+	NodeUpgradeDone       = "done"
+)
+
+// NodeUpgrade is the state of a cluster member during a rolling upgrade.
+type NodeUpgrade struct {
+	NodeID   int64
+	Name     string
+	Position int
+	State    string
+	Error    string
+	Date     time.Time
+
+	// Version is the version reported by the member before its upgrade.
+	Version string
+
+	// Offline is whether the member was seen offline since it was evacuated.
+	Offline bool
+}
+
+// CreateNodeUpgrades starts tracking a rolling upgrade of the cluster members with the given IDs.
+// The members are upgraded in the given order.
+func (c *ClusterTx) CreateNodeUpgrades(ctx context.Context, nodeIDs []int64) error {
+	now := time.Now().UTC()
+
+	for i, nodeID := range nodeIDs {
+		stmt := "INSERT INTO nodes_upgrade (node_id, position, state, error, date) VALUES (?, ?, ?, '', ?)"
+		_, err := c.tx.ExecContext(ctx, stmt, nodeID, i, NodeUpgradePending, now)
+		if err != nil {
+			return fmt.Errorf("Failed recording cluster member upgrade: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetNodeUpgrades returns the state of the cluster members in the current rolling upgrade, in upgrade order.
+func (c *ClusterTx) GetNodeUpgrades(ctx context.Context) ([]NodeUpgrade, error) {
+	upgrades := []NodeUpgrade{}
+
+	sql := `
+SELECT nodes_upgrade.node_id, nodes.name, nodes_upgrade.position, nodes_upgrade.state, nodes_upgrade.error, nodes_upgrade.date,
+       nodes_upgrade.version, nodes_upgrade.offline
+  FROM nodes_upgrade
+  JOIN nodes ON nodes.id = nodes_upgrade.node_id
+  ORDER BY nodes_upgrade.position
+`
+	err := query.Scan(ctx, c.tx, sql, func(scan func(dest ...any) error) error {
+		upgrade := NodeUpgrade{}
+
+		err := scan(&upgrade.NodeID, &upgrade.Name, &upgrade.Position, &upgrade.State, &upgrade.Error, &upgrade.Date, &upgrade.Version, &upgrade.Offline)
+		if err != nil {
+			return err
+		}
+
+		upgrades = append(upgrades, upgrade)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching cluster member upgrades: %w", err)
+	}
+
+	return upgrades, nil
+}
+
+// UpdateNodeUpgrade sets the upgrade state of the cluster member with the given ID along with its error, if any.
+func (c *ClusterTx) UpdateNodeUpgrade(ctx context.Context, nodeID int64, state string, errorMessage string) error {
+	stmt := "UPDATE nodes_upgrade SET state = ?, error = ?, date = ? WHERE node_id = ?"
+	result, err := c.tx.ExecContext(ctx, stmt, state, errorMessage, time.Now().UTC(), nodeID)
+	if err != nil {
+		return fmt.Errorf("Failed updating cluster member upgrade: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n != 1 {
+		return fmt.Errorf("Cluster member isn't part of the rolling upgrade")
+	}
+
+	return nil
+}
+
+// UpdateNodeUpgradeRestart records the version reported by the cluster member with the given ID before its
+// upgrade and whether it was seen offline since.
+func (c *ClusterTx) UpdateNodeUpgradeRestart(ctx context.Context, nodeID int64, version string, offline bool) error {
+	stmt := "UPDATE nodes_upgrade SET version = ?, offline = ? WHERE node_id = ?"
+	result, err := c.tx.ExecContext(ctx, stmt, version, offline, nodeID)
+	if err != nil {
+		return fmt.Errorf("Failed updating cluster member upgrade: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n != 1 {
+		return fmt.Errorf("Cluster member isn't part of the rolling upgrade")
+	}
+
+	return nil
+}
+
+// ClearNodeUpgradeErrors removes the errors of the current rolling upgrade, so that it can resume.
+func (c *ClusterTx) ClearNodeUpgradeErrors(ctx context.Context) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE nodes_upgrade SET error = '' WHERE error != ''")
+	if err != nil {
+		return fmt.Errorf("Failed clearing cluster member upgrade errors: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteNodeUpgrades stops tracking the current rolling upgrade.
+func (c *ClusterTx) DeleteNodeUpgrades(ctx context.Context) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM nodes_upgrade")
+	if err != nil {
+		return fmt.Errorf("Failed deleting cluster member upgrades: %w", err)
+	}
+
+	return nil
+}
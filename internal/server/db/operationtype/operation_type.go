@@ -71,6 +71,7 @@ const (
 	RenewServerCertificate
 	RemoveExpiredTokens
 	ClusterHeal
+	ClusterRebalance
 )
 
 // Description return a human-readable description of the operation type.
@@ -194,6 +195,8 @@ func (t Type) Description() string {
 		return "Remove expired tokens"
 	case ClusterHeal:
 		return "Healing cluster"
+	case ClusterRebalance:
+		return "Rebalancing cluster"
 	default:
 		return "Executing operation"
 	}
@@ -4,8 +4,10 @@ package db
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/lxc/incus/internal/server/db/cluster"
+	"github.com/lxc/incus/internal/server/db/query"
 )
 
 // GetProject returns the project with the given key.
@@ -26,3 +28,103 @@ func (db *DB) GetProject(ctx context.Context, projectName string) (*cluster.Proj
 
 	return p, nil
 }
+
+// GetProjectStorageBucketsCount returns the number of storage buckets in the project.
+func (c *ClusterTx) GetProjectStorageBucketsCount(ctx context.Context, projectName string) (int, error) {
+	count, err := query.Count(ctx, c.tx, "storage_buckets", "project_id = (SELECT id FROM projects WHERE name = ?)", projectName)
+	if err != nil {
+		return -1, fmt.Errorf("Failed counting storage buckets: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetProjectNetworkForwardsCount returns the number of network forwards owned by the project.
+// Those are the ones it created on the networks of other projects and the ones created by the project owning the
+// network otherwise.
+func (c *ClusterTx) GetProjectNetworkForwardsCount(ctx context.Context, projectName string) (int, error) {
+	count, err := query.Count(ctx, c.tx, "networks_forwards", "IFNULL(project_id, (SELECT networks.project_id FROM networks WHERE networks.id = networks_forwards.network_id)) = (SELECT id FROM projects WHERE name = ?)", projectName)
+	if err != nil {
+		return -1, fmt.Errorf("Failed counting network forwards: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetProjectNetworkLoadBalancersCount returns the number of network load balancers owned by the project.
+// Those are the ones it created on the networks of other projects and the ones created by the project owning the
+// network otherwise.
+func (c *ClusterTx) GetProjectNetworkLoadBalancersCount(ctx context.Context, projectName string) (int, error) {
+	count, err := query.Count(ctx, c.tx, "networks_load_balancers", "IFNULL(project_id, (SELECT networks.project_id FROM networks WHERE networks.id = networks_load_balancers.network_id)) = (SELECT id FROM projects WHERE name = ?)", projectName)
+	if err != nil {
+		return -1, fmt.Errorf("Failed counting network load balancers: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetProjectBackupsCount returns the number of instance and custom storage volume backups in the project.
+func (c *ClusterTx) GetProjectBackupsCount(ctx context.Context, projectName string) (int, error) {
+	instanceBackups, err := query.Count(ctx, c.tx, "instances_backups", "instance_id IN (SELECT instances.id FROM instances JOIN projects ON projects.id = instances.project_id WHERE projects.name = ?)", projectName)
+	if err != nil {
+		return -1, fmt.Errorf("Failed counting instance backups: %w", err)
+	}
+
+	volumeBackups, err := query.Count(ctx, c.tx, "storage_volumes_backups", "storage_volume_id IN (SELECT storage_volumes.id FROM storage_volumes JOIN projects ON projects.id = storage_volumes.project_id WHERE projects.name = ?)", projectName)
+	if err != nil {
+		return -1, fmt.Errorf("Failed counting storage volume backups: %w", err)
+	}
+
+	return instanceBackups + volumeBackups, nil
+}
+
+// GetProjectBackupsSize returns the total size in bytes of the instance and custom storage volume backups in the
+// project.
+func (c *ClusterTx) GetProjectBackupsSize(ctx context.Context, projectName string) (int64, error) {
+	stmt := `
+SELECT
+  (SELECT IFNULL(SUM(instances_backups.size), 0) FROM instances_backups
+    JOIN instances ON instances.id = instances_backups.instance_id
+    JOIN projects ON projects.id = instances.project_id
+    WHERE projects.name = ?)
+  +
+  (SELECT IFNULL(SUM(storage_volumes_backups.size), 0) FROM storage_volumes_backups
+    JOIN storage_volumes ON storage_volumes.id = storage_volumes_backups.storage_volume_id
+    JOIN projects ON projects.id = storage_volumes.project_id
+    WHERE projects.name = ?)
+`
+	var size int64
+	err := c.tx.QueryRowContext(ctx, stmt, projectName, projectName).Scan(&size)
+	if err != nil {
+		return -1, fmt.Errorf("Failed getting backups size: %w", err)
+	}
+
+	return size, nil
+}
+
+// GetProjectMaxSnapshotsCount returns the highest number of snapshots of a single instance or custom storage
+// volume in the project.
+func (c *ClusterTx) GetProjectMaxSnapshotsCount(ctx context.Context, projectName string) (int, error) {
+	stmt := `
+SELECT IFNULL(MAX(count), 0) FROM (
+  SELECT COUNT(*) AS count FROM instances_snapshots
+    JOIN instances ON instances.id = instances_snapshots.instance_id
+    JOIN projects ON projects.id = instances.project_id
+    WHERE projects.name = ?
+    GROUP BY instances_snapshots.instance_id
+  UNION ALL
+  SELECT COUNT(*) AS count FROM storage_volumes_snapshots
+    JOIN storage_volumes ON storage_volumes.id = storage_volumes_snapshots.storage_volume_id
+    JOIN projects ON projects.id = storage_volumes.project_id
+    WHERE projects.name = ?
+    GROUP BY storage_volumes_snapshots.storage_volume_id
+)
+`
+	var count int
+	err := c.tx.QueryRowContext(ctx, stmt, projectName, projectName).Scan(&count)
+	if err != nil {
+		return -1, fmt.Errorf("Failed counting snapshots: %w", err)
+	}
+
+	return count, nil
+}
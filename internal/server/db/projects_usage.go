@@ -0,0 +1,99 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lxc/incus/internal/server/db/query"
+)
+
+// ProjectUsage is a sample of the resources allocated to a project.
+//
+// The sample accounts for the allocations over the Duration preceding its Timestamp.
+type ProjectUsage struct {
+	Project         string
+	Timestamp       time.Time
+	Duration        time.Duration
+	Containers      int64
+	VirtualMachines int64
+	CPU             int64
+	Disk            int64
+}
+
+// CreateProjectUsage records a resource usage sample of a project.
+func (c *ClusterTx) CreateProjectUsage(ctx context.Context, usage ProjectUsage) error {
+	stmt := `
+INSERT INTO projects_usage (project_id, timestamp, duration, containers, virtual_machines, cpu, disk)
+  VALUES ((SELECT id FROM projects WHERE name = ?), ?, ?, ?, ?, ?, ?)
+`
+	_, err := c.tx.ExecContext(ctx, stmt, usage.Project, usage.Timestamp.Unix(), int64(usage.Duration.Seconds()), usage.Containers, usage.VirtualMachines, usage.CPU, usage.Disk)
+	if err != nil {
+		return fmt.Errorf("Failed recording project usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetProjectUsageLastTimestamp returns the time of the most recent resource usage sample of any project.
+// The zero time is returned if there are no samples.
+func (c *ClusterTx) GetProjectUsageLastTimestamp(ctx context.Context) (time.Time, error) {
+	var timestamp int64
+	err := c.tx.QueryRowContext(ctx, "SELECT IFNULL(MAX(timestamp), 0) FROM projects_usage").Scan(&timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Failed fetching last project usage sample: %w", err)
+	}
+
+	if timestamp == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(timestamp, 0).UTC(), nil
+}
+
+// GetProjectUsage returns the resource usage samples of a project taken between start (included) and end
+// (excluded), oldest first.
+func (c *ClusterTx) GetProjectUsage(ctx context.Context, projectName string, start time.Time, end time.Time) ([]ProjectUsage, error) {
+	samples := []ProjectUsage{}
+
+	sql := `
+SELECT projects_usage.timestamp, projects_usage.duration, projects_usage.containers, projects_usage.virtual_machines, projects_usage.cpu, projects_usage.disk
+  FROM projects_usage
+  JOIN projects ON projects.id = projects_usage.project_id
+  WHERE projects.name = ? AND projects_usage.timestamp >= ? AND projects_usage.timestamp < ?
+  ORDER BY projects_usage.timestamp
+`
+	err := query.Scan(ctx, c.tx, sql, func(scan func(dest ...any) error) error {
+		usage := ProjectUsage{Project: projectName}
+
+		var timestamp int64
+		var duration int64
+		err := scan(&timestamp, &duration, &usage.Containers, &usage.VirtualMachines, &usage.CPU, &usage.Disk)
+		if err != nil {
+			return err
+		}
+
+		usage.Timestamp = time.Unix(timestamp, 0).UTC()
+		usage.Duration = time.Duration(duration) * time.Second
+		samples = append(samples, usage)
+
+		return nil
+	}, projectName, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching project usage: %w", err)
+	}
+
+	return samples, nil
+}
+
+// DeleteProjectUsage removes the resource usage samples of all projects taken before the given time.
+func (c *ClusterTx) DeleteProjectUsage(ctx context.Context, before time.Time) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM projects_usage WHERE timestamp < ?", before.Unix())
+	if err != nil {
+		return fmt.Errorf("Failed deleting expired project usage: %w", err)
+	}
+
+	return nil
+}
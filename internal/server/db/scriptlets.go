@@ -0,0 +1,49 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lxc/incus/internal/server/db/query"
+)
+
+// GetScriptletData returns the key/value store of the given scriptlet.
+func (c *ClusterTx) GetScriptletData(ctx context.Context, scriptlet string) (map[string]string, error) {
+	data, err := query.SelectConfig(ctx, c.tx, "scriptlets_data", "scriptlet = ?", scriptlet)
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching scriptlet data: %w", err)
+	}
+
+	return data, nil
+}
+
+// UpdateScriptletData updates the given keys in the key/value store of the given scriptlet.
+// Keys set to empty values will be deleted.
+func (c *ClusterTx) UpdateScriptletData(ctx context.Context, scriptlet string, values map[string]string) error {
+	for key, value := range values {
+		var err error
+		if value == "" {
+			_, err = c.tx.ExecContext(ctx, "DELETE FROM scriptlets_data WHERE scriptlet = ? AND key = ?", scriptlet, key)
+		} else {
+			_, err = c.tx.ExecContext(ctx, "INSERT OR REPLACE INTO scriptlets_data (scriptlet, key, value) VALUES (?, ?, ?)", scriptlet, key, value)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed updating scriptlet data key %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteScriptletData removes all keys from the key/value store of the given scriptlet.
+func (c *ClusterTx) DeleteScriptletData(ctx context.Context, scriptlet string) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM scriptlets_data WHERE scriptlet = ?", scriptlet)
+	if err != nil {
+		return fmt.Errorf("Failed deleting scriptlet data: %w", err)
+	}
+
+	return nil
+}
@@ -223,6 +223,16 @@ func (c *ClusterTx) GetExpiredStorageVolumeSnapshots(ctx context.Context, member
 	return snapshots, nil
 }
 
+// GetStorageVolumeSnapshotsCount returns the number of snapshots of the storage volume with the given ID.
+func (c *ClusterTx) GetStorageVolumeSnapshotsCount(ctx context.Context, volumeID int64) (int, error) {
+	count, err := query.Count(ctx, c.tx, "storage_volumes_snapshots", "storage_volume_id = ?", volumeID)
+	if err != nil {
+		return -1, fmt.Errorf("Failed counting storage volume snapshots: %w", err)
+	}
+
+	return count, nil
+}
+
 // Updates the expiry date of a storage volume snapshot.
 func storageVolumeSnapshotExpiryDateUpdate(tx *sql.Tx, volumeID int64, expiryDate time.Time) error {
 	stmt := "UPDATE storage_volumes_snapshots SET expiry_date=? WHERE id=?"
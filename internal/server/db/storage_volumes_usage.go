@@ -0,0 +1,54 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lxc/incus/internal/server/db/query"
+)
+
+// UpdateStorageVolumeUsage records the disk space used by the storage volume with the given ID.
+// Only the last known usage of each volume is kept.
+func (c *ClusterTx) UpdateStorageVolumeUsage(ctx context.Context, volumeID int64, timestamp time.Time, used int64) error {
+	stmt := "INSERT OR REPLACE INTO storage_volumes_usage (storage_volume_id, timestamp, used) VALUES (?, ?, ?)"
+	_, err := c.tx.ExecContext(ctx, stmt, volumeID, timestamp.Unix(), used)
+	if err != nil {
+		return fmt.Errorf("Failed recording storage volume usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetProjectsStorageUsage returns the last known disk space used by the storage volumes of each project.
+func (c *ClusterTx) GetProjectsStorageUsage(ctx context.Context) (map[string]int64, error) {
+	usage := map[string]int64{}
+
+	sql := `
+SELECT projects.name, SUM(storage_volumes_usage.used)
+  FROM storage_volumes_usage
+  JOIN storage_volumes ON storage_volumes.id = storage_volumes_usage.storage_volume_id
+  JOIN projects ON projects.id = storage_volumes.project_id
+  GROUP BY projects.name
+`
+	err := query.Scan(ctx, c.tx, sql, func(scan func(dest ...any) error) error {
+		var projectName string
+		var used int64
+
+		err := scan(&projectName, &used)
+		if err != nil {
+			return err
+		}
+
+		usage[projectName] = used
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching storage usage: %w", err)
+	}
+
+	return usage, nil
+}
@@ -74,6 +74,7 @@ func (d *usb) Register() error {
 	devConfig := d.config
 	deviceName := d.name
 	state := d.state
+	isVM := d.inst.Type() == instancetype.VM
 
 	// Handler for when a USB event occurs.
 	f := func(e USBEvent) (*deviceConfig.RunConfig, error) {
@@ -83,12 +84,13 @@ func (d *usb) Register() error {
 
 		runConf := deviceConfig.RunConfig{}
 
-		if e.Action == "add" {
+		// Virtual machines get the host device passed through QMP, there are no device nodes to manage.
+		if !isVM && e.Action == "add" {
 			err := unixDeviceSetupCharNum(state, devicesPath, "unix", deviceName, devConfig, e.Major, e.Minor, e.Path, false, &runConf)
 			if err != nil {
 				return nil, err
 			}
-		} else if e.Action == "remove" {
+		} else if !isVM && e.Action == "remove" {
 			relativeTargetPath := strings.TrimPrefix(e.Path, "/")
 			err := unixDeviceRemove(devicesPath, "unix", deviceName, relativeTargetPath, &runConf)
 			if err != nil {
@@ -207,10 +209,10 @@ func (d *usb) Stop() (*deviceConfig.RunConfig, error) {
 		}
 	}
 
-	if d.inst.Type() == instancetype.Container {
-		// Unregister any USB event handlers for this device.
-		usbUnregisterHandler(d.inst, d.name)
+	// Unregister any USB event handlers for this device.
+	usbUnregisterHandler(d.inst, d.name)
 
+	if d.inst.Type() == instancetype.Container {
 		err := unixDeviceRemove(d.inst.DevicesPath(), "unix", d.name, "", &runConf)
 		if err != nil {
 			return nil, err
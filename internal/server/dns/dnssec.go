@@ -0,0 +1,225 @@
+package dns
+
+import (
+	"crypto"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// DNSSECKeyTTL is the TTL of the DNSKEY records.
+const DNSSECKeyTTL = 3600
+
+// dnssecSignatureValidity is how long the generated signatures are valid for.
+// Signatures are generated on every transfer, so this only needs to cover the secondaries' expiry.
+const dnssecSignatureValidity = 14 * 24 * time.Hour
+
+// DNSSECKey represents a key used to sign a zone.
+type DNSSECKey struct {
+	DNSKEY *dns.DNSKEY
+	Signer crypto.Signer
+}
+
+// NewDNSKEY returns the DNSKEY record of a zone key.
+func NewDNSKEY(zoneName string, flags uint16, algorithm uint8, publicKey string) *dns.DNSKEY {
+	return &dns.DNSKEY{
+		Hdr: dns.RR_Header{
+			Name:   dns.Fqdn(zoneName),
+			Rrtype: dns.TypeDNSKEY,
+			Class:  dns.ClassINET,
+			Ttl:    DNSSECKeyTTL,
+		},
+		Flags:     flags,
+		Protocol:  3,
+		Algorithm: algorithm,
+		PublicKey: publicKey,
+	}
+}
+
+// NewDNSSECKey returns a zone signing key from its public key and its private key in BIND format.
+func NewDNSSECKey(zoneName string, flags uint16, algorithm uint8, publicKey string, privateKey string) (*DNSSECKey, error) {
+	key := NewDNSKEY(zoneName, flags, algorithm, publicKey)
+
+	privKey, err := key.NewPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing private key of DNSSEC key %d: %w", key.KeyTag(), err)
+	}
+
+	signer, ok := privKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported private key type for DNSSEC key %d", key.KeyTag())
+	}
+
+	return &DNSSECKey{DNSKEY: key, Signer: signer}, nil
+}
+
+// canonicalLess compares two domain names using the canonical DNS name order (RFC 4034 section 6.1).
+func canonicalLess(a string, b string) bool {
+	labelsA := dns.SplitDomainName(dns.CanonicalName(a))
+	labelsB := dns.SplitDomainName(dns.CanonicalName(b))
+
+	for i := 1; i <= len(labelsA) && i <= len(labelsB); i++ {
+		labelA := labelsA[len(labelsA)-i]
+		labelB := labelsB[len(labelsB)-i]
+
+		if labelA != labelB {
+			return labelA < labelB
+		}
+	}
+
+	return len(labelsA) < len(labelsB)
+}
+
+// signZone returns the records of a zone transfer with its DNSKEY, NSEC and RRSIG records added.
+// Key signing keys sign the DNSKEY record set, the other keys sign everything else.
+func signZone(zoneName string, records []dns.RR, keys []DNSSECKey, now time.Time) ([]dns.RR, error) {
+	type rrsetKey struct {
+		name   string
+		rrtype uint16
+	}
+
+	origin := dns.Fqdn(zoneName)
+
+	// Group the records into record sets, skipping the closing SOA record.
+	var soa *dns.SOA
+	rrsets := map[rrsetKey][]dns.RR{}
+	names := map[string][]uint16{}
+
+	addRecord := func(rr dns.RR) {
+		key := rrsetKey{name: dns.CanonicalName(rr.Header().Name), rrtype: rr.Header().Rrtype}
+
+		if rrsets[key] == nil {
+			names[key.name] = append(names[key.name], key.rrtype)
+		}
+
+		rrsets[key] = append(rrsets[key], rr)
+	}
+
+	for _, rr := range records {
+		if rr.Header().Rrtype == dns.TypeSOA {
+			if soa != nil {
+				continue
+			}
+
+			soa = rr.(*dns.SOA)
+		}
+
+		addRecord(rr)
+	}
+
+	if soa == nil {
+		return nil, fmt.Errorf("Missing SOA record in zone %q", zoneName)
+	}
+
+	// Publish the keys.
+	for _, key := range keys {
+		addRecord(dns.Copy(key.DNSKEY))
+	}
+
+	// Build the NSEC chain for authenticated denial of existence.
+	sortedNames := make([]string, 0, len(names))
+	for name := range names {
+		sortedNames = append(sortedNames, name)
+	}
+
+	sort.Slice(sortedNames, func(i int, j int) bool {
+		return canonicalLess(sortedNames[i], sortedNames[j])
+	})
+
+	for i, name := range sortedNames {
+		types := append([]uint16{dns.TypeNSEC, dns.TypeRRSIG}, names[name]...)
+		sort.Slice(types, func(i int, j int) bool { return types[i] < types[j] })
+
+		addRecord(&dns.NSEC{
+			Hdr: dns.RR_Header{
+				Name:   name,
+				Rrtype: dns.TypeNSEC,
+				Class:  dns.ClassINET,
+				Ttl:    soa.Minttl,
+			},
+			NextDomain: sortedNames[(i+1)%len(sortedNames)],
+			TypeBitMap: types,
+		})
+	}
+
+	// Sort the keys by role, zones with only key signing keys have them sign everything.
+	kskKeys := []DNSSECKey{}
+	zskKeys := []DNSSECKey{}
+	for _, key := range keys {
+		if key.DNSKEY.Flags&dns.SEP != 0 {
+			kskKeys = append(kskKeys, key)
+		} else {
+			zskKeys = append(zskKeys, key)
+		}
+	}
+
+	if len(zskKeys) == 0 {
+		zskKeys = kskKeys
+	}
+
+	if len(kskKeys) == 0 {
+		kskKeys = zskKeys
+	}
+
+	sign := func(rrset []dns.RR) ([]dns.RR, error) {
+		signingKeys := zskKeys
+		if rrset[0].Header().Rrtype == dns.TypeDNSKEY {
+			signingKeys = kskKeys
+		}
+
+		signatures := make([]dns.RR, 0, len(signingKeys))
+		for _, key := range signingKeys {
+			sig := &dns.RRSIG{
+				Hdr: dns.RR_Header{
+					Ttl: rrset[0].Header().Ttl,
+				},
+				KeyTag:     key.DNSKEY.KeyTag(),
+				SignerName: origin,
+				Algorithm:  key.DNSKEY.Algorithm,
+				Inception:  uint32(now.Add(-time.Hour).Unix()),
+				Expiration: uint32(now.Add(dnssecSignatureValidity).Unix()),
+			}
+
+			err := sig.Sign(key.Signer, rrset)
+			if err != nil {
+				return nil, fmt.Errorf("Failed signing %s records of %q: %w", dns.TypeToString[rrset[0].Header().Rrtype], rrset[0].Header().Name, err)
+			}
+
+			signatures = append(signatures, sig)
+		}
+
+		return signatures, nil
+	}
+
+	// Output the signed zone, starting and ending with the SOA record as expected for a transfer.
+	signed := []dns.RR{}
+	for _, name := range sortedNames {
+		types := names[name]
+		sort.Slice(types, func(i int, j int) bool { return types[i] < types[j] })
+
+		// Make sure the SOA record set comes first.
+		if strings.EqualFold(name, origin) {
+			sort.SliceStable(types, func(i int, j int) bool { return types[i] == dns.TypeSOA })
+		}
+
+		for _, rrtype := range types {
+			rrset := rrsets[rrsetKey{name: name, rrtype: rrtype}]
+
+			signatures, err := sign(rrset)
+			if err != nil {
+				return nil, err
+			}
+
+			signed = append(signed, rrset...)
+			signed = append(signed, signatures...)
+		}
+	}
+
+	// The zone apex sorts first, so the SOA is already at the start.
+	signed = append(signed, soa)
+
+	return signed, nil
+}
@@ -0,0 +1,116 @@
+package dns
+
+import (
+	"crypto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func newTestDNSSECKey(t *testing.T, flags uint16) DNSSECKey {
+	key := NewDNSKEY("example.net", flags, dns.ECDSAP256SHA256, "")
+
+	privKey, err := key.Generate(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Round-trip the key through its stored format.
+	out, err := NewDNSSECKey("example.net", flags, dns.ECDSAP256SHA256, key.PublicKey, key.PrivateKeyString(privKey.(crypto.Signer)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return *out
+}
+
+func Test_signZone(t *testing.T) {
+	content := `example.net. 3600 IN SOA example.net. hostmaster.example.net. 1 120 60 86400 30
+example.net. 300 IN NS ns1.example.net.
+c1.example.net. 300 IN A 10.0.0.2
+c1.example.net. 300 IN AAAA fd42::2
+b.example.net. 300 IN A 10.0.0.3
+example.net. 3600 IN SOA example.net. hostmaster.example.net. 1 120 60 86400 30
+`
+
+	records := []dns.RR{}
+	parser := dns.NewZoneParser(strings.NewReader(content), "", "")
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		records = append(records, rr)
+	}
+
+	ksk := newTestDNSSECKey(t, dns.ZONE|dns.SEP)
+	zsk := newTestDNSSECKey(t, dns.ZONE)
+
+	signed, err := signZone("example.net", records, []DNSSECKey{ksk, zsk}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the transfer framing.
+	if signed[0].Header().Rrtype != dns.TypeSOA || signed[len(signed)-1].Header().Rrtype != dns.TypeSOA {
+		t.Fatalf("Signed zone doesn't start and end with the SOA record")
+	}
+
+	// Group the record sets and their signatures.
+	rrsets := map[string][]dns.RR{}
+	sigs := map[string][]*dns.RRSIG{}
+	nsecs := map[string]*dns.NSEC{}
+	for _, rr := range signed[:len(signed)-1] {
+		switch rr := rr.(type) {
+		case *dns.RRSIG:
+			key := rr.Hdr.Name + "/" + dns.TypeToString[rr.TypeCovered]
+			sigs[key] = append(sigs[key], rr)
+		default:
+			if nsec, ok := rr.(*dns.NSEC); ok {
+				nsecs[nsec.Hdr.Name] = nsec
+			}
+
+			key := rr.Header().Name + "/" + dns.TypeToString[rr.Header().Rrtype]
+			rrsets[key] = append(rrsets[key], rr)
+		}
+	}
+
+	for key, rrset := range rrsets {
+		if len(sigs[key]) != 1 {
+			t.Fatalf("Expected one signature for %s, got %d", key, len(sigs[key]))
+		}
+
+		sig := sigs[key][0]
+
+		signer := zsk
+		if rrset[0].Header().Rrtype == dns.TypeDNSKEY {
+			signer = ksk
+		}
+
+		if sig.KeyTag != signer.DNSKEY.KeyTag() {
+			t.Fatalf("Record set %s signed by unexpected key %d", key, sig.KeyTag)
+		}
+
+		err := sig.Verify(signer.DNSKEY, rrset)
+		if err != nil {
+			t.Fatalf("Invalid signature for %s: %v", key, err)
+		}
+	}
+
+	if len(rrsets["example.net./DNSKEY"]) != 2 {
+		t.Fatalf("Expected both keys to be published")
+	}
+
+	// Check the NSEC chain follows the canonical order and loops back to the apex.
+	chain := []string{}
+	for name := "example.net."; ; {
+		chain = append(chain, name)
+		name = nsecs[name].NextDomain
+		if name == "example.net." {
+			break
+		}
+	}
+
+	expected := "example.net. b.example.net. c1.example.net."
+	if strings.Join(chain, " ") != expected {
+		t.Fatalf("Unexpected NSEC chain %q", strings.Join(chain, " "))
+	}
+}
@@ -41,6 +41,17 @@ func (d dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
+	// Answer regular queries when acting as a resolver for the client.
+	// Signed queries come from zone peers and are always handled below, so that they get signed answers.
+	qtype := r.Question[0].Qtype
+	if qtype != dns.TypeAXFR && qtype != dns.TypeIXFR && r.IsTsig() == nil {
+		resolver := d.server.resolverConfig()
+		if resolver.enabled && resolver.allowed(w.RemoteAddr()) {
+			d.resolve(w, r, resolver)
+			return
+		}
+	}
+
 	// Check that it's a supported request type.
 	if r.Question[0].Qtype != dns.TypeAXFR && r.Question[0].Qtype != dns.TypeIXFR && r.Question[0].Qtype != dns.TypeSOA {
 		m := new(dns.Msg)
@@ -123,6 +134,23 @@ func (d dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		m.Answer = append(m.Answer, rr)
 	}
 
+	// Sign the zone.
+	if len(zone.DNSSECKeys) > 0 {
+		m.Answer, err = signZone(name, m.Answer, zone.DNSSECKeys, time.Now())
+		if err != nil {
+			logger.Errorf("Failed signing DNS zone %q: %v", name, err)
+
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeServerFailure)
+			err := w.WriteMsg(m)
+			if err != nil {
+				logger.Error("Unable to write message", logger.Ctx{"err": err})
+			}
+
+			return
+		}
+	}
+
 	tsig := r.IsTsig()
 	if tsig != nil && w.TsigStatus() == nil {
 		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
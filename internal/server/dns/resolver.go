@@ -0,0 +1,260 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/lxc/incus/internal/ports"
+	"github.com/lxc/incus/internal/server/db"
+	internalUtil "github.com/lxc/incus/internal/util"
+	"github.com/lxc/incus/shared/logger"
+)
+
+// resolverForwardTimeout is how long to wait for an upstream server to answer a forwarded query.
+const resolverForwardTimeout = 5 * time.Second
+
+// resolverConfig represents the configuration of the resolver.
+type resolverConfig struct {
+	enabled    bool
+	forwarders []string
+	subnets    []*net.IPNet
+}
+
+// allowed returns true if the client is allowed to use the resolver.
+func (c resolverConfig) allowed(addr net.Addr) bool {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, subnet := range c.subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UpdateResolver configures whether regular queries are answered for the hosted zones and forwarded to upstream servers.
+// Queries are forwarded to the servers in /etc/resolv.conf when no forwarders are provided.
+// Only clients within the given subnets may use the resolver, so it's unusable until some are provided.
+func (s *Server) UpdateResolver(enabled bool, forwarders []string, subnets []string) error {
+	config := resolverConfig{enabled: enabled}
+
+	for _, forwarder := range forwarders {
+		config.forwarders = append(config.forwarders, internalUtil.CanonicalNetworkAddress(forwarder, ports.DNSDefaultPort))
+	}
+
+	for _, subnet := range subnets {
+		_, ipNet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			return fmt.Errorf("Invalid resolver subnet %q: %w", subnet, err)
+		}
+
+		config.subnets = append(config.subnets, ipNet)
+	}
+
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resolver = config
+
+	return nil
+}
+
+// resolverConfig returns the current resolver configuration.
+func (s *Server) resolverConfig() resolverConfig {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.resolver
+}
+
+// zoneForName returns the most specific hosted zone containing the name, or an empty string if none does.
+func (s *Server) zoneForName(name string) (string, error) {
+	if s.db == nil {
+		return "", nil
+	}
+
+	// Check the name itself and all of its parents.
+	candidates := []string{}
+	for candidate := name; candidate != ""; {
+		candidates = append(candidates, candidate)
+		_, candidate, _ = strings.Cut(candidate, ".")
+	}
+
+	var zoneName string
+	err := s.db.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		zoneName, err = tx.GetNetworkZoneNameForCandidates(ctx, candidates)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return zoneName, nil
+}
+
+// lookupRecords returns the records of a zone matching the name and type, along with the zone's SOA record.
+// A CNAME record is returned when the name is an alias. The returned bool indicates whether the name exists.
+func lookupRecords(records []dns.RR, name string, qtype uint16) ([]dns.RR, *dns.SOA, bool) {
+	name = dns.CanonicalName(name)
+
+	var soa *dns.SOA
+	answer := []dns.RR{}
+	exists := false
+
+	for _, rr := range records {
+		hdr := rr.Header()
+
+		// Zone transfers include the SOA record twice.
+		if hdr.Rrtype == dns.TypeSOA {
+			if soa != nil {
+				continue
+			}
+
+			soa = rr.(*dns.SOA)
+		}
+
+		if dns.CanonicalName(hdr.Name) != name {
+			continue
+		}
+
+		exists = true
+
+		if hdr.Rrtype == qtype || qtype == dns.TypeANY || hdr.Rrtype == dns.TypeCNAME {
+			answer = append(answer, rr)
+		}
+	}
+
+	return answer, soa, exists
+}
+
+// resolve answers a regular query, either from the hosted zones or through the upstream servers.
+// The caller must have checked that the client is allowed to use the resolver.
+func (d dnsHandler) resolve(w dns.ResponseWriter, r *dns.Msg, config resolverConfig) {
+	question := r.Question[0]
+	name := strings.TrimSuffix(dns.CanonicalName(question.Name), ".")
+
+	zoneName, err := d.server.zoneForName(name)
+	if err != nil {
+		logger.Error("Failed to look up DNS zones", logger.Ctx{"err": err})
+		d.writeRcode(w, r, dns.RcodeServerFailure)
+		return
+	}
+
+	if zoneName == "" {
+		d.forward(w, r, config)
+		return
+	}
+
+	// The hosted zones are visible to all the clients allowed to use the resolver.
+	zone, err := d.server.zoneRetriever(zoneName, true)
+	if err != nil {
+		d.writeRcode(w, r, dns.RcodeServerFailure)
+		return
+	}
+
+	records := []dns.RR{}
+	zoneRR := dns.NewZoneParser(strings.NewReader(zone.Content), "", "")
+	for rr, ok := zoneRR.Next(); ok; rr, ok = zoneRR.Next() {
+		records = append(records, rr)
+	}
+
+	err = zoneRR.Err()
+	if err != nil {
+		logger.Errorf("Bad DNS record in zone %q: %v", zoneName, err)
+		d.writeRcode(w, r, dns.RcodeServerFailure)
+		return
+	}
+
+	answer, soa, exists := lookupRecords(records, question.Name, question.Qtype)
+
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+	m.RecursionAvailable = true
+	m.Answer = answer
+
+	// Negative answers include the SOA record so they can be cached.
+	if len(answer) == 0 {
+		if !exists {
+			m.Rcode = dns.RcodeNameError
+		}
+
+		if soa != nil {
+			m.Ns = []dns.RR{soa}
+		}
+	}
+
+	err = w.WriteMsg(m)
+	if err != nil {
+		logger.Error("Unable to write message", logger.Ctx{"err": err})
+	}
+}
+
+// forward sends the query to the upstream servers and relays the first answer.
+func (d dnsHandler) forward(w dns.ResponseWriter, r *dns.Msg, config resolverConfig) {
+	forwarders := config.forwarders
+	if len(forwarders) == 0 {
+		clientConfig, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+		if err == nil {
+			for _, server := range clientConfig.Servers {
+				forwarders = append(forwarders, net.JoinHostPort(server, clientConfig.Port))
+			}
+		}
+	}
+
+	// Use the same transport as the client so truncated answers can be retried over TCP.
+	client := &dns.Client{Net: "udp", Timeout: resolverForwardTimeout}
+	_, isTCP := w.RemoteAddr().(*net.TCPAddr)
+	if isTCP {
+		client.Net = "tcp"
+	}
+
+	for _, forwarder := range forwarders {
+		resp, _, err := client.Exchange(r, forwarder)
+		if err != nil {
+			logger.Debug("Failed forwarding DNS query", logger.Ctx{"name": r.Question[0].Name, "forwarder": forwarder, "err": err})
+			continue
+		}
+
+		resp.Id = r.Id
+
+		err = w.WriteMsg(resp)
+		if err != nil {
+			logger.Error("Unable to write message", logger.Ctx{"err": err})
+		}
+
+		return
+	}
+
+	d.writeRcode(w, r, dns.RcodeServerFailure)
+}
+
+// writeRcode replies to the query with an empty response and the given response code.
+func (d dnsHandler) writeRcode(w dns.ResponseWriter, r *dns.Msg, rcode int) {
+	m := new(dns.Msg)
+	m.SetRcode(r, rcode)
+	m.RecursionAvailable = true
+
+	err := w.WriteMsg(m)
+	if err != nil {
+		logger.Error("Unable to write message", logger.Ctx{"err": err})
+	}
+}
@@ -0,0 +1,62 @@
+package dns
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func Test_lookupRecords(t *testing.T) {
+	content := `example.net. 3600 IN SOA example.net. hostmaster.example.net. 1 120 60 86400 30
+c1.example.net. 300 IN A 10.0.0.2
+c1.example.net. 300 IN AAAA fd42::2
+www.example.net. 300 IN CNAME c1.example.net.
+example.net. 3600 IN SOA example.net. hostmaster.example.net. 1 120 60 86400 30
+`
+
+	records := []dns.RR{}
+	parser := dns.NewZoneParser(strings.NewReader(content), "", "")
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		records = append(records, rr)
+	}
+
+	tests := []struct {
+		name   string
+		qtype  uint16
+		answer int
+		exists bool
+	}{
+		{"C1.example.net.", dns.TypeA, 1, true},
+		{"c1.example.net.", dns.TypeANY, 2, true},
+		{"c1.example.net.", dns.TypeMX, 0, true},
+		{"www.example.net.", dns.TypeA, 1, true},
+		{"c2.example.net.", dns.TypeA, 0, false},
+		{"example.net.", dns.TypeSOA, 1, true},
+	}
+
+	for _, tt := range tests {
+		answer, soa, exists := lookupRecords(records, tt.name, tt.qtype)
+		if len(answer) != tt.answer || exists != tt.exists {
+			t.Errorf("lookupRecords(%q, %s) returned %d records (exists=%v), expected %d (exists=%v)", tt.name, dns.TypeToString[tt.qtype], len(answer), exists, tt.answer, tt.exists)
+		}
+
+		if soa == nil {
+			t.Errorf("lookupRecords(%q, %s) didn't return the SOA record", tt.name, dns.TypeToString[tt.qtype])
+		}
+	}
+}
+
+func Test_resolverConfigAllowed(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	config := resolverConfig{enabled: true, subnets: []*net.IPNet{subnet}}
+
+	if !config.allowed(&net.UDPAddr{IP: net.ParseIP("10.0.0.5"), Port: 1234}) {
+		t.Errorf("Expected client in subnet to be allowed")
+	}
+
+	if config.allowed(&net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234}) {
+		t.Errorf("Expected client outside of subnet to be refused")
+	}
+}
@@ -25,7 +25,8 @@ type Server struct {
 	zoneRetriever ZoneRetriever
 
 	// Internal state (to handle reconfiguration).
-	address string
+	address  string
+	resolver resolverConfig
 
 	mu sync.Mutex
 }
@@ -8,4 +8,7 @@ import (
 type Zone struct {
 	Info    api.NetworkZone
 	Content string
+
+	// Keys used to sign full zone transfers, only set when DNSSEC is enabled.
+	DNSSECKeys []DNSSECKey
 }
@@ -15,6 +15,7 @@ type SNATOpts struct {
 	Append      bool       // Append rules (has no effect if driver doesn't support it).
 	Subnet      *net.IPNet // Subnet of source network used to identify candidate traffic.
 	SNATAddress net.IP     // SNAT IP address to use. If nil then MASQUERADE is used.
+	ExcludeDev  string     // Outbound interface for which traffic should not be translated (if set).
 }
 
 // Opts for setting up the firewall.
@@ -32,9 +32,9 @@ chain pstrt{{.chainSeparator}}{{.networkName}} {
 
 	{{- range $ipFamily, $config := .rules}}
 	{{if $config.SNATAddress -}}
-	{{$ipFamily}} saddr {{$config.Subnet}} {{$ipFamily}} daddr != {{$config.Subnet}} snat {{$config.SNATAddress}}
+	{{$ipFamily}} saddr {{$config.Subnet}} {{$ipFamily}} daddr != {{$config.Subnet}} {{if $config.ExcludeDev}}oifname != "{{$config.ExcludeDev}}" {{end}}snat {{$config.SNATAddress}}
 	{{else -}}
-	{{$ipFamily}} saddr {{$config.Subnet}} {{$ipFamily}} daddr != {{$config.Subnet}} masquerade
+	{{$ipFamily}} saddr {{$config.Subnet}} {{$ipFamily}} daddr != {{$config.Subnet}} {{if $config.ExcludeDev}}oifname != "{{$config.ExcludeDev}}" {{end}}masquerade
 	{{- end}}
 	{{- end}}
 }
@@ -344,7 +344,8 @@ func (d Xtables) networkSetupForwardingPolicy(networkName string, ipVersion uint
 
 // networkSetupOutboundNAT configures outbound NAT.
 // If srcIP is non-nil then SNAT is used with the specified address, otherwise MASQUERADE mode is used.
-func (d Xtables) networkSetupOutboundNAT(networkName string, subnet *net.IPNet, srcIP net.IP, appendRule bool) error {
+// If excludeDev is non-empty then traffic leaving through that interface isn't translated.
+func (d Xtables) networkSetupOutboundNAT(networkName string, subnet *net.IPNet, srcIP net.IP, excludeDev string, appendRule bool) error {
 	family := uint(4)
 	if subnet.IP.To4() == nil {
 		family = 6
@@ -355,6 +356,10 @@ func (d Xtables) networkSetupOutboundNAT(networkName string, subnet *net.IPNet,
 		"!", "-d", subnet.String(),
 	}
 
+	if excludeDev != "" {
+		args = append(args, "!", "-o", excludeDev)
+	}
+
 	// If SNAT IP not supplied then use the IP of the outbound interface (MASQUERADE).
 	if srcIP == nil {
 		args = append(args, "-j", "MASQUERADE")
@@ -444,14 +449,14 @@ func (d Xtables) networkSetupDHCPv4Checksum(networkName string) error {
 // NetworkSetup configure network firewall.
 func (d Xtables) NetworkSetup(networkName string, opts Opts) error {
 	if opts.SNATV4 != nil {
-		err := d.networkSetupOutboundNAT(networkName, opts.SNATV4.Subnet, opts.SNATV4.SNATAddress, opts.SNATV4.Append)
+		err := d.networkSetupOutboundNAT(networkName, opts.SNATV4.Subnet, opts.SNATV4.SNATAddress, opts.SNATV4.ExcludeDev, opts.SNATV4.Append)
 		if err != nil {
 			return err
 		}
 	}
 
 	if opts.SNATV6 != nil {
-		err := d.networkSetupOutboundNAT(networkName, opts.SNATV6.Subnet, opts.SNATV6.SNATAddress, opts.SNATV6.Append)
+		err := d.networkSetupOutboundNAT(networkName, opts.SNATV6.Subnet, opts.SNATV6.SNATAddress, opts.SNATV6.ExcludeDev, opts.SNATV6.Append)
 		if err != nil {
 			return err
 		}
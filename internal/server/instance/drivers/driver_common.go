@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pborman/uuid"
@@ -30,6 +31,7 @@ import (
 	"github.com/lxc/incus/internal/server/locking"
 	"github.com/lxc/incus/internal/server/operations"
 	"github.com/lxc/incus/internal/server/project"
+	"github.com/lxc/incus/internal/server/resources"
 	"github.com/lxc/incus/internal/server/state"
 	storagePools "github.com/lxc/incus/internal/server/storage"
 	internalUtil "github.com/lxc/incus/internal/util"
@@ -953,6 +955,102 @@ func (d *common) canMigrate(inst instance.Instance) (bool, bool) {
 	return true, live
 }
 
+// numaPlacementLock prevents concurrent instance starts from picking the same CPUs.
+var numaPlacementLock sync.Mutex
+
+// cpuPinningUsage returns the number of other running instances pinned to each CPU thread.
+func (d *common) cpuPinningUsage() (map[int64]int, error) {
+	instances, err := instance.LoadNodeAll(d.state, instancetype.Any)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := map[int64]int{}
+	for _, inst := range instances {
+		if inst.ID() == d.id || !inst.IsRunning() {
+			continue
+		}
+
+		config := inst.ExpandedConfig()
+
+		cpuSet := config["volatile.cpu.set"]
+		if cpuSet == "" {
+			// Skip instances which aren't pinned to specific CPUs.
+			_, err := strconv.Atoi(config["limits.cpu"])
+			if config["limits.cpu"] == "" || err == nil {
+				continue
+			}
+
+			cpuSet = config["limits.cpu"]
+		}
+
+		cpus, err := resources.ParseCpuset(cpuSet)
+		if err != nil {
+			continue
+		}
+
+		for _, id := range cpus {
+			usage[id]++
+		}
+	}
+
+	return usage, nil
+}
+
+// placeNUMA selects the least used NUMA nodes of the host and their CPU threads for an instance with
+// limits.cpu.nodes set to "balanced".
+// The caller must hold numaPlacementLock until the placement has been recorded.
+func (d *common) placeNUMA(count int, hugepages uint64) (*resources.NUMAPlacement, error) {
+	cpus, err := resources.GetCPU()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting CPU information: %w", err)
+	}
+
+	memory, err := resources.GetMemory()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting memory information: %w", err)
+	}
+
+	usage, err := d.cpuPinningUsage()
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting CPU usage: %w", err)
+	}
+
+	placement, err := resources.PlaceNUMA(cpus, memory, usage, count, hugepages)
+	if err != nil {
+		return nil, fmt.Errorf("Failed placing instance on NUMA nodes: %w", err)
+	}
+
+	d.logger.Debug("Selected NUMA placement", logger.Ctx{"nodes": placement.Nodes, "cpus": placement.CPUs})
+
+	return placement, nil
+}
+
+// formatCPUSet returns a CPU set in the format used by limits.cpu.
+// A single CPU uses the range syntax to differentiate it from a number of CPUs.
+func formatCPUSet(ids []int64) string {
+	if len(ids) == 1 {
+		return fmt.Sprintf("%d-%d", ids[0], ids[0])
+	}
+
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.FormatInt(id, 10))
+	}
+
+	return strings.Join(values, ",")
+}
+
+// formatNUMANodes returns a NUMA node set in the format used by limits.cpu.nodes.
+func formatNUMANodes(ids []int64) string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, strconv.FormatInt(id, 10))
+	}
+
+	return strings.Join(values, ",")
+}
+
 // recordLastState records last power and used time into local config and database config.
 func (d *common) recordLastState() error {
 	var err error
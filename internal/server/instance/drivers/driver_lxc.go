@@ -2326,6 +2326,11 @@ func (d *lxc) Start(stateful bool) error {
 	d.logger.Debug("Start started", logger.Ctx{"stateful": stateful})
 	defer d.logger.Debug("Start finished", logger.Ctx{"stateful": stateful})
 
+	// Instances created from a stateful image resume the runtime state it carried on their first start.
+	if !stateful && d.stateful && util.IsTrue(d.localConfig["volatile.apply_state"]) {
+		stateful = true
+	}
+
 	// Check that we are startable before creating an operation lock.
 	// Must happen before creating operation Start lock to avoid the status check returning Stopped due to the
 	// existence of a Start operation lock.
@@ -2399,6 +2404,14 @@ func (d *lxc) Start(stateful bool) error {
 			return fmt.Errorf("Failed clearing instance stateful flag: %w", err)
 		}
 
+		if d.localConfig["volatile.apply_state"] != "" {
+			err = d.VolatileSet(map[string]string{"volatile.apply_state": ""})
+			if err != nil {
+				op.Done(err)
+				return fmt.Errorf("Failed clearing volatile.apply_state: %w", err)
+			}
+		}
+
 		if op.Action() == "start" {
 			d.logger.Info("Started instance", ctxMap)
 			d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
@@ -2540,7 +2553,13 @@ func (d *lxc) onStart(_ map[string]string) error {
 		return err
 	}
 
-	// Trigger a rebalance
+	// Select the NUMA nodes if needed and trigger a rebalance.
+	err = d.placeNUMANodes()
+	if err != nil {
+		_ = apparmor.InstanceUnload(d.state.OS, d)
+		return err
+	}
+
 	cgroup.TaskSchedulerTrigger("container", d.name, "started")
 
 	// Record last start state.
@@ -2884,10 +2903,11 @@ func (d *lxc) onStop(args map[string]string) error {
 	// Make sure we can't call go-lxc functions by mistake
 	d.fromHook = true
 
-	// Record power state.
+	// Record power state and release the NUMA placement.
 	err = d.VolatileSet(map[string]string{
 		"volatile.last_state.power": instance.PowerStateStopped,
 		"volatile.last_state.ready": "false",
+		"volatile.cpu.nodes":        "",
 	})
 	if err != nil {
 		// Don't return an error here as we still want to cleanup the instance even if DB not available.
@@ -4538,6 +4558,11 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 					}
 				}
 			} else if key == "limits.cpu" || key == "limits.cpu.nodes" {
+				err = d.placeNUMANodes()
+				if err != nil {
+					return err
+				}
+
 				// Trigger a scheduler re-run
 				cgroup.TaskSchedulerTrigger("container", d.name, "changed")
 			} else if key == "limits.cpu.priority" || key == "limits.cpu.allowance" {
@@ -4753,11 +4778,12 @@ func (d *lxc) Update(args db.InstanceArgs, userRequested bool) error {
 }
 
 // Export backs up the instance.
-func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.Time) (api.ImageMetadata, error) {
+func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.Time, stateful bool) (api.ImageMetadata, error) {
 	ctxMap := logger.Ctx{
 		"created":   d.creationDate,
 		"ephemeral": d.ephemeral,
-		"used":      d.lastUsedDate}
+		"used":      d.lastUsedDate,
+		"stateful":  stateful}
 
 	meta := api.ImageMetadata{}
 
@@ -4806,6 +4832,18 @@ func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.
 		return nil
 	}
 
+	// Flag images including the runtime state so that instances created from them resume it.
+	statefulProperties := func(properties map[string]string) map[string]string {
+		newProperties := make(map[string]string, len(properties)+1)
+		for k, v := range properties {
+			newProperties[k] = v
+		}
+
+		newProperties["stateful"] = "true"
+
+		return newProperties
+	}
+
 	// Look for metadata.yaml.
 	fnam := filepath.Join(cDir, "metadata.yaml")
 	if !util.PathExists(fnam) {
@@ -4847,6 +4885,10 @@ func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.
 		meta.Architecture = arch
 		meta.CreationDate = time.Now().UTC().Unix()
 		meta.Properties = properties
+		if stateful {
+			meta.Properties = statefulProperties(meta.Properties)
+		}
+
 		if !expiration.IsZero() {
 			meta.ExpiryDate = expiration.UTC().Unix()
 		}
@@ -4906,7 +4948,11 @@ func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.
 			meta.Properties = properties
 		}
 
-		if properties != nil || !expiration.IsZero() {
+		if stateful {
+			meta.Properties = statefulProperties(meta.Properties)
+		}
+
+		if properties != nil || !expiration.IsZero() || stateful {
 			// Generate a new metadata.yaml.
 			tempDir, err := os.MkdirTemp("", "incus_metadata_")
 			if err != nil {
@@ -4943,7 +4989,7 @@ func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.
 			return meta, err
 		}
 
-		if properties != nil || !expiration.IsZero() {
+		if properties != nil || !expiration.IsZero() || stateful {
 			tmpOffset := len(path.Dir(fnam)) + 1
 			err = tarWriter.WriteFile(fnam[tmpOffset:], fnam, fi, false)
 		} else {
@@ -4976,6 +5022,22 @@ func (d *lxc) Export(w io.Writer, properties map[string]string, expiration time.
 		}
 	}
 
+	// Include the saved runtime state so that instances created from the image resume it.
+	if stateful {
+		fnam = d.StatePath()
+		if !d.IsStateful() || !util.PathExists(fnam) {
+			_ = tarWriter.Close()
+			d.logger.Error("Failed exporting instance", ctxMap)
+			return meta, fmt.Errorf("Instance has no saved state to export")
+		}
+
+		err = filepath.Walk(fnam, writeToTar)
+		if err != nil {
+			d.logger.Error("Failed exporting instance", ctxMap)
+			return meta, err
+		}
+	}
+
 	err = tarWriter.Close()
 	if err != nil {
 		d.logger.Error("Failed exporting instance", ctxMap)
@@ -5012,6 +5074,38 @@ func getCRIULogErrors(imagesDir string, method string) (string, error) {
 	return strings.Join(ret, "\n"), nil
 }
 
+// CheckLiveMigration checks whether the container's configuration can be checkpointed by CRIU.
+// This is used to refuse a live migration before anything gets transferred.
+func (d *lxc) CheckLiveMigration() error {
+	_, err := exec.LookPath("criu")
+	if err != nil {
+		return localMigration.ErrNoLiveMigration
+	}
+
+	if d.IsNesting() {
+		return fmt.Errorf("Live migration isn't supported for containers with security.nesting enabled")
+	}
+
+	for _, dev := range d.expandedDevices.Sorted() {
+		switch dev.Config["type"] {
+		case "gpu", "usb", "unix-char", "unix-block", "unix-hotplug", "infiniband", "tpm":
+			return fmt.Errorf("Live migration isn't supported for containers with %q devices (device %q)", dev.Config["type"], dev.Name)
+		case "nic":
+			nicType, err := nictype.NICType(d.state, d.Project().Name, dev.Config)
+			if err != nil {
+				return err
+			}
+
+			// CRIU can only restore veth based interfaces.
+			if !util.ValueInSlice(nicType, []string{"bridged", "ovn", "p2p", "routed"}) {
+				return fmt.Errorf("Live migration isn't supported for containers with %q network interfaces (device %q)", nicType, dev.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Check if CRIU supports pre-dumping and number of pre-dump iterations.
 func (d *lxc) migrationSendCheckForPreDumpSupport() (bool, int) {
 	// Check if this architecture/kernel/criu combination supports pre-copy dirty memory tracking feature.
@@ -5398,11 +5492,7 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 					final := false
 					for !final {
 						preDumpCounter++
-						if preDumpCounter < maxDumpIterations {
-							final = false
-						} else {
-							final = true
-						}
+						final = preDumpCounter >= maxDumpIterations
 
 						dumpDir := fmt.Sprintf("%03d", preDumpCounter)
 						loopArgs := preDumpLoopArgs{
@@ -5413,6 +5503,8 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 							dumpDir:       dumpDir,
 							final:         final,
 							rsyncFeatures: rsyncFeatures,
+							iteration:     preDumpCounter,
+							maxIterations: maxDumpIterations,
 						}
 
 						final, err = d.migrateSendPreDumpLoop(&loopArgs)
@@ -5421,8 +5513,7 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 							return err
 						}
 
-						preDumpDir = fmt.Sprintf("%03d", preDumpCounter)
-						preDumpCounter++
+						preDumpDir = dumpDir
 					}
 				} else {
 					d.logger.Debug("The other side does not support pre-copy")
@@ -5434,6 +5525,11 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 					return err
 				}
 
+				// The container stays frozen from the final dump until the restore on the target is done.
+				// When pre-dumps were sent, only the pages dirtied since the last one need to be dumped.
+				d.migrationSendProgress("final-dump", "Final memory dump (container frozen)", nil)
+				dumpStart := time.Now()
+
 				go func() {
 					d.logger.Debug("Final CRIU dump started")
 					defer d.logger.Debug("Final CRIU dump stopped")
@@ -5459,7 +5555,7 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 					return err
 				// The dump finished, let's continue on to the restore.
 				case <-dumpDone:
-					d.logger.Debug("Dump finished, continuing with restore...")
+					d.logger.Debug("Dump finished, continuing with restore...", logger.Ctx{"duration": time.Since(dumpStart)})
 				}
 			} else {
 				d.logger.Debug("The version of liblxc is older than 2.0.4 and the live migration will probably fail")
@@ -5485,12 +5581,15 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 			// However assuming we're network bound, there's really no reason to do these in.
 			// parallel. In the future when we're using p.haul's protocol, it will make sense
 			// to do these in parallel.
+			d.migrationSendProgress("final-transfer", "Transferring final container state", nil)
+
 			ctName, _, _ := api.GetParentAndSnapshotName(d.Name())
 			err = rsync.Send(ctName, internalUtil.AddSlash(checkpointDir), stateConn, nil, rsyncFeatures, rsyncBwlimit, d.state.OS.ExecPath)
 			if err != nil {
 				return err
 			}
 
+			d.migrationSendProgress("", "", nil)
 			d.logger.Debug("Finished live migration phase")
 		}
 
@@ -5542,6 +5641,38 @@ type preDumpLoopArgs struct {
 	dumpDir       string
 	final         bool
 	rsyncFeatures []string
+	iteration     int
+	maxIterations int
+}
+
+// migrationSendProgress reports the progress of the live migration state transfer through the operation
+// metadata. An empty stage clears it.
+func (d *lxc) migrationSendProgress(stage string, text string, details map[string]string) {
+	if d.op == nil {
+		return
+	}
+
+	meta := map[string]any{}
+	for k, v := range d.op.Metadata() {
+		// The storage transfer progress is stale by now.
+		if k == "progress" || strings.HasSuffix(k, "_progress") {
+			continue
+		}
+
+		meta[k] = v
+	}
+
+	if stage != "" {
+		progress := map[string]string{"stage": "live", "phase": stage}
+		for k, v := range details {
+			progress[k] = v
+		}
+
+		meta["progress"] = progress
+		meta["live_progress"] = text
+	}
+
+	_ = d.op.UpdateMetadata(meta)
 }
 
 // migrateSendPreDumpLoop is the main logic behind the pre-copy migration.
@@ -5607,6 +5738,14 @@ func (d *lxc) migrateSendPreDumpLoop(args *preDumpLoopArgs) (bool, error) {
 
 	d.logger.Debug("CRIU pages", logger.Ctx{"pages": written, "skipped": skippedParent, "skippedPerc": percentageSkipped})
 
+	d.migrationSendProgress("pre-dump", fmt.Sprintf("Memory pre-copy: iteration %d/%d (%d%% unchanged)", args.iteration, args.maxIterations, percentageSkipped), map[string]string{
+		"iteration":       strconv.Itoa(args.iteration),
+		"max_iterations":  strconv.Itoa(args.maxIterations),
+		"pages_written":   strconv.FormatUint(written, 10),
+		"pages_skipped":   strconv.FormatUint(skippedParent, 10),
+		"percent_skipped": strconv.Itoa(percentageSkipped),
+	})
+
 	// threshold is the percentage of memory pages that needs
 	// to be pre-copied for the pre-copy migration to stop.
 	var threshold int
@@ -7889,6 +8028,31 @@ func (d *lxc) CanMigrate() (bool, bool) {
 	return d.canMigrate(d)
 }
 
+// placeNUMANodes selects the NUMA nodes of a container with limits.cpu.nodes set to "balanced" and records
+// them in volatile.cpu.nodes for the CPU scheduler to balance the container within.
+func (d *lxc) placeNUMANodes() error {
+	nodes := ""
+
+	count, err := strconv.Atoi(d.expandedConfig["limits.cpu"])
+	if d.expandedConfig["limits.cpu.nodes"] == "balanced" && err == nil {
+		numaPlacementLock.Lock()
+		defer numaPlacementLock.Unlock()
+
+		placement, err := d.placeNUMA(count, 0)
+		if err != nil {
+			return err
+		}
+
+		nodes = formatNUMANodes(placement.Nodes)
+	}
+
+	if d.localConfig["volatile.cpu.nodes"] == nodes {
+		return nil
+	}
+
+	return d.VolatileSet(map[string]string{"volatile.cpu.nodes": nodes})
+}
+
 // LockExclusive attempts to get exlusive access to the instance's root volume.
 func (d *lxc) LockExclusive() (*operationlock.InstanceOperation, error) {
 	if d.IsRunning() {
@@ -13,6 +13,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image/png"
 	"io"
 	"io/fs"
 	"net"
@@ -91,6 +92,16 @@ const QEMUDefaultCPUCores = 1
 // QEMUDefaultMemSize is the default memory size for VMs if no limit specified.
 const QEMUDefaultMemSize = "1GiB"
 
+// qemuMemoryHotplugSlots is the number of memory devices that can be hotplugged into a VM.
+const qemuMemoryHotplugSlots = 8
+
+// qemuMemoryHotplugAlignment is the size hotplugged memory devices must be a multiple of.
+// This matches the memory block size of x86_64 guests, which can only bring whole blocks online.
+const qemuMemoryHotplugAlignment = 128 * 1024 * 1024
+
+// qemuCPUUnplugTimeout is how long to wait for the guest to release a hot-unplugged CPU.
+const qemuCPUUnplugTimeout = 30 * time.Second
+
 // qemuSerialChardevName is used to communicate state with QEMU via QMP.
 const qemuSerialChardevName = "qemu_serial-chardev"
 
@@ -636,6 +647,19 @@ func (d *qemu) onStop(target string) error {
 		d.logger.Error("Failed recording last power state", logger.Ctx{"err": err})
 	}
 
+	// Release the NUMA placement unless the state was saved, in which case it must be kept for the restore.
+	if d.localConfig["volatile.cpu.set"] != "" && !d.stateful {
+		err = d.VolatileSet(map[string]string{
+			"volatile.cpu.nodes": "",
+			"volatile.cpu.set":   "",
+		})
+		if err != nil {
+			d.logger.Error("Failed releasing NUMA placement", logger.Ctx{"err": err})
+		}
+
+		cgroup.TaskSchedulerTrigger("virtual-machine", d.name, "stopped")
+	}
+
 	// Cleanup.
 	d.cleanupDevices() // Must be called before unmount.
 	_ = os.Remove(d.pidFilePath())
@@ -1034,39 +1058,64 @@ func (d *qemu) validateStartup(stateful bool, statusCode api.StatusCode) error {
 	// Otherwise, there will not be enough disk space to write the instance state to disk during any subsequent stops.
 	// (Only check when migration.stateful is true, otherwise the memory won't be dumped when this instance stops).
 	if util.IsTrue(d.expandedConfig["migration.stateful"]) {
-		_, rootDiskDevice, err := d.getRootDiskDevice()
+		err = d.validateStateSize()
 		if err != nil {
-			return err
+			return fmt.Errorf("Stateful start requires that the instance limits.memory is less than size.state on the root disk device: %w", err)
 		}
+	}
 
-		stateDiskSizeStr := deviceConfig.DefaultVMBlockFilesystemSize
-		if rootDiskDevice["size.state"] != "" {
-			stateDiskSizeStr = rootDiskDevice["size.state"]
-		}
+	return nil
+}
 
-		stateDiskSize, err := units.ParseByteSizeString(stateDiskSizeStr)
-		if err != nil {
-			return err
-		}
+// validateStateSize checks that the instance's config volume is large enough to hold its memory state.
+func (d *qemu) validateStateSize() error {
+	_, rootDiskDevice, err := d.getRootDiskDevice()
+	if err != nil {
+		return err
+	}
 
-		memoryLimitStr := QEMUDefaultMemSize
-		if d.expandedConfig["limits.memory"] != "" {
-			memoryLimitStr = d.expandedConfig["limits.memory"]
-		}
+	stateDiskSizeStr := deviceConfig.DefaultVMBlockFilesystemSize
+	if rootDiskDevice["size.state"] != "" {
+		stateDiskSizeStr = rootDiskDevice["size.state"]
+	}
 
-		memoryLimit, err := units.ParseByteSizeString(memoryLimitStr)
-		if err != nil {
-			return err
-		}
+	stateDiskSize, err := units.ParseByteSizeString(stateDiskSizeStr)
+	if err != nil {
+		return err
+	}
 
-		if stateDiskSize < memoryLimit {
-			return fmt.Errorf("Stateful start requires that the instance limits.memory is less than size.state on the root disk device")
-		}
+	memoryLimitStr := QEMUDefaultMemSize
+	if d.expandedConfig["limits.memory"] != "" {
+		memoryLimitStr = d.expandedConfig["limits.memory"]
+	}
+
+	memoryLimit, err := units.ParseByteSizeString(memoryLimitStr)
+	if err != nil {
+		return err
+	}
+
+	if stateDiskSize < memoryLimit {
+		return fmt.Errorf("The size.state of the root disk device (%s) is smaller than limits.memory (%s)", stateDiskSizeStr, memoryLimitStr)
 	}
 
 	return nil
 }
 
+// validateStateSave checks that the state of the running VM can be saved and restored later on.
+func (d *qemu) validateStateSave(monitor *qmp.Monitor) error {
+	// Hotplugged memory isn't part of the configuration the VM gets started with when restoring its state.
+	pluggedSizeBytes, err := monitor.GetPluggedMemorySizeBytes()
+	if err != nil {
+		return err
+	}
+
+	if pluggedSizeBytes > 0 {
+		return fmt.Errorf("Saving the state of a virtual machine with hotplugged memory isn't supported, restart it first")
+	}
+
+	return d.validateStateSize()
+}
+
 // Start starts the instance.
 func (d *qemu) Start(stateful bool) error {
 	unlock := d.updateBackupFileLock(context.Background())
@@ -1392,8 +1441,25 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		}
 	}
 
+	// Place the instance on NUMA nodes if requested.
+	cpuLimit := d.expandedConfig["limits.cpu"]
+	if d.expandedConfig["limits.cpu.nodes"] == "balanced" {
+		oldPlacement := map[string]string{
+			"volatile.cpu.nodes": d.localConfig["volatile.cpu.nodes"],
+			"volatile.cpu.set":   d.localConfig["volatile.cpu.set"],
+		}
+
+		cpuLimit, err = d.numaCPULimit(cpuLimit, stateful)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		revert.Add(func() { _ = d.VolatileSet(oldPlacement) })
+	}
+
 	// Get CPU information.
-	cpuInfo, err := d.cpuTopology(d.expandedConfig["limits.cpu"])
+	cpuInfo, err := d.cpuTopology(cpuLimit)
 	if err != nil {
 		return err
 	}
@@ -1447,6 +1513,13 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		"-D", d.LogFilePath(),
 	}
 
+	// Expose the graphical console over VNC too if QEMU supports it.
+	info := DriverStatuses()[instancetype.VM].Info
+	_, vncSupported := info.Features["vnc"]
+	if vncSupported {
+		qemuCmd = append(qemuCmd, "-vnc", fmt.Sprintf("unix:%s", d.vncPath()))
+	}
+
 	// If stateful, restore now.
 	if stateful {
 		if !d.stateful {
@@ -1757,6 +1830,11 @@ func (d *qemu) start(stateful bool, op *operationlock.InstanceOperation) error {
 		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(d, nil))
 	}
 
+	// Trigger a rebalance of the containers around the pinned vCPUs.
+	if cpuInfo.vcpus != nil {
+		cgroup.TaskSchedulerTrigger("virtual-machine", d.name, "started")
+	}
+
 	// The VM started cleanly so now enable the unexpected disconnection event to ensure the onStop hook is
 	// run if QMP unexpectedly disconnects.
 	monitor.SetOnDisconnectEvent(true)
@@ -1904,6 +1982,39 @@ func (d *qemu) advertiseVsockAddress() error {
 	return nil
 }
 
+// DumpGuestMemory writes a dump of the guest memory in the given format to the file.
+// The VM is paused for the duration of the dump and resumed afterwards if it was running.
+func (d *qemu) DumpGuestMemory(w *os.File, format string) error {
+	// Connect to the monitor.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err // The VM isn't running as no monitor socket available.
+	}
+
+	// Send the target file to qemu.
+	err = monitor.SendFile("memory-dump", w)
+	if err != nil {
+		return err
+	}
+
+	// Start the dump.
+	err = monitor.DumpGuestMemory("fd:memory-dump", format)
+	if err != nil {
+		_ = monitor.CloseFile("memory-dump")
+		return fmt.Errorf("Failed starting memory dump: %w", err)
+	}
+
+	d.logger.Debug("Memory dump started", logger.Ctx{"format": format})
+	defer d.logger.Debug("Memory dump finished", logger.Ctx{"format": format})
+
+	err = monitor.DumpWait()
+	if err != nil {
+		return fmt.Errorf("Failed dumping memory: %w", err)
+	}
+
+	return nil
+}
+
 // AgentCertificate returns the server certificate of the agent.
 func (d *qemu) AgentCertificate() *x509.Certificate {
 	agentCert := filepath.Join(d.Path(), "config", "agent.crt")
@@ -2107,6 +2218,14 @@ func (d *qemu) deviceStart(dev device.Device, instanceRunning bool) (*deviceConf
 				}
 			}
 
+			// Attach PCI passthrough device if requested.
+			if len(runConf.PCIDevice) > 0 {
+				err = d.deviceAttachPCI(dev.Name(), runConf.PCIDevice)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			// If running, run post start hooks now (if not, they will be run
 			// once the instance is started).
 			err = d.runHooks(runConf.PostHooks)
@@ -2277,6 +2396,14 @@ func (d *qemu) deviceStop(dev device.Device, instanceRunning bool, _ string) err
 			}
 		}
 
+		// Detach PCI passthrough device from running instance.
+		if configCopy["type"] == "pci" {
+			err = d.deviceDetachPCI(dev.Name())
+			if err != nil {
+				return err
+			}
+		}
+
 		// Detach disk from running instance.
 		if configCopy["type"] == "disk" {
 			err = d.deviceDetachBlockDevice(dev.Name(), configCopy)
@@ -2305,24 +2432,6 @@ func (d *qemu) deviceDetachNIC(deviceName string) error {
 		return err
 	}
 
-	// pciDeviceExists checks if the deviceID exists as a bridged PCI device.
-	pciDeviceExists := func(deviceID string) (bool, error) {
-		pciDevs, err := monitor.QueryPCI()
-		if err != nil {
-			return false, err
-		}
-
-		for _, pciDev := range pciDevs {
-			for _, bridgeDev := range pciDev.Bridge.Devices {
-				if bridgeDev.DevID == deviceID {
-					return true, nil
-				}
-			}
-		}
-
-		return false, nil
-	}
-
 	escapedDeviceName := linux.PathNameEncode(deviceName)
 	deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, escapedDeviceName)
 	netDevID := fmt.Sprintf("%s%s", qemuNetDevIDPrefix, escapedDeviceName)
@@ -2348,7 +2457,7 @@ func (d *qemu) deviceDetachNIC(deviceName string) error {
 		waitDuration := time.Duration(time.Second * time.Duration(10))
 		waitUntil := time.Now().Add(waitDuration)
 		for {
-			devExists, err := pciDeviceExists(deviceID)
+			devExists, err := d.pciDeviceExists(monitor, deviceID)
 			if err != nil {
 				return fmt.Errorf("Failed getting PCI devices to check for NIC detach: %w", err)
 			}
@@ -2369,6 +2478,169 @@ func (d *qemu) deviceDetachNIC(deviceName string) error {
 	return nil
 }
 
+// pciDeviceExists checks if the deviceID exists as a bridged PCI device.
+func (d *qemu) pciDeviceExists(monitor *qmp.Monitor, deviceID string) (bool, error) {
+	pciDevs, err := monitor.QueryPCI()
+	if err != nil {
+		return false, err
+	}
+
+	for _, pciDev := range pciDevs {
+		for _, bridgeDev := range pciDev.Bridge.Devices {
+			if bridgeDev.DevID == deviceID {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// pciFreePort returns the name of an unused PCIe root port to hotplug a device into.
+// The ports are searched from the last one so that the ports used by NICs at boot time are left alone.
+func (d *qemu) pciFreePort(monitor *qmp.Monitor) (string, error) {
+	pciDevs, err := monitor.QueryPCI()
+	if err != nil {
+		return "", err
+	}
+
+	return qemuPCIFreePort(pciDevs)
+}
+
+// qemuPCIFreePort returns the highest numbered PCIe root port without any device behind it.
+func qemuPCIFreePort(pciDevs []qmp.PCIDevice) (string, error) {
+	freePort := ""
+	freePortNum := -1
+	for _, pciDev := range pciDevs {
+		if !strings.HasPrefix(pciDev.DevID, busDevicePortPrefix) || len(pciDev.Bridge.Devices) > 0 {
+			continue
+		}
+
+		portNum, err := strconv.Atoi(strings.TrimPrefix(pciDev.DevID, busDevicePortPrefix))
+		if err != nil {
+			continue
+		}
+
+		if portNum > freePortNum {
+			freePort = pciDev.DevID
+			freePortNum = portNum
+		}
+	}
+
+	if freePort == "" {
+		return "", fmt.Errorf("No free PCIe port available, restart the instance to add the device")
+	}
+
+	return freePort, nil
+}
+
+// deviceAttachPCI live attaches a PCI passthrough device to the instance.
+func (d *qemu) deviceAttachPCI(deviceName string, pciConfig []deviceConfig.RunConfigItem) error {
+	var pciSlotName, pciIOMMUGroup string
+	for _, pciItem := range pciConfig {
+		if pciItem.Key == "pciSlotName" {
+			pciSlotName = pciItem.Value
+		} else if pciItem.Key == "pciIOMMUGroup" {
+			pciIOMMUGroup = pciItem.Value
+		}
+	}
+
+	_, qemuBus, err := d.qemuArchConfig(d.architecture)
+	if err != nil {
+		return err
+	}
+
+	if qemuBus != "pcie" {
+		return fmt.Errorf("PCI devices can only be hotplugged on a PCIe bus")
+	}
+
+	// Check if the agent is running.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	portName, err := d.pciFreePort(monitor)
+	if err != nil {
+		return err
+	}
+
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	// The QEMU process has already dropped its privileges, let it access the IOMMU group.
+	if d.state.OS.UnprivUser != "" {
+		if pciIOMMUGroup == "" {
+			return fmt.Errorf("No PCI IOMMU group supplied")
+		}
+
+		vfioGroupFile := fmt.Sprintf("/dev/vfio/%s", pciIOMMUGroup)
+		err := os.Chown(vfioGroupFile, int(d.state.OS.UnprivUID), -1)
+		if err != nil {
+			return fmt.Errorf("Failed to chown vfio group device %q: %w", vfioGroupFile, err)
+		}
+
+		reverter.Add(func() { _ = os.Chown(vfioGroupFile, 0, -1) })
+	}
+
+	d.logger.Debug("Using PCI bus device to hotplug PCI device into", logger.Ctx{"device": deviceName, "port": portName})
+
+	qemuDev := map[string]string{
+		"id":     fmt.Sprintf("%s%s", qemuDeviceIDPrefix, linux.PathNameEncode(deviceName)),
+		"driver": "vfio-pci",
+		"bus":    portName,
+		"addr":   "00.0",
+		"host":   pciSlotName,
+	}
+
+	err = monitor.AddDevice(qemuDev)
+	if err != nil {
+		return fmt.Errorf("Failed setting up device %q: %w", deviceName, err)
+	}
+
+	reverter.Success()
+	return nil
+}
+
+// deviceDetachPCI detaches a PCI passthrough device from a running instance.
+func (d *qemu) deviceDetachPCI(deviceName string) error {
+	// Check if the agent is running.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err
+	}
+
+	deviceID := fmt.Sprintf("%s%s", qemuDeviceIDPrefix, linux.PathNameEncode(deviceName))
+
+	err = monitor.RemoveDevice(deviceID)
+	if err != nil {
+		return fmt.Errorf("Failed removing PCI device: %w", err)
+	}
+
+	// Wait until the guest has released the device before handing it back to the host driver.
+	waitDuration := time.Duration(time.Second * time.Duration(10))
+	waitUntil := time.Now().Add(waitDuration)
+	for {
+		devExists, err := d.pciDeviceExists(monitor, deviceID)
+		if err != nil {
+			return fmt.Errorf("Failed getting PCI devices to check for PCI device detach: %w", err)
+		}
+
+		if !devExists {
+			break
+		}
+
+		if time.Now().After(waitUntil) {
+			return fmt.Errorf("Failed to detach PCI device after %v", waitDuration)
+		}
+
+		d.logger.Debug("Waiting for PCI device to be detached", logger.Ctx{"device": deviceName})
+		time.Sleep(time.Second * time.Duration(2))
+	}
+
+	return nil
+}
+
 func (d *qemu) monitorPath() string {
 	return filepath.Join(d.LogPath(), "qemu.monitor")
 }
@@ -2385,6 +2657,10 @@ func (d *qemu) spicePath() string {
 	return filepath.Join(d.LogPath(), "qemu.spice")
 }
 
+func (d *qemu) vncPath() string {
+	return filepath.Join(d.LogPath(), "qemu.vnc")
+}
+
 func (d *qemu) spiceCmdlineConfig() string {
 	return fmt.Sprintf("unix=on,disable-ticketing=on,addr=%s", d.spicePath())
 }
@@ -3344,8 +3620,14 @@ func (d *qemu) addCPUMemoryConfig(cfg *[]cfgSection, cpuInfo *cpuTopology) error
 	nodeMemory := int64(memSizeMB / int64(len(hostNodes)))
 	cpuOpts.memory = nodeMemory
 
+	// Determine the maximum memory size for memory hotplug.
+	maxSizeBytes, err := d.memoryHotplugMaxSizeBytes()
+	if err != nil {
+		return err
+	}
+
 	if cfg != nil {
-		*cfg = append(*cfg, qemuMemory(&qemuMemoryOpts{memSizeMB})...)
+		*cfg = append(*cfg, qemuMemory(&qemuMemoryOpts{memSizeMB, maxSizeBytes / 1024 / 1024, qemuMemoryHotplugSlots})...)
 		*cfg = append(*cfg, qemuCPU(&cpuOpts, cpuPinning)...)
 	}
 
@@ -4446,6 +4728,12 @@ func (d *qemu) Stop(stateful bool) error {
 
 	// Handle stateful stop.
 	if stateful {
+		err = d.validateStateSave(monitor)
+		if err != nil {
+			op.Done(err)
+			return fmt.Errorf("Unable to perform a stateful stop: %w", err)
+		}
+
 		// Dump the state.
 		err = d.saveState(monitor)
 		if err != nil {
@@ -4570,11 +4858,30 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 			return err
 		}
 
-		// Dump the state.
+		err = d.validateStateSave(monitor)
+		if err != nil {
+			return fmt.Errorf("Unable to create a stateful snapshot: %w", err)
+		}
+
+		// Dump the state into the config volume so it gets included in the snapshot.
 		err = d.saveState(monitor)
 		if err != nil {
+			_ = os.Remove(d.StatePath())
+			_ = monitor.Start()
 			return err
 		}
+
+		// Always remove the state from the main volume and resume the VM, even if the snapshot failed.
+		defer func() {
+			_ = os.Remove(d.StatePath())
+		}()
+
+		defer func() {
+			err := monitor.Start()
+			if err != nil {
+				d.logger.Error("Failed resuming instance after stateful snapshot", logger.Ctx{"err": err})
+			}
+		}()
 	}
 
 	// Create the snapshot.
@@ -4583,20 +4890,6 @@ func (d *qemu) snapshot(name string, expiry time.Time, stateful bool) error {
 		return err
 	}
 
-	// Resume the VM once the disk state has been saved.
-	if stateful {
-		// Remove the state from the main volume.
-		err = os.Remove(d.StatePath())
-		if err != nil {
-			return err
-		}
-
-		err = monitor.Start()
-		if err != nil {
-			return err
-		}
-	}
-
 	return nil
 }
 
@@ -4610,6 +4903,10 @@ func (d *qemu) Snapshot(name string, expiry time.Time, stateful bool) error {
 
 // Restore restores an instance snapshot.
 func (d *qemu) Restore(source instance.Instance, stateful bool) error {
+	if stateful && !source.IsStateful() {
+		return fmt.Errorf("Stateful restore requires a stateful snapshot")
+	}
+
 	op, err := operationlock.Create(d.Project().Name, d.Name(), operationlock.ActionRestore, false, false)
 	if err != nil {
 		return fmt.Errorf("Failed to create instance restore operation: %w", err)
@@ -4711,7 +5008,29 @@ func (d *qemu) Restore(source instance.Instance, stateful bool) error {
 		return err
 	}
 
+	// The restored config volume contains the snapshot's state if any.
+	// Only a stateful restore resumes the instance from it, otherwise it's discarded.
+	if !stateful && source.IsStateful() {
+		_, err = d.mount()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		err = os.Remove(d.StatePath())
+		_ = d.unmount()
+		if err != nil && !os.IsNotExist(err) {
+			op.Done(err)
+			return fmt.Errorf("Failed removing restored state: %w", err)
+		}
+	}
+
 	d.stateful = stateful
+	err = d.state.DB.Cluster.UpdateInstanceStatefulFlag(d.id, d.stateful)
+	if err != nil {
+		op.Done(err)
+		return fmt.Errorf("Error updating instance stateful flag: %w", err)
+	}
 
 	// Restart the instance.
 	if wasRunning || stateful {
@@ -5215,6 +5534,10 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 					}
 				}
 
+				if d.localConfig["volatile.cpu.set"] != "" {
+					return fmt.Errorf("Cannot update key %q when using NUMA placement and the VM is running", key)
+				}
+
 				// If the key is being unset, set it to default value.
 				if value == "" {
 					value = "1"
@@ -5229,6 +5552,15 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 				if err != nil {
 					return fmt.Errorf("Failed updating cpu limit: %w", err)
 				}
+
+				// Have the agent bring the new CPUs online in the guest.
+				oldLimit, _ := strconv.Atoi(oldValue)
+				if limit > oldLimit {
+					err = d.devIncusEventSend("cpu", map[string]any{"count": limit})
+					if err != nil {
+						d.logger.Warn("Failed notifying the agent of hotplugged CPUs", logger.Ctx{"err": err})
+					}
+				}
 			} else if key == "limits.memory" {
 				err = d.updateMemoryLimit(value)
 				if err != nil {
@@ -5354,7 +5686,97 @@ func (d *qemu) Update(args db.InstanceArgs, userRequested bool) error {
 	return nil
 }
 
-// updateMemoryLimit live updates the VM's memory limit by reszing the balloon device.
+// memoryHotplugMaxSizeBytes returns the maximum memory size the VM can reach through memory hotplug.
+// Returns 0 if memory hotplug isn't available.
+func (d *qemu) memoryHotplugMaxSizeBytes() (int64, error) {
+	value := d.expandedConfig["limits.memory.hotplug"]
+	if util.IsFalseOrEmpty(value) || util.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		return 0, nil
+	}
+
+	if !util.ValueInSlice(d.architecture, []int{osarch.ARCH_64BIT_INTEL_X86, osarch.ARCH_64BIT_ARMV8_LITTLE_ENDIAN}) {
+		return 0, nil
+	}
+
+	if util.IsTrue(value) {
+		memory, err := resources.GetMemory()
+		if err != nil {
+			return -1, fmt.Errorf("Failed getting host memory: %w", err)
+		}
+
+		return int64(memory.Total), nil
+	}
+
+	maxSizeBytes, err := units.ParseByteSizeString(value)
+	if err != nil {
+		return -1, fmt.Errorf("limits.memory.hotplug invalid: %w", err)
+	}
+
+	return maxSizeBytes, nil
+}
+
+// hotplugMemory adds memory to the running VM through a new DIMM device.
+func (d *qemu) hotplugMemory(monitor *qmp.Monitor, sizeBytes int64) error {
+	devices, err := monitor.QueryMemoryDevices()
+	if err != nil {
+		return err
+	}
+
+	if len(devices) >= qemuMemoryHotplugSlots {
+		return fmt.Errorf("No memory hotplug slots left (%d used)", len(devices))
+	}
+
+	// Find an unused device name.
+	usedIDs := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		usedIDs[device.ID] = true
+	}
+
+	var deviceID string
+	for i := 0; ; i++ {
+		deviceID = fmt.Sprintf("dimm%d", i)
+		if !usedIDs[deviceID] {
+			break
+		}
+	}
+
+	memDev := map[string]any{
+		"qom-type": "memory-backend-memfd",
+		"id":       fmt.Sprintf("mem-%s", deviceID),
+		"size":     sizeBytes,
+		"share":    true,
+	}
+
+	device := map[string]string{
+		"driver": "pc-dimm",
+		"id":     deviceID,
+		"memdev": fmt.Sprintf("mem-%s", deviceID),
+	}
+
+	return monitor.AddMemoryDevice(memDev, device)
+}
+
+// qemuMemoryHotplugSize returns the size of the memory device to hotplug for the VM to have at least newSizeBytes
+// of memory. The size is rounded up to the memory hotplug alignment, the balloon device then takes care of
+// giving the VM the exact size requested.
+func qemuMemoryHotplugSize(totalSizeBytes int64, newSizeBytes int64, maxSizeBytes int64) (int64, error) {
+	if newSizeBytes > maxSizeBytes {
+		return -1, fmt.Errorf("Cannot increase memory size beyond the memory hotplug limit when VM is running (Limit %dMiB, new size %dMiB)", maxSizeBytes/1024/1024, newSizeBytes/1024/1024)
+	}
+
+	sizeBytes := newSizeBytes - totalSizeBytes
+	if sizeBytes%qemuMemoryHotplugAlignment != 0 {
+		sizeBytes += qemuMemoryHotplugAlignment - sizeBytes%qemuMemoryHotplugAlignment
+	}
+
+	if totalSizeBytes+sizeBytes > maxSizeBytes {
+		return -1, fmt.Errorf("Cannot increase memory size to %dMiB when VM is running as memory can only be added in %dMiB increments up to the memory hotplug limit of %dMiB", newSizeBytes/1024/1024, qemuMemoryHotplugAlignment/1024/1024, maxSizeBytes/1024/1024)
+	}
+
+	return sizeBytes, nil
+}
+
+// updateMemoryLimit live updates the VM's memory limit by hotplugging memory and resizing the balloon device.
 func (d *qemu) updateMemoryLimit(newLimit string) error {
 	if newLimit == "" {
 		return nil
@@ -5383,7 +5805,13 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 		return err
 	}
 
+	pluggedSizeBytes, err := monitor.GetPluggedMemorySizeBytes()
+	if err != nil {
+		return err
+	}
+
 	baseSizeMB := baseSizeBytes / 1024 / 1024
+	totalSizeMB := (baseSizeBytes + pluggedSizeBytes) / 1024 / 1024
 
 	curSizeBytes, err := monitor.GetMemoryBalloonSizeBytes()
 	if err != nil {
@@ -5394,8 +5822,33 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 
 	if curSizeMB == newSizeMB {
 		return nil
-	} else if baseSizeMB < newSizeMB {
-		return fmt.Errorf("Cannot increase memory size beyond boot time size when VM is running (Boot time size %dMiB, new size %dMiB)", baseSizeMB, newSizeMB)
+	} else if totalSizeMB < newSizeMB {
+		// Add the missing memory to the VM if possible.
+		maxSizeBytes, err := d.memoryHotplugMaxSizeBytes()
+		if err != nil {
+			return err
+		}
+
+		maxSizeMB := maxSizeBytes / 1024 / 1024
+		if maxSizeMB <= baseSizeMB {
+			return fmt.Errorf("Cannot increase memory size beyond boot time size when VM is running (Boot time size %dMiB, new size %dMiB)", baseSizeMB, newSizeMB)
+		}
+
+		hotplugSizeBytes, err := qemuMemoryHotplugSize(baseSizeBytes+pluggedSizeBytes, newSizeBytes, maxSizeBytes)
+		if err != nil {
+			return err
+		}
+
+		err = d.hotplugMemory(monitor, hotplugSizeBytes)
+		if err != nil {
+			return fmt.Errorf("Failed adding memory: %w", err)
+		}
+
+		// Have the agent bring the new memory online in the guest.
+		err = d.devIncusEventSend("memory", map[string]any{"size": newSizeMB * 1024 * 1024})
+		if err != nil {
+			d.logger.Warn("Failed notifying the agent of hotplugged memory", logger.Ctx{"err": err})
+		}
 	}
 
 	// Set effective memory size.
@@ -5404,8 +5857,8 @@ func (d *qemu) updateMemoryLimit(newLimit string) error {
 		return err
 	}
 
-	// Changing the memory balloon can take time, so poll the effectice size to check it has shrunk within 1%
-	// of the target size, which we then take as success (it may still continue to shrink closer to target).
+	// Changing the memory balloon can take time, so poll the effectice size to check it has reached within 1%
+	// of the target size, which we then take as success (it may still continue to move closer to target).
 	for i := 0; i < 10; i++ {
 		curSizeBytes, err = monitor.GetMemoryBalloonSizeBytes()
 		if err != nil {
@@ -5695,7 +6148,7 @@ func (d *qemu) delete(force bool) error {
 }
 
 // Export publishes the instance.
-func (d *qemu) Export(w io.Writer, properties map[string]string, expiration time.Time) (api.ImageMetadata, error) {
+func (d *qemu) Export(w io.Writer, properties map[string]string, expiration time.Time, stateful bool) (api.ImageMetadata, error) {
 	ctxMap := logger.Ctx{
 		"created":   d.creationDate,
 		"ephemeral": d.ephemeral,
@@ -5703,6 +6156,10 @@ func (d *qemu) Export(w io.Writer, properties map[string]string, expiration time
 
 	meta := api.ImageMetadata{}
 
+	if stateful {
+		return meta, fmt.Errorf("Stateful export isn't supported for virtual machines")
+	}
+
 	if d.IsRunning() {
 		return meta, fmt.Errorf("Cannot export a running instance as an image")
 	}
@@ -7019,6 +7476,12 @@ func (d *qemu) Console(protocol string) (*os.File, chan error, error) {
 		path = d.consolePath()
 	case instance.ConsoleTypeVGA:
 		path = d.spicePath()
+	case instance.ConsoleTypeVNC:
+		if !util.PathExists(d.vncPath()) {
+			return nil, nil, api.StatusErrorf(http.StatusBadRequest, "VNC console isn't supported by this virtual machine")
+		}
+
+		path = d.vncPath()
 	default:
 		return nil, nil, fmt.Errorf("Unknown protocol %q", protocol)
 	}
@@ -7044,6 +7507,63 @@ func (d *qemu) Console(protocol string) (*os.File, chan error, error) {
 	return file, chDisconnect, nil
 }
 
+// ConsoleScreenshot writes a PNG screenshot of the VGA console to the file.
+func (d *qemu) ConsoleScreenshot(screenshotFile *os.File) error {
+	// Connect to the monitor.
+	monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+	if err != nil {
+		return err // The VM isn't running as no monitor socket available.
+	}
+
+	// QEMU can only write PNG screenshots since 7.1, older versions write PPM ones which are then converted.
+	format := "png"
+	qemuVer71, _ := version.NewDottedVersion("7.1")
+	qemuVer, _ := d.version()
+	if qemuVer == nil || qemuVer.Compare(qemuVer71) < 0 {
+		format = "ppm"
+	}
+
+	dumpFile := screenshotFile
+	if format == "ppm" {
+		dumpFile, err = os.CreateTemp("", "incus_screenshot_")
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			_ = dumpFile.Close()
+			_ = os.Remove(dumpFile.Name())
+		}()
+	}
+
+	// Pass the file to qemu as it may not be allowed to create files itself.
+	info, err := monitor.SendFileWithFDSet("screenshot", dumpFile, false)
+	if err != nil {
+		return fmt.Errorf("Failed sending screenshot file to QEMU: %w", err)
+	}
+
+	defer func() { _ = monitor.RemoveFDFromFDSet("screenshot") }()
+
+	err = monitor.Screendump(fmt.Sprintf("/dev/fdset/%d", info.ID), format)
+	if err != nil {
+		return fmt.Errorf("Failed taking screenshot: %w", err)
+	}
+
+	if format == "ppm" {
+		img, err := decodePPM(dumpFile)
+		if err != nil {
+			return fmt.Errorf("Failed reading screenshot: %w", err)
+		}
+
+		err = png.Encode(screenshotFile, img)
+		if err != nil {
+			return fmt.Errorf("Failed converting screenshot: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Exec a command inside the instance.
 func (d *qemu) Exec(req api.InstanceExecPost, stdin *os.File, stdout *os.File, stderr *os.File) (instance.Cmd, error) {
 	revert := revert.New()
@@ -7279,6 +7799,17 @@ func (d *qemu) renderState(statusCode api.StatusCode) (*api.InstanceState, error
 			}
 		}
 
+		// Fallback to the effective memory size of the VM if the agent didn't report it.
+		if status.Memory.Total == 0 {
+			monitor, err := qmp.Connect(d.monitorPath(), qemuSerialChardevName, d.getMonitorEventHandler())
+			if err == nil {
+				memSizeBytes, err := monitor.GetMemoryBalloonSizeBytes()
+				if err == nil {
+					status.Memory.Total = memSizeBytes
+				}
+			}
+		}
+
 		// Populate host_name for network devices.
 		for k, m := range d.ExpandedDevices() {
 			// We only care about nics.
@@ -7422,6 +7953,11 @@ func (d *qemu) DeviceEventHandler(runConf *deviceConfig.RunConfig) error {
 		switch fields[1] {
 		case "add":
 			for _, usbDev := range runConf.USBDevice {
+				// The device node may show up slightly after the kernel event.
+				for i := 0; i < 10 && !util.PathExists(usbDev.HostDevicePath); i++ {
+					time.Sleep(500 * time.Millisecond)
+				}
+
 				// This ensures that the device is actually removed from QEMU before adding it again.
 				// In most cases the device will already be removed, but it is possible that the
 				// device still exists in QEMU before trying to add it again.
@@ -7707,6 +8243,60 @@ func (d *qemu) UpdateBackupFile() error {
 	return pool.UpdateInstanceBackupFile(d, nil)
 }
 
+// numaCPULimit places a VM with limits.cpu.nodes set to "balanced" on NUMA nodes and returns the CPU set to pin
+// its vCPUs to. The placement is recorded in the volatile.cpu.nodes and volatile.cpu.set keys.
+func (d *qemu) numaCPULimit(limit string, stateful bool) (string, error) {
+	// The state can only be restored with the same CPU topology.
+	if stateful && d.localConfig["volatile.cpu.set"] != "" {
+		return d.localConfig["volatile.cpu.set"], nil
+	}
+
+	if limit == "" {
+		limit = "1"
+	}
+
+	count, err := strconv.Atoi(limit)
+	if err != nil {
+		d.logger.Warn("The pinned CPUs override the NUMA configuration", logger.Ctx{"cpus": limit})
+		return limit, nil
+	}
+
+	// Huge pages are allocated from the NUMA nodes the vCPUs are placed on.
+	var hugepages uint64
+	if util.IsTrue(d.expandedConfig["limits.memory.hugepages"]) {
+		memSize := QEMUDefaultMemSize
+		if d.expandedConfig["limits.memory"] != "" {
+			memSize = d.expandedConfig["limits.memory"]
+		}
+
+		memSizeBytes, err := units.ParseByteSizeString(memSize)
+		if err != nil {
+			return "", err
+		}
+
+		hugepages = uint64(memSizeBytes)
+	}
+
+	numaPlacementLock.Lock()
+	defer numaPlacementLock.Unlock()
+
+	placement, err := d.placeNUMA(count, hugepages)
+	if err != nil {
+		return "", err
+	}
+
+	cpuSet := formatCPUSet(placement.CPUs)
+	err = d.VolatileSet(map[string]string{
+		"volatile.cpu.nodes": formatNUMANodes(placement.Nodes),
+		"volatile.cpu.set":   cpuSet,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return cpuSet, nil
+}
+
 type cpuTopology struct {
 	sockets int
 	cores   int
@@ -8116,6 +8706,14 @@ func (d *qemu) checkFeatures(hostArch int, qemuPath string) (map[string]any, err
 		}
 	}
 
+	// Check VNC feature.
+	err = monitor.QueryVNC()
+	if err != nil {
+		logger.Debug("Failed querying VNC during VM feature check", logger.Ctx{"err": err})
+	} else {
+		features["vnc"] = struct{}{}
+	}
+
 	// Check if vhost-net accelerator (for NIC CPU offloading) is available.
 	if util.PathExists("/dev/vhost-net") {
 		features["vhost_net"] = struct{}{}
@@ -8290,6 +8888,25 @@ func (d *qemu) blockNodeName(name string) string {
 	return fmt.Sprintf("%s%s", qemuBlockDevIDPrefix, name)
 }
 
+// cpuDevice returns the device_add arguments for a hotpluggable CPU slot.
+func (d *qemu) cpuDevice(devID string, cpu qmp.HotpluggableCPU) map[string]string {
+	dev := map[string]string{
+		"id":      devID,
+		"driver":  cpu.Type,
+		"core-id": fmt.Sprintf("%d", cpu.Props.CoreID),
+	}
+
+	// No such thing as sockets and threads on s390x.
+	if d.architecture != osarch.ARCH_64BIT_S390_BIG_ENDIAN {
+		dev["socket-id"] = fmt.Sprintf("%d", cpu.Props.SocketID)
+		dev["thread-id"] = fmt.Sprintf("%d", cpu.Props.ThreadID)
+	}
+
+	return dev
+}
+
+// setCPUs hotplugs or hot-unplugs vCPUs until the VM has count vCPUs.
+// CPUs are added in topology order and removed in reverse order so the guest CPU numbering stays contiguous.
 func (d *qemu) setCPUs(count int) error {
 	if count == 0 {
 		return nil
@@ -8306,6 +8923,78 @@ func (d *qemu) setCPUs(count int) error {
 		return fmt.Errorf("Failed to query hotpluggable CPUs: %w", err)
 	}
 
+	addCPUs, removeCPUs, err := qemuCPUHotplugPlan(cpus, count)
+	if err != nil {
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// More CPUs requested.
+	if len(addCPUs) > 0 {
+		for _, cpu := range addCPUs {
+			devID := fmt.Sprintf("cpu%d%d%d", cpu.Props.SocketID, cpu.Props.CoreID, cpu.Props.ThreadID)
+
+			err := monitor.AddDevice(d.cpuDevice(devID, cpu))
+			if err != nil {
+				return fmt.Errorf("Failed to add device: %w", err)
+			}
+
+			revert.Add(func() {
+				err := monitor.RemoveDevice(devID)
+				if err != nil {
+					d.logger.Warn("Failed to remove CPU device", logger.Ctx{"device": devID, "err": err})
+				}
+			})
+		}
+
+		// Add the new vCPU threads to the core scheduling domain.
+		pids, err := monitor.GetCPUs()
+		if err != nil {
+			return err
+		}
+
+		err = d.setCoreSched(pids)
+		if err != nil {
+			return fmt.Errorf("Failed to allocate new core scheduling domain for vCPU threads: %w", err)
+		}
+	}
+
+	// Less CPUs requested, remove the most recently added ones first.
+	for _, cpu := range removeCPUs {
+		cpu := cpu
+		fields := strings.Split(cpu.QOMPath, "/")
+		devID := fields[len(fields)-1]
+
+		err := monitor.RemoveDevice(devID)
+		if err != nil {
+			return fmt.Errorf("Failed to remove CPU: %w", err)
+		}
+
+		// Plug the CPU back if anything fails from now on, including the guest not releasing it in time.
+		revert.Add(func() {
+			err := monitor.AddDevice(d.cpuDevice(devID, cpu))
+			if err != nil {
+				d.logger.Warn("Failed to add CPU device", logger.Ctx{"device": devID, "err": err})
+			}
+		})
+
+		// The guest must release the CPU before it goes away, wait for it.
+		err = waitCPUUnplug(monitor.QueryHotpluggableCPUs, cpu.QOMPath, qemuCPUUnplugTimeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+
+	return nil
+}
+
+// qemuCPUHotplugPlan returns the CPUs to hotplug or hot-unplug for the VM to have count vCPUs.
+// CPUs are added in topology order and removed in reverse order so the guest CPU numbering stays contiguous.
+func qemuCPUHotplugPlan(cpus []qmp.HotpluggableCPU, count int) ([]qmp.HotpluggableCPU, []qmp.HotpluggableCPU, error) {
 	var availableCPUs []qmp.HotpluggableCPU
 	var hotpluggedCPUs []qmp.HotpluggableCPU
 
@@ -8319,91 +9008,78 @@ func (d *qemu) setCPUs(count int) error {
 		}
 	}
 
-	// The reserved CPUs includes both the hotplugged CPUs as well as the fixed one.
-	totalReservedCPUs := len(hotpluggedCPUs) + 1
+	cpuLess := func(a qmp.CPUInstanceProperties, b qmp.CPUInstanceProperties) bool {
+		if a.SocketID != b.SocketID {
+			return a.SocketID < b.SocketID
+		}
 
-	// Nothing to do as the count matches the already reserved CPUs.
-	if count == totalReservedCPUs {
-		return nil
+		if a.CoreID != b.CoreID {
+			return a.CoreID < b.CoreID
+		}
+
+		return a.ThreadID < b.ThreadID
 	}
 
-	revert := revert.New()
-	defer revert.Fail()
+	sort.Slice(availableCPUs, func(i int, j int) bool { return cpuLess(availableCPUs[i].Props, availableCPUs[j].Props) })
+	sort.Slice(hotpluggedCPUs, func(i int, j int) bool { return cpuLess(hotpluggedCPUs[j].Props, hotpluggedCPUs[i].Props) })
+
+	// The reserved CPUs includes both the hotplugged CPUs as well as the fixed one.
+	totalReservedCPUs := len(hotpluggedCPUs) + 1
 
-	// More CPUs requested.
 	if count > totalReservedCPUs {
 		// Cannot allocate more CPUs than the system provides.
 		if count > len(cpus) {
-			return fmt.Errorf("Cannot allocate more CPUs than available")
+			return nil, nil, fmt.Errorf("Cannot allocate more CPUs than available (maximum %d)", len(cpus))
 		}
 
 		// This shouldn't trigger, but if it does, don't panic.
 		if count-totalReservedCPUs > len(availableCPUs) {
-			return fmt.Errorf("Unable to allocate more CPUs, not enough hotpluggable CPUs available")
+			return nil, nil, fmt.Errorf("Unable to allocate more CPUs, not enough hotpluggable CPUs available")
 		}
 
-		// Only allocate the difference in CPUs.
-		for i := 0; i < count-totalReservedCPUs; i++ {
-			cpu := availableCPUs[i]
-
-			devID := fmt.Sprintf("cpu%d%d%d", cpu.Props.SocketID, cpu.Props.CoreID, cpu.Props.ThreadID)
+		return availableCPUs[:count-totalReservedCPUs], nil, nil
+	}
 
-			dev := map[string]string{
-				"id":      devID,
-				"driver":  cpu.Type,
-				"core-id": fmt.Sprintf("%d", cpu.Props.CoreID),
-			}
+	if count < totalReservedCPUs {
+		// This shouldn't trigger, but if it does, don't panic.
+		if count < 1 {
+			return nil, nil, fmt.Errorf("Unable to remove CPUs, not enough hotpluggable CPUs available")
+		}
 
-			// No such thing as sockets and threads on s390x.
-			if d.architecture != osarch.ARCH_64BIT_S390_BIG_ENDIAN {
-				dev["socket-id"] = fmt.Sprintf("%d", cpu.Props.SocketID)
-				dev["thread-id"] = fmt.Sprintf("%d", cpu.Props.ThreadID)
-			}
+		return nil, hotpluggedCPUs[:totalReservedCPUs-count], nil
+	}
 
-			err := monitor.AddDevice(dev)
-			if err != nil {
-				return fmt.Errorf("Failed to add device: %w", err)
-			}
+	return nil, nil, nil
+}
 
-			revert.Add(func() {
-				err := monitor.RemoveDevice(devID)
-				d.logger.Warn("Failed to remove CPU device", logger.Ctx{"err": err})
-			})
-		}
-	} else {
-		if totalReservedCPUs-count > len(hotpluggedCPUs) {
-			// This shouldn't trigger, but if it does, don't panic.
-			return fmt.Errorf("Unable to remove CPUs, not enough hotpluggable CPUs available")
+// waitCPUUnplug waits for the guest to release the hot-unplugged CPU at qomPath.
+// The hotpluggable CPUs are listed through query.
+func waitCPUUnplug(query func() ([]qmp.HotpluggableCPU, error), qomPath string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		cpus, err := query()
+		if err != nil {
+			return fmt.Errorf("Failed to query hotpluggable CPUs: %w", err)
 		}
 
-		// Less CPUs requested.
-		for i := 0; i < totalReservedCPUs-count; i++ {
-			cpu := hotpluggedCPUs[i]
-
-			fields := strings.Split(cpu.QOMPath, "/")
-			devID := fields[len(fields)-1]
-
-			err := monitor.RemoveDevice(devID)
-			if err != nil {
-				return fmt.Errorf("Failed to remove CPU: %w", err)
+		found := false
+		for _, cpu := range cpus {
+			if cpu.QOMPath == qomPath {
+				found = true
+				break
 			}
+		}
 
-			revert.Add(func() {
-				err := monitor.AddDevice(map[string]string{
-					"id":        devID,
-					"driver":    cpu.Type,
-					"socket-id": fmt.Sprintf("%d", cpu.Props.SocketID),
-					"core-id":   fmt.Sprintf("%d", cpu.Props.CoreID),
-					"thread-id": fmt.Sprintf("%d", cpu.Props.ThreadID),
-				})
-				d.logger.Warn("Failed to add CPU device", logger.Ctx{"err": err})
-			})
+		if !found {
+			return nil
 		}
-	}
 
-	revert.Success()
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Timed out waiting for the guest to release CPU %q", qomPath)
+		}
 
-	return nil
+		time.Sleep(250 * time.Millisecond)
+	}
 }
 
 func (d *qemu) architectureSupportsCPUHotplug() bool {
@@ -98,15 +98,27 @@ func TestQemuConfigTemplates(t *testing.T) {
 			opts     qemuMemoryOpts
 			expected string
 		}{{
-			qemuMemoryOpts{4096},
+			qemuMemoryOpts{4096, 0, 0},
 			`# Memory
 			[memory]
 			size = "4096M"`,
 		}, {
-			qemuMemoryOpts{8192},
+			qemuMemoryOpts{8192, 0, 0},
 			`# Memory
 			[memory]
 			size = "8192M"`,
+		}, {
+			qemuMemoryOpts{2048, 65536, 8},
+			`# Memory
+			[memory]
+			size = "2048M"
+			slots = "8"
+			maxmem = "65536M"`,
+		}, {
+			qemuMemoryOpts{2048, 2048, 8},
+			`# Memory
+			[memory]
+			size = "2048M"`,
 		}}
 		for _, tc := range testCases {
 			runTest(tc.expected, qemuMemory(&tc.opts))
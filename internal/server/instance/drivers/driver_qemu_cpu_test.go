@@ -0,0 +1,109 @@
+package drivers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/internal/server/instance/drivers/qmp"
+)
+
+// cpuTestSlots returns 4 hotpluggable CPU slots, the first one being the boot CPU and the next plugged ones hotplugged.
+func cpuTestSlots(plugged int) []qmp.HotpluggableCPU {
+	cpus := []qmp.HotpluggableCPU{{QOMPath: "/machine/unattached/device[0]", Props: qmp.CPUInstanceProperties{CoreID: 0}}}
+
+	for core := 1; core < 4; core++ {
+		cpu := qmp.HotpluggableCPU{Props: qmp.CPUInstanceProperties{CoreID: core}}
+		if core <= plugged {
+			cpu.QOMPath = fmt.Sprintf("/machine/peripheral/cpu0%d0", core)
+		}
+
+		cpus = append(cpus, cpu)
+	}
+
+	// QEMU doesn't list the slots in topology order.
+	for i, j := 0, len(cpus)-1; i < j; i, j = i+1, j-1 {
+		cpus[i], cpus[j] = cpus[j], cpus[i]
+	}
+
+	return cpus
+}
+
+func TestQemuCPUHotplugPlan(t *testing.T) {
+	cores := func(cpus []qmp.HotpluggableCPU) []int {
+		ids := []int{}
+		for _, cpu := range cpus {
+			ids = append(ids, cpu.Props.CoreID)
+		}
+
+		return ids
+	}
+
+	tests := []struct {
+		name    string
+		plugged int
+		count   int
+		add     []int
+		remove  []int
+		err     string
+	}{
+		{name: "Unchanged", plugged: 1, count: 2, add: []int{}, remove: []int{}},
+		{name: "Add in topology order", plugged: 0, count: 3, add: []int{1, 2}, remove: []int{}},
+		{name: "Add to hotplugged", plugged: 1, count: 4, add: []int{2, 3}, remove: []int{}},
+		{name: "Remove in reverse order", plugged: 3, count: 2, add: []int{}, remove: []int{3, 2}},
+		{name: "Remove all hotplugged", plugged: 2, count: 1, add: []int{}, remove: []int{2, 1}},
+		{name: "Too many", plugged: 0, count: 5, err: "Cannot allocate more CPUs than available (maximum 4)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			add, remove, err := qemuCPUHotplugPlan(cpuTestSlots(test.plugged), test.count)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.add, cores(add))
+			assert.Equal(t, test.remove, cores(remove))
+		})
+	}
+}
+
+func TestWaitCPUUnplug(t *testing.T) {
+	qomPath := "/machine/peripheral/cpu010"
+
+	// The guest releases the CPU after a few queries.
+	queries := 0
+	query := func() ([]qmp.HotpluggableCPU, error) {
+		queries++
+		if queries < 3 {
+			return cpuTestSlots(1), nil
+		}
+
+		return cpuTestSlots(0), nil
+	}
+
+	err := waitCPUUnplug(query, qomPath, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 3, queries)
+
+	// The guest never releases the CPU.
+	query = func() ([]qmp.HotpluggableCPU, error) {
+		return cpuTestSlots(1), nil
+	}
+
+	err = waitCPUUnplug(query, qomPath, 0)
+	assert.EqualError(t, err, fmt.Sprintf("Timed out waiting for the guest to release CPU %q", qomPath))
+
+	// Query failures are returned.
+	query = func() ([]qmp.HotpluggableCPU, error) {
+		return nil, fmt.Errorf("Monitor is disconnected")
+	}
+
+	err = waitCPUUnplug(query, qomPath, time.Minute)
+	assert.EqualError(t, err, "Failed to query hotpluggable CPUs: Monitor is disconnected")
+}
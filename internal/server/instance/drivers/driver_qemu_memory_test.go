@@ -0,0 +1,78 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQemuMemoryHotplugSize(t *testing.T) {
+	const MiB = 1024 * 1024
+
+	tests := []struct {
+		name     string
+		total    int64
+		new      int64
+		max      int64
+		expected int64
+		err      string
+	}{
+		{
+			name:     "Aligned increment",
+			total:    1024 * MiB,
+			new:      2048 * MiB,
+			max:      4096 * MiB,
+			expected: 1024 * MiB,
+		},
+		{
+			name:     "Unaligned increment is rounded up",
+			total:    1024 * MiB,
+			new:      1124 * MiB,
+			max:      4096 * MiB,
+			expected: 128 * MiB,
+		},
+		{
+			name:     "Unaligned current size",
+			total:    1000 * MiB,
+			new:      1500 * MiB,
+			max:      4096 * MiB,
+			expected: 512 * MiB,
+		},
+		{
+			name:     "Up to the limit",
+			total:    1024 * MiB,
+			new:      4096 * MiB,
+			max:      4096 * MiB,
+			expected: 3072 * MiB,
+		},
+		{
+			name:  "Beyond the limit",
+			total: 1024 * MiB,
+			new:   5000 * MiB,
+			max:   4096 * MiB,
+			err:   "Cannot increase memory size beyond the memory hotplug limit when VM is running (Limit 4096MiB, new size 5000MiB)",
+		},
+		{
+			name:  "Rounding beyond the limit",
+			total: 1024 * MiB,
+			new:   4000 * MiB,
+			max:   4050 * MiB,
+			err:   "Cannot increase memory size to 4000MiB when VM is running as memory can only be added in 128MiB increments up to the memory hotplug limit of 4050MiB",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			size, err := qemuMemoryHotplugSize(test.total, test.new, test.max)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, size)
+			assert.Zero(t, size%qemuMemoryHotplugAlignment)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/internal/server/instance/drivers/qmp"
+)
+
+func TestQemuPCIFreePort(t *testing.T) {
+	port := func(name string, used bool) qmp.PCIDevice {
+		dev := qmp.PCIDevice{DevID: name}
+		if used {
+			dev.Bridge.Devices = []qmp.PCIDevice{{DevID: "dev-incus_eth0"}}
+		}
+
+		return dev
+	}
+
+	tests := []struct {
+		name     string
+		devices  []qmp.PCIDevice
+		expected string
+		err      string
+	}{
+		{
+			name:     "Highest free port",
+			devices:  []qmp.PCIDevice{port("qemu_pcie0", true), port("qemu_pcie4", false), port("qemu_pcie5", false), port("qemu_pcie6", true)},
+			expected: "qemu_pcie5",
+		},
+		{
+			name:     "Ports numbered past 9",
+			devices:  []qmp.PCIDevice{port("qemu_pcie9", false), port("qemu_pcie10", false), port("qemu_pcie2", false)},
+			expected: "qemu_pcie10",
+		},
+		{
+			name:     "Port freed by a removed device",
+			devices:  []qmp.PCIDevice{port("qemu_pcie4", false), port("qemu_pcie5", false), port("qemu_pcie6", false), port("qemu_pcie7", true)},
+			expected: "qemu_pcie6",
+		},
+		{
+			name:     "Other devices are ignored",
+			devices:  []qmp.PCIDevice{{DevID: "qemu_scsi"}, port("qemu_pcieX", false), port("qemu_pcie3", false)},
+			expected: "qemu_pcie3",
+		},
+		{
+			name:    "No free port",
+			devices: []qmp.PCIDevice{port("qemu_pcie0", true), port("qemu_pcie1", true)},
+			err:     "No free PCIe port available, restart the instance to add the device",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			portName, err := qemuPCIFreePort(test.devices)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, portName)
+		})
+	}
+}
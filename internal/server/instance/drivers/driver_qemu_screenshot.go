@@ -0,0 +1,91 @@
+package drivers
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// decodePPM decodes a binary PPM (P6) image as written by QEMU's screendump command.
+func decodePPM(r io.Reader) (image.Image, error) {
+	reader := bufio.NewReader(r)
+
+	// Read the header fields, skipping comments.
+	fields := make([]int, 0, 3)
+
+	var magic string
+	_, err := fmt.Fscan(reader, &magic)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading PPM header: %w", err)
+	}
+
+	if magic != "P6" {
+		return nil, fmt.Errorf("Unsupported PPM format %q", magic)
+	}
+
+	for len(fields) < 3 {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("Failed reading PPM header: %w", err)
+		}
+
+		if b == '#' {
+			_, err = reader.ReadString('\n')
+			if err != nil {
+				return nil, fmt.Errorf("Failed reading PPM header: %w", err)
+			}
+
+			continue
+		}
+
+		if b == ' ' || b == '\t' || b == '\n' || b == '\r' {
+			continue
+		}
+
+		err = reader.UnreadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		var value int
+		_, err = fmt.Fscan(reader, &value)
+		if err != nil {
+			return nil, fmt.Errorf("Failed reading PPM header: %w", err)
+		}
+
+		fields = append(fields, value)
+	}
+
+	width, height, maxValue := fields[0], fields[1], fields[2]
+	if width <= 0 || height <= 0 || maxValue <= 0 || maxValue > 255 {
+		return nil, fmt.Errorf("Unsupported PPM dimensions %dx%d (max value %d)", width, height, maxValue)
+	}
+
+	// A single whitespace character separates the header from the pixels.
+	_, err = reader.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading PPM header: %w", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	pixel := make([]byte, 3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			_, err = io.ReadFull(reader, pixel)
+			if err != nil {
+				return nil, fmt.Errorf("Failed reading PPM pixels: %w", err)
+			}
+
+			img.SetRGBA(x, y, color.RGBA{
+				R: uint8(int(pixel[0]) * 255 / maxValue),
+				G: uint8(int(pixel[1]) * 255 / maxValue),
+				B: uint8(int(pixel[2]) * 255 / maxValue),
+				A: 255,
+			})
+		}
+	}
+
+	return img, nil
+}
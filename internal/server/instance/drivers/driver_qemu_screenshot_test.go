@@ -0,0 +1,37 @@
+package drivers
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestDecodePPM(t *testing.T) {
+	data := append([]byte("P6\n# QEMU screendump\n2 1\n255\n"), 255, 0, 0, 0, 128, 255)
+
+	img, err := decodePPM(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 1 {
+		t.Fatalf("Unexpected image size %v", img.Bounds())
+	}
+
+	expected := []color.RGBA{{R: 255, A: 255}, {G: 128, B: 255, A: 255}}
+	for x, pixel := range expected {
+		if img.At(x, 0) != pixel {
+			t.Errorf("Unexpected pixel %d: %v (expected %v)", x, img.At(x, 0), pixel)
+		}
+	}
+
+	_, err = decodePPM(bytes.NewReader([]byte("P3\n1 1\n255\n0 0 0\n")))
+	if err == nil {
+		t.Error("Expected an error for an ASCII PPM image")
+	}
+
+	_, err = decodePPM(bytes.NewReader([]byte("P6\n2 2\n255\n\x00\x00\x00")))
+	if err == nil {
+		t.Error("Expected an error for a truncated PPM image")
+	}
+}
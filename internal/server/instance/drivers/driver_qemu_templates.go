@@ -115,14 +115,25 @@ func qemuBase(opts *qemuBaseOpts) []cfgSection {
 }
 
 type qemuMemoryOpts struct {
-	memSizeMB int64
+	memSizeMB    int64
+	maxSizeMB    int64
+	hotplugSlots int
 }
 
 func qemuMemory(opts *qemuMemoryOpts) []cfgSection {
+	entries := []cfgEntry{{key: "size", value: fmt.Sprintf("%dM", opts.memSizeMB)}}
+
+	if opts.maxSizeMB > opts.memSizeMB && opts.hotplugSlots > 0 {
+		entries = append(entries, []cfgEntry{
+			{key: "slots", value: fmt.Sprintf("%d", opts.hotplugSlots)},
+			{key: "maxmem", value: fmt.Sprintf("%dM", opts.maxSizeMB)},
+		}...)
+	}
+
 	return []cfgSection{{
 		name:    "memory",
 		comment: "Memory",
-		entries: []cfgEntry{{key: "size", value: fmt.Sprintf("%dM", opts.memSizeMB)}},
+		entries: entries,
 	}}
 }
 
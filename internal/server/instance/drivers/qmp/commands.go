@@ -321,6 +321,81 @@ func (m *Monitor) MigrateWait(state string) error {
 	}
 }
 
+// Screendump writes a screenshot of the primary display to the file.
+// The format (png or ppm) is only passed to QEMU when not ppm, as older versions only support ppm.
+func (m *Monitor) Screendump(filename string, format string) error {
+	args := map[string]string{
+		"filename": filename,
+	}
+
+	if format != "" && format != "ppm" {
+		args["format"] = format
+	}
+
+	err := m.run("screendump", args, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// QueryVNC checks that QEMU was built with VNC support.
+func (m *Monitor) QueryVNC() error {
+	err := m.run("query-vnc", nil, nil)
+	if err != nil {
+		return fmt.Errorf("Failed to query VNC: %w", err)
+	}
+
+	return nil
+}
+
+// DumpGuestMemory starts dumping the guest memory to the URI in the given format.
+func (m *Monitor) DumpGuestMemory(uri string, format string) error {
+	args := map[string]any{
+		"paging":   false,
+		"protocol": uri,
+		"format":   format,
+		"detach":   true,
+	}
+
+	err := m.run("dump-guest-memory", args, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DumpWait waits until the guest memory dump completes.
+// Returns an error if the dump fails.
+func (m *Monitor) DumpWait() error {
+	// Wait until it completes or fails.
+	for {
+		// Prepare the response.
+		var resp struct {
+			Return struct {
+				Status string `json:"status"`
+			} `json:"return"`
+		}
+
+		err := m.run("query-dump", nil, &resp)
+		if err != nil {
+			return err
+		}
+
+		if resp.Return.Status == "failed" {
+			return fmt.Errorf("Dump guest memory call failed")
+		}
+
+		if resp.Return.Status == "completed" {
+			return nil
+		}
+
+		time.Sleep(1 * time.Second)
+	}
+}
+
 // MigrateContinue continues a migration stream.
 func (m *Monitor) MigrateContinue(fromState string) error {
 	var args struct {
@@ -465,6 +540,86 @@ func (m *Monitor) SetMemoryBalloonSizeBytes(sizeBytes int64) error {
 	return m.run("balloon", args, nil)
 }
 
+// GetPluggedMemorySizeBytes returns the size of the hotplugged memory in bytes.
+func (m *Monitor) GetPluggedMemorySizeBytes() (int64, error) {
+	// Prepare the response.
+	var resp struct {
+		Return struct {
+			PluggedMemory int64 `json:"plugged-memory"`
+		} `json:"return"`
+	}
+
+	err := m.run("query-memory-size-summary", nil, &resp)
+	if err != nil {
+		return -1, err
+	}
+
+	return resp.Return.PluggedMemory, nil
+}
+
+// MemoryDevice contains information about a hotplugged memory device.
+type MemoryDevice struct {
+	ID   string `json:"id"`
+	Size int64  `json:"size"`
+	Slot int    `json:"slot"`
+	Node int    `json:"node"`
+}
+
+// QueryMemoryDevices returns the hotplugged memory devices.
+func (m *Monitor) QueryMemoryDevices() ([]MemoryDevice, error) {
+	// Prepare the response.
+	var resp struct {
+		Return []struct {
+			Type string       `json:"type"`
+			Data MemoryDevice `json:"data"`
+		} `json:"return"`
+	}
+
+	err := m.run("query-memory-devices", nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to query memory devices: %w", err)
+	}
+
+	devices := []MemoryDevice{}
+	for _, device := range resp.Return {
+		if device.Type != "dimm" {
+			continue
+		}
+
+		devices = append(devices, device.Data)
+	}
+
+	return devices, nil
+}
+
+// AddMemoryDevice adds a memory backend object and plugs it into the VM through a DIMM device.
+func (m *Monitor) AddMemoryDevice(memDev map[string]any, device map[string]string) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	memDevID, ok := memDev["id"].(string)
+	if !ok {
+		return fmt.Errorf("Memory backend ID must be a string")
+	}
+
+	err := m.run("object-add", memDev, nil)
+	if err != nil {
+		return fmt.Errorf("Failed adding memory backend: %w", err)
+	}
+
+	revert.Add(func() {
+		_ = m.run("object-del", map[string]string{"id": memDevID}, nil)
+	})
+
+	err = m.AddDevice(device)
+	if err != nil {
+		return fmt.Errorf("Failed adding memory device: %w", err)
+	}
+
+	revert.Success()
+	return nil
+}
+
 // AddBlockDevice adds a block device.
 func (m *Monitor) AddBlockDevice(blockDev map[string]any, device map[string]string) error {
 	revert := revert.New()
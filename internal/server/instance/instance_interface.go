@@ -37,6 +37,7 @@ const HookStop = "onstop"
 const (
 	ConsoleTypeConsole = "console"
 	ConsoleTypeVGA     = "vga"
+	ConsoleTypeVNC     = "vnc"
 )
 
 // TemplateTrigger trigger name.
@@ -97,7 +98,7 @@ type Instance interface {
 	Update(newConfig db.InstanceArgs, userRequested bool) error
 
 	Delete(force bool) error
-	Export(w io.Writer, properties map[string]string, expiration time.Time) (api.ImageMetadata, error)
+	Export(w io.Writer, properties map[string]string, expiration time.Time, stateful bool) (api.ImageMetadata, error)
 
 	// Live configuration.
 	CGroup() (*cgroup.CGroup, error)
@@ -180,6 +181,7 @@ type Container interface {
 	InsertSeccompUnixDevice(prefix string, m deviceConfig.Device, pid int) error
 	DevptsFd() (*os.File, error)
 	IdmappedStorage(path string, fstype string) idmap.IdmapStorageType
+	CheckLiveMigration() error
 }
 
 // VM interface is for VM specific functions.
@@ -187,6 +189,8 @@ type VM interface {
 	Instance
 
 	AgentCertificate() *x509.Certificate
+	DumpGuestMemory(w *os.File, format string) error
+	ConsoleScreenshot(screenshotFile *os.File) error
 }
 
 // CriuMigrationArgs arguments for CRIU migration.
@@ -0,0 +1,132 @@
+package conntrack
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/lxc/incus/shared/subprocess"
+)
+
+// Entry represents a connection tracking entry.
+// The original direction is the one of the packet that created the entry, the reply direction is the opposite one.
+type Entry struct {
+	Protocol        string
+	Source          net.IP
+	Destination     net.IP
+	SourcePort      uint64
+	DestinationPort uint64
+
+	// Source of the reply direction, differs from Destination when the connection was destination NATed.
+	ReplySource     net.IP
+	ReplySourcePort uint64
+
+	OriginalPackets uint64
+	OriginalBytes   uint64
+	ReplyPackets    uint64
+	ReplyBytes      uint64
+}
+
+// Installed returns true if the conntrack tool is installed.
+func Installed() bool {
+	_, err := exec.LookPath("conntrack")
+	return err == nil
+}
+
+// Dump returns all the connection tracking entries of the host for the given family ("ipv4" or "ipv6").
+// Packet and byte counters are only populated when the nf_conntrack_acct sysctl is enabled.
+func Dump(family string) ([]Entry, error) {
+	output, err := subprocess.RunCommand("conntrack", "-L", "-f", family)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing connection tracking entries: %w", err)
+	}
+
+	entries := []Entry{}
+	for _, line := range strings.Split(output, "\n") {
+		entry := parseEntry(line)
+		if entry == nil {
+			continue
+		}
+
+		entries = append(entries, *entry)
+	}
+
+	return entries, nil
+}
+
+// parseEntry parses a line of conntrack output, returning nil if it doesn't describe a connection.
+func parseEntry(line string) *Entry {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return nil
+	}
+
+	entry := Entry{
+		Protocol: fields[0],
+	}
+
+	// Keys appear once for the original direction and then a second time for the reply direction,
+	// starting with a second "src" key.
+	reply := false
+	for _, field := range fields[1:] {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+
+		if key == "src" && entry.Source != nil {
+			reply = true
+		}
+
+		switch key {
+		case "src":
+			if reply {
+				entry.ReplySource = net.ParseIP(value)
+			} else {
+				entry.Source = net.ParseIP(value)
+			}
+
+		case "dst":
+			if !reply {
+				entry.Destination = net.ParseIP(value)
+			}
+
+		case "sport":
+			port, _ := strconv.ParseUint(value, 10, 16)
+			if reply {
+				entry.ReplySourcePort = port
+			} else {
+				entry.SourcePort = port
+			}
+
+		case "dport":
+			if !reply {
+				entry.DestinationPort, _ = strconv.ParseUint(value, 10, 16)
+			}
+
+		case "packets":
+			packets, _ := strconv.ParseUint(value, 10, 64)
+			if reply {
+				entry.ReplyPackets = packets
+			} else {
+				entry.OriginalPackets = packets
+			}
+
+		case "bytes":
+			bytes, _ := strconv.ParseUint(value, 10, 64)
+			if reply {
+				entry.ReplyBytes = bytes
+			} else {
+				entry.OriginalBytes = bytes
+			}
+		}
+	}
+
+	if entry.Source == nil || entry.Destination == nil {
+		return nil
+	}
+
+	return &entry
+}
@@ -0,0 +1,105 @@
+package conntrack
+
+import (
+	"net"
+	"testing"
+)
+
+func Test_parseEntry(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected *Entry
+	}{
+		{
+			line: "tcp      6 431999 ESTABLISHED src=10.0.0.2 dst=1.1.1.1 sport=50000 dport=443 packets=10 bytes=1000 src=1.1.1.1 dst=192.0.2.1 sport=443 dport=50000 packets=8 bytes=800 [ASSURED] mark=0 use=1",
+			expected: &Entry{
+				Protocol:        "tcp",
+				Source:          net.ParseIP("10.0.0.2"),
+				Destination:     net.ParseIP("1.1.1.1"),
+				SourcePort:      50000,
+				DestinationPort: 443,
+				ReplySource:     net.ParseIP("1.1.1.1"),
+				ReplySourcePort: 443,
+				OriginalPackets: 10,
+				OriginalBytes:   1000,
+				ReplyPackets:    8,
+				ReplyBytes:      800,
+			},
+		},
+		{
+			line: "udp      17 29 src=fd42::2 dst=fd42::1 sport=40000 dport=53 packets=1 bytes=76 [UNREPLIED] src=fd42::1 dst=fd42::2 sport=53 dport=40000 packets=0 bytes=0 mark=0 use=1",
+			expected: &Entry{
+				Protocol:        "udp",
+				Source:          net.ParseIP("fd42::2"),
+				Destination:     net.ParseIP("fd42::1"),
+				SourcePort:      40000,
+				DestinationPort: 53,
+				ReplySource:     net.ParseIP("fd42::1"),
+				ReplySourcePort: 53,
+				OriginalPackets: 1,
+				OriginalBytes:   76,
+			},
+		},
+		{
+			line: "icmp     1 29 src=10.0.0.2 dst=8.8.8.8 type=8 code=0 id=1 packets=1 bytes=84 src=8.8.8.8 dst=10.0.0.2 type=0 code=0 id=1 packets=1 bytes=84 mark=0 use=1",
+			expected: &Entry{
+				Protocol:        "icmp",
+				Source:          net.ParseIP("10.0.0.2"),
+				Destination:     net.ParseIP("8.8.8.8"),
+				ReplySource:     net.ParseIP("8.8.8.8"),
+				OriginalPackets: 1,
+				OriginalBytes:   84,
+				ReplyPackets:    1,
+				ReplyBytes:      84,
+			},
+		},
+		{
+			line: "tcp      6 117 TIME_WAIT src=198.51.100.7 dst=192.0.2.1 sport=41000 dport=8080 packets=6 bytes=420 src=10.0.0.5 dst=198.51.100.7 sport=80 dport=41000 packets=4 bytes=1310 [ASSURED] mark=0 use=1",
+			expected: &Entry{
+				Protocol:        "tcp",
+				Source:          net.ParseIP("198.51.100.7"),
+				Destination:     net.ParseIP("192.0.2.1"),
+				SourcePort:      41000,
+				DestinationPort: 8080,
+				ReplySource:     net.ParseIP("10.0.0.5"),
+				ReplySourcePort: 80,
+				OriginalPackets: 6,
+				OriginalBytes:   420,
+				ReplyPackets:    4,
+				ReplyBytes:      1310,
+			},
+		},
+		{
+			line:     "conntrack v1.4.6 (conntrack-tools): 3 flow entries have been shown.",
+			expected: nil,
+		},
+		{
+			line:     "",
+			expected: nil,
+		},
+	}
+
+	for i, test := range tests {
+		entry := parseEntry(test.line)
+		if test.expected == nil {
+			if entry != nil {
+				t.Errorf("Test %d: expected no entry, got %+v", i, entry)
+			}
+
+			continue
+		}
+
+		if entry == nil {
+			t.Errorf("Test %d: expected an entry, got none", i)
+			continue
+		}
+
+		if entry.Protocol != test.expected.Protocol || !entry.Source.Equal(test.expected.Source) || !entry.Destination.Equal(test.expected.Destination) || entry.SourcePort != test.expected.SourcePort || entry.DestinationPort != test.expected.DestinationPort || !entry.ReplySource.Equal(test.expected.ReplySource) || entry.ReplySourcePort != test.expected.ReplySourcePort {
+			t.Errorf("Test %d: unexpected tuple %+v", i, entry)
+		}
+
+		if entry.OriginalPackets != test.expected.OriginalPackets || entry.OriginalBytes != test.expected.OriginalBytes || entry.ReplyPackets != test.expected.ReplyPackets || entry.ReplyBytes != test.expected.ReplyBytes {
+			t.Errorf("Test %d: unexpected counters %+v", i, entry)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package dhcpv6
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Client and server ports (RFC 8415 section 7.2).
+const (
+	clientPort = 546
+	serverPort = 547
+)
+
+// allServers is the All_DHCP_Relay_Agents_and_Servers multicast address.
+var allServers = net.ParseIP("ff02::1:2")
+
+// retransmitTimeouts are the successive timeouts used when waiting for a response.
+var retransmitTimeouts = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+
+// ErrNoResponse is returned when no server responded to the request.
+var ErrNoResponse = errors.New("No response from DHCPv6 server")
+
+// Lease represents a prefix delegated by a DHCPv6 server.
+type Lease struct {
+	Prefix   *net.IPNet
+	ServerID []byte
+
+	// Time after which the lease should be renewed with the server that delegated it.
+	T1 time.Duration
+
+	// Time after which the lease should be renewed with any server.
+	T2 time.Duration
+
+	PreferredLifetime time.Duration
+	ValidLifetime     time.Duration
+}
+
+// Client requests prefixes through DHCPv6 prefix delegation (RFC 8415).
+type Client struct {
+	// Interface to send the requests on.
+	Interface string
+
+	// DHCP Unique Identifier of the client.
+	DUID []byte
+
+	// Identifier of the prefix delegation identity association.
+	IAID uint32
+
+	// Prefix length to request from the server (0 to let the server decide).
+	PrefixLength int
+}
+
+// NewDUID returns a DUID-UUID (RFC 6355) derived from the seed.
+// This makes the client identity stable for as long as the seed doesn't change, regardless of the host.
+func NewDUID(seed string) []byte {
+	sum := sha256.Sum256([]byte(seed))
+
+	duid := binary.BigEndian.AppendUint16(nil, 4)
+	return append(duid, sum[:16]...)
+}
+
+// Solicit looks for a server and requests a prefix from it.
+func (c *Client) Solicit(ctx context.Context) (*Lease, error) {
+	conn, err := c.listen()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	ia := &iaPD{iaid: c.IAID}
+	if c.PrefixLength > 0 {
+		ia.prefixes = []iaPrefix{{prefix: &net.IPNet{IP: net.IPv6zero, Mask: net.CIDRMask(c.PrefixLength, 128)}}}
+	}
+
+	// Find a server.
+	advertise, err := c.exchange(ctx, conn, msgSolicit, msgAdvertise, []option{{code: optIAPD, data: ia.marshal()}})
+	if err != nil {
+		return nil, err
+	}
+
+	serverID := advertise.option(optServerID)
+	if serverID == nil {
+		return nil, fmt.Errorf("DHCPv6 advertise is missing the server identifier")
+	}
+
+	advertised, err := c.leaseIA(advertise)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request the advertised prefix.
+	reply, err := c.exchange(ctx, conn, msgRequest, msgReply, []option{
+		{code: optServerID, data: serverID},
+		{code: optIAPD, data: advertised.marshal()},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.lease(reply)
+}
+
+// Renew extends the lease with the server that delegated it.
+func (c *Client) Renew(ctx context.Context, lease *Lease) (*Lease, error) {
+	conn, err := c.listen()
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	ia := &iaPD{iaid: c.IAID, prefixes: []iaPrefix{{prefix: lease.Prefix}}}
+
+	reply, err := c.exchange(ctx, conn, msgRenew, msgReply, []option{
+		{code: optServerID, data: lease.ServerID},
+		{code: optIAPD, data: ia.marshal()},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return c.lease(reply)
+}
+
+// Release gives the prefix back to the server that delegated it.
+// The server's response isn't waited for.
+func (c *Client) Release(lease *Lease) error {
+	conn, err := c.listen()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = conn.Close() }()
+
+	ia := &iaPD{iaid: c.IAID, prefixes: []iaPrefix{{prefix: lease.Prefix}}}
+
+	msg, err := c.newMessage(msgRelease, []option{
+		{code: optServerID, data: lease.ServerID},
+		{code: optIAPD, data: ia.marshal()},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.WriteToUDP(msg.marshal(), &net.UDPAddr{IP: allServers, Port: serverPort, Zone: c.Interface})
+	if err != nil {
+		return fmt.Errorf("Failed sending DHCPv6 release: %w", err)
+	}
+
+	return nil
+}
+
+// listen opens the client socket on the link-local address of the interface.
+func (c *Client) listen() (*net.UDPConn, error) {
+	iface, err := net.InterfaceByName(c.Interface)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() != nil || !ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+
+		conn, err := net.ListenUDP("udp6", &net.UDPAddr{IP: ipNet.IP, Port: clientPort, Zone: c.Interface})
+		if err != nil {
+			return nil, fmt.Errorf("Failed opening DHCPv6 client socket on %q: %w", c.Interface, err)
+		}
+
+		return conn, nil
+	}
+
+	return nil, fmt.Errorf("Interface %q doesn't have an IPv6 link-local address", c.Interface)
+}
+
+// newMessage returns a new client message with a random transaction ID and the client identifier.
+func (c *Client) newMessage(msgType uint8, options []option) (*message, error) {
+	msg := &message{msgType: msgType}
+
+	_, err := rand.Read(msg.transactionID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	msg.options = append([]option{
+		{code: optClientID, data: c.DUID},
+		{code: optElapsedTime, data: []byte{0, 0}},
+	}, options...)
+
+	return msg, nil
+}
+
+// exchange sends a message and waits for a response of the expected type, retransmitting as needed.
+func (c *Client) exchange(ctx context.Context, conn *net.UDPConn, msgType uint8, replyType uint8, options []option) (*message, error) {
+	msg, err := c.newMessage(msgType, options)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 1500)
+	for _, timeout := range retransmitTimeouts {
+		_, err = conn.WriteToUDP(msg.marshal(), &net.UDPAddr{IP: allServers, Port: serverPort, Zone: c.Interface})
+		if err != nil {
+			return nil, fmt.Errorf("Failed sending DHCPv6 message: %w", err)
+		}
+
+		deadline := time.Now().Add(timeout)
+		ctxDeadline, ok := ctx.Deadline()
+		if ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+
+		err = conn.SetReadDeadline(deadline)
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+
+				return nil, err
+			}
+
+			reply, err := parseMessage(buf[:n])
+			if err != nil || reply.msgType != replyType || reply.transactionID != msg.transactionID {
+				continue
+			}
+
+			return reply, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, ErrNoResponse
+}
+
+// leaseIA returns the IA_PD option of a server message, checking that it contains a prefix.
+func (c *Client) leaseIA(msg *message) (*iaPD, error) {
+	status, statusMessage := parseStatus(msg.option(optStatusCode))
+	if status != statusSuccess {
+		return nil, fmt.Errorf("DHCPv6 server returned status %d: %s", status, statusMessage)
+	}
+
+	for _, opt := range msg.options {
+		if opt.code != optIAPD {
+			continue
+		}
+
+		ia, err := parseIAPD(opt.data)
+		if err != nil {
+			return nil, err
+		}
+
+		if ia.iaid != c.IAID {
+			continue
+		}
+
+		if ia.status != statusSuccess {
+			return nil, fmt.Errorf("DHCPv6 server returned status %d: %s", ia.status, ia.statusMessage)
+		}
+
+		if len(ia.prefixes) == 0 {
+			return nil, fmt.Errorf("DHCPv6 server returned status %d: No prefix delegated", statusNoPrefix)
+		}
+
+		return ia, nil
+	}
+
+	return nil, fmt.Errorf("DHCPv6 server didn't delegate a prefix")
+}
+
+// lease returns the lease described by a server reply.
+func (c *Client) lease(reply *message) (*Lease, error) {
+	ia, err := c.leaseIA(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := ia.prefixes[0]
+
+	lease := &Lease{
+		Prefix:            prefix.prefix,
+		ServerID:          reply.option(optServerID),
+		T1:                time.Duration(ia.t1) * time.Second,
+		T2:                time.Duration(ia.t2) * time.Second,
+		PreferredLifetime: time.Duration(prefix.preferredLifetime) * time.Second,
+		ValidLifetime:     time.Duration(prefix.validLifetime) * time.Second,
+	}
+
+	// Servers can leave the renewal times to the client (RFC 8415 section 21.21).
+	if lease.T1 == 0 {
+		lease.T1 = lease.PreferredLifetime / 2
+	}
+
+	if lease.T2 == 0 {
+		lease.T2 = lease.PreferredLifetime * 4 / 5
+	}
+
+	return lease, nil
+}
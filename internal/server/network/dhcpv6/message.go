@@ -0,0 +1,207 @@
+package dhcpv6
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// DHCPv6 message types (RFC 8415 section 7.3).
+const (
+	msgSolicit   uint8 = 1
+	msgAdvertise uint8 = 2
+	msgRequest   uint8 = 3
+	msgRenew     uint8 = 5
+	msgReply     uint8 = 7
+	msgRelease   uint8 = 8
+)
+
+// DHCPv6 option codes (RFC 8415 section 21).
+const (
+	optClientID    uint16 = 1
+	optServerID    uint16 = 2
+	optElapsedTime uint16 = 8
+	optStatusCode  uint16 = 13
+	optIAPD        uint16 = 25
+	optIAPrefix    uint16 = 26
+)
+
+// DHCPv6 status codes (RFC 8415 section 21.13).
+const (
+	statusSuccess  uint16 = 0
+	statusNoPrefix uint16 = 6
+)
+
+// option represents a DHCPv6 option.
+type option struct {
+	code uint16
+	data []byte
+}
+
+// message represents a DHCPv6 client/server message.
+type message struct {
+	msgType       uint8
+	transactionID [3]byte
+	options       []option
+}
+
+// marshal returns the wire format of the message.
+func (m *message) marshal() []byte {
+	buf := []byte{m.msgType, m.transactionID[0], m.transactionID[1], m.transactionID[2]}
+	return append(buf, marshalOptions(m.options)...)
+}
+
+// option returns the data of the first option with the given code, or nil if not present.
+func (m *message) option(code uint16) []byte {
+	for _, opt := range m.options {
+		if opt.code == code {
+			return opt.data
+		}
+	}
+
+	return nil
+}
+
+// parseMessage parses a DHCPv6 message in wire format.
+func parseMessage(buf []byte) (*message, error) {
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("Message too short")
+	}
+
+	m := &message{msgType: buf[0]}
+	copy(m.transactionID[:], buf[1:4])
+
+	var err error
+	m.options, err = parseOptions(buf[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// marshalOptions returns the wire format of a list of options.
+func marshalOptions(options []option) []byte {
+	buf := []byte{}
+	for _, opt := range options {
+		buf = binary.BigEndian.AppendUint16(buf, opt.code)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(opt.data)))
+		buf = append(buf, opt.data...)
+	}
+
+	return buf
+}
+
+// parseOptions parses a list of options in wire format.
+func parseOptions(buf []byte) ([]option, error) {
+	options := []option{}
+	for len(buf) > 0 {
+		if len(buf) < 4 {
+			return nil, fmt.Errorf("Truncated option header")
+		}
+
+		code := binary.BigEndian.Uint16(buf[0:2])
+		length := int(binary.BigEndian.Uint16(buf[2:4]))
+		if len(buf) < 4+length {
+			return nil, fmt.Errorf("Truncated option %d", code)
+		}
+
+		options = append(options, option{code: code, data: buf[4 : 4+length]})
+		buf = buf[4+length:]
+	}
+
+	return options, nil
+}
+
+// iaPrefix represents an IA Prefix option.
+type iaPrefix struct {
+	preferredLifetime uint32
+	validLifetime     uint32
+	prefix            *net.IPNet
+}
+
+// iaPD represents an Identity Association for Prefix Delegation option.
+type iaPD struct {
+	iaid     uint32
+	t1       uint32
+	t2       uint32
+	prefixes []iaPrefix
+
+	// Status code of the option, if any.
+	status        uint16
+	statusMessage string
+}
+
+// marshal returns the wire format of the IA_PD option data.
+func (ia *iaPD) marshal() []byte {
+	buf := binary.BigEndian.AppendUint32(nil, ia.iaid)
+	buf = binary.BigEndian.AppendUint32(buf, ia.t1)
+	buf = binary.BigEndian.AppendUint32(buf, ia.t2)
+
+	options := []option{}
+	for _, prefix := range ia.prefixes {
+		ones, _ := prefix.prefix.Mask.Size()
+
+		data := binary.BigEndian.AppendUint32(nil, prefix.preferredLifetime)
+		data = binary.BigEndian.AppendUint32(data, prefix.validLifetime)
+		data = append(data, uint8(ones))
+		data = append(data, prefix.prefix.IP.To16()...)
+
+		options = append(options, option{code: optIAPrefix, data: data})
+	}
+
+	return append(buf, marshalOptions(options)...)
+}
+
+// parseIAPD parses the IA_PD option data.
+func parseIAPD(buf []byte) (*iaPD, error) {
+	if len(buf) < 12 {
+		return nil, fmt.Errorf("IA_PD option too short")
+	}
+
+	ia := &iaPD{
+		iaid: binary.BigEndian.Uint32(buf[0:4]),
+		t1:   binary.BigEndian.Uint32(buf[4:8]),
+		t2:   binary.BigEndian.Uint32(buf[8:12]),
+	}
+
+	options, err := parseOptions(buf[12:])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range options {
+		switch opt.code {
+		case optIAPrefix:
+			if len(opt.data) < 25 {
+				return nil, fmt.Errorf("IA Prefix option too short")
+			}
+
+			prefixLength := int(opt.data[8])
+			if prefixLength > 128 {
+				return nil, fmt.Errorf("Invalid delegated prefix length %d", prefixLength)
+			}
+
+			mask := net.CIDRMask(prefixLength, 128)
+			ia.prefixes = append(ia.prefixes, iaPrefix{
+				preferredLifetime: binary.BigEndian.Uint32(opt.data[0:4]),
+				validLifetime:     binary.BigEndian.Uint32(opt.data[4:8]),
+				prefix:            &net.IPNet{IP: net.IP(opt.data[9:25]).Mask(mask), Mask: mask},
+			})
+
+		case optStatusCode:
+			ia.status, ia.statusMessage = parseStatus(opt.data)
+		}
+	}
+
+	return ia, nil
+}
+
+// parseStatus parses the status code option data.
+func parseStatus(buf []byte) (uint16, string) {
+	if len(buf) < 2 {
+		return statusSuccess, ""
+	}
+
+	return binary.BigEndian.Uint16(buf[0:2]), string(buf[2:])
+}
@@ -0,0 +1,64 @@
+package dhcpv6
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_messageRoundTrip(t *testing.T) {
+	_, prefix, _ := net.ParseCIDR("2001:db8:1200::/56")
+
+	ia := &iaPD{
+		iaid:     42,
+		t1:       1800,
+		t2:       2880,
+		prefixes: []iaPrefix{{preferredLifetime: 3600, validLifetime: 7200, prefix: prefix}},
+	}
+
+	msg := &message{
+		msgType:       msgReply,
+		transactionID: [3]byte{1, 2, 3},
+		options: []option{
+			{code: optServerID, data: []byte{0, 4, 1, 2}},
+			{code: optIAPD, data: ia.marshal()},
+		},
+	}
+
+	parsed, err := parseMessage(msg.marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.msgType != msgReply || parsed.transactionID != msg.transactionID {
+		t.Fatalf("Unexpected message header %d %v", parsed.msgType, parsed.transactionID)
+	}
+
+	client := &Client{IAID: 42}
+	lease, err := client.lease(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lease.Prefix.String() != "2001:db8:1200::/56" {
+		t.Fatalf("Unexpected prefix %q", lease.Prefix.String())
+	}
+
+	if lease.T1 != 30*time.Minute || lease.ValidLifetime != 2*time.Hour || string(lease.ServerID) != string([]byte{0, 4, 1, 2}) {
+		t.Fatalf("Unexpected lease %+v", lease)
+	}
+
+	// Replies for another identity association don't delegate anything to us.
+	client.IAID = 43
+	_, err = client.lease(parsed)
+	if err == nil {
+		t.Fatal("Expected an error for a reply without our identity association")
+	}
+}
+
+func Test_parseMessageTruncated(t *testing.T) {
+	_, err := parseMessage([]byte{msgReply, 1, 2, 3, 0, 2, 0, 10, 1})
+	if err == nil {
+		t.Fatal("Expected an error for a truncated option")
+	}
+}
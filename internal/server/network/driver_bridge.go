@@ -28,6 +28,7 @@ import (
 	firewallDrivers "github.com/lxc/incus/internal/server/firewall/drivers"
 	"github.com/lxc/incus/internal/server/ip"
 	"github.com/lxc/incus/internal/server/network/acl"
+	"github.com/lxc/incus/internal/server/network/conntrack"
 	"github.com/lxc/incus/internal/server/network/openvswitch"
 	"github.com/lxc/incus/internal/server/project"
 	localUtil "github.com/lxc/incus/internal/server/util"
@@ -58,6 +59,7 @@ func (n *bridge) DBType() db.NetworkType {
 func (n *bridge) Info() Info {
 	info := n.common.Info()
 	info.AddressForwards = true
+	info.FlowLogging = true
 
 	return info
 }
@@ -91,7 +93,7 @@ func (n *bridge) FillConfig(config map[string]string) error {
 		config["ipv4.nat"] = "true"
 	}
 
-	if config["ipv6.address"] == "" {
+	if config["ipv6.address"] == "" && util.IsFalseOrEmpty(config["ipv6.prefix_delegation.request"]) {
 		content, err := os.ReadFile("/proc/sys/net/ipv6/conf/default/disable_ipv6")
 		if err == nil && string(content) == "0\n" {
 			config["ipv6.address"] = "auto"
@@ -176,6 +178,8 @@ func (n *bridge) Validate(config map[string]string) error {
 		}),
 		"bridge.hwaddr": validate.Optional(validate.IsNetworkMAC),
 		"bridge.mtu":    validate.Optional(validate.IsNetworkMTU),
+		"network":       validate.Optional(validate.IsNetworkName),
+		"flows.logging": validate.Optional(validate.IsBool),
 
 		"ipv4.address": validate.Optional(func(value string) error {
 			if validate.IsOneOf("none", "auto")(value) == nil {
@@ -276,6 +280,11 @@ func (n *bridge) Validate(config map[string]string) error {
 		rules[k] = v
 	}
 
+	// Add the prefix delegation validation rules.
+	for k, v := range prefixDelegationUplinkRules() {
+		rules[k] = v
+	}
+
 	// Validate the configuration.
 	err = n.validate(config, rules)
 	if err != nil {
@@ -311,6 +320,17 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// The address of bridges requesting a prefix comes from the delegated prefix.
+	if util.IsTrue(config["ipv6.prefix_delegation.request"]) {
+		if !util.ValueInSlice(config["ipv6.address"], []string{"", "none"}) {
+			return fmt.Errorf(`"ipv6.address" can't be set when "ipv6.prefix_delegation.request" is enabled`)
+		}
+
+		if util.IsTrue(config["ipv6.dhcp.stateful"]) {
+			return fmt.Errorf(`"ipv6.dhcp.stateful" can't be enabled when "ipv6.prefix_delegation.request" is enabled`)
+		}
+	}
+
 	// Check using same MAC address on every cluster node is safe.
 	if config["bridge.hwaddr"] != "" {
 		err = n.checkClusterWideMACSafe(config)
@@ -395,6 +415,18 @@ func (n *bridge) Validate(config map[string]string) error {
 		}
 	}
 
+	// Check uplink network is suitable (must be in default project).
+	if config["network"] != "" {
+		uplinkNet, err := LoadByName(n.state, project.Default, config["network"])
+		if err != nil {
+			return fmt.Errorf("Failed loading uplink network %q: %w", config["network"], err)
+		}
+
+		if uplinkNet.Type() != "wireguard" {
+			return fmt.Errorf("Network type %q unsupported as bridge uplink", uplinkNet.Type())
+		}
+	}
+
 	return nil
 }
 
@@ -624,7 +656,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	}
 
 	// IPv6 bridge configuration.
-	if !util.ValueInSlice(n.config["ipv6.address"], []string{"", "none"}) {
+	if !util.ValueInSlice(n.ipv6Address(), []string{"", "none"}) {
 		if !util.PathExists("/proc/sys/net/ipv6") {
 			return fmt.Errorf("Network has ipv6.address but kernel IPv6 support is missing")
 		}
@@ -803,6 +835,20 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Enable connection tracking accounting for flow logging.
+	if util.IsTrue(n.config["flows.logging"]) {
+		if !conntrack.Installed() {
+			n.logger.Warn("The conntrack tool is required for flow logging")
+		}
+
+		err = localUtil.SysctlSet("net/netfilter/nf_conntrack_acct", "1")
+		if err != nil {
+			return err
+		}
+	} else {
+		flowLogDelete(ProjectNetwork{ProjectName: n.project, NetworkName: n.name})
+	}
+
 	// Configure IPv4 firewall.
 	if !util.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) {
 		if n.hasDHCPv4() && n.hasIPv4Firewall() {
@@ -919,9 +965,11 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 				srcIP = net.ParseIP(n.config["ipv4.nat.address"])
 			}
 
+			// Traffic routed through the uplink network keeps the instance addresses.
 			fwOpts.SNATV4 = &firewallDrivers.SNATOpts{
 				SNATAddress: srcIP,
 				Subnet:      subnet,
+				ExcludeDev:  n.config["network"],
 			}
 
 			if n.config["ipv4.nat.order"] == "after" {
@@ -982,7 +1030,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	}
 
 	// Configure IPv6.
-	if !util.ValueInSlice(n.config["ipv6.address"], []string{"", "none"}) {
+	if !util.ValueInSlice(n.ipv6Address(), []string{"", "none"}) {
 		// Enable IPv6 for the subnet.
 		err := localUtil.SysctlSet(fmt.Sprintf("net/ipv6/conf/%s/disable_ipv6", n.name), "0")
 		if err != nil {
@@ -990,7 +1038,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 
 		// Parse the subnet.
-		ipAddress, subnet, err := net.ParseCIDR(n.config["ipv6.address"])
+		ipAddress, subnet, err := net.ParseCIDR(n.ipv6Address())
 		if err != nil {
 			return fmt.Errorf("Failed parsing ipv6.address: %w", err)
 		}
@@ -1081,7 +1129,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		// Add the address.
 		addr := &ip.Addr{
 			DevName: n.name,
-			Address: n.config["ipv6.address"],
+			Address: n.ipv6Address(),
 			Family:  ip.FamilyV6,
 		}
 
@@ -1101,6 +1149,7 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			fwOpts.SNATV6 = &firewallDrivers.SNATOpts{
 				SNATAddress: srcIP,
 				Subnet:      subnet,
+				ExcludeDev:  n.config["network"],
 			}
 
 			if n.config["ipv6.nat.order"] == "after" {
@@ -1464,6 +1513,9 @@ func (n *bridge) Stop() error {
 		}
 	}
 
+	// Clear the flow log.
+	flowLogDelete(ProjectNetwork{ProjectName: n.project, NetworkName: n.name})
+
 	// Kill any existing dnsmasq daemon for this network
 	err = dnsmasq.Kill(n.name, false)
 	if err != nil {
@@ -1679,10 +1731,20 @@ func (n *bridge) hasIPv4Firewall() bool {
 	return false
 }
 
+// ipv6Address returns the IPv6 address of the bridge.
+// Bridges requesting a prefix use the first subnet of the prefix delegated to the local member.
+func (n *bridge) ipv6Address() string {
+	if util.IsTrue(n.config["ipv6.prefix_delegation.request"]) {
+		return prefixDelegationAddress(n.config)
+	}
+
+	return n.config["ipv6.address"]
+}
+
 // hasIPv6Firewall indicates whether the network has IPv6 firewall enabled.
 func (n *bridge) hasIPv6Firewall() bool {
 	// IPv6 firewall is only enabled if there is a bridge ipv6.address and ipv6.firewall enabled.
-	if !util.ValueInSlice(n.config["ipv6.address"], []string{"", "none"}) && util.IsTrueOrEmpty(n.config["ipv6.firewall"]) {
+	if !util.ValueInSlice(n.ipv6Address(), []string{"", "none"}) && util.IsTrueOrEmpty(n.config["ipv6.firewall"]) {
 		return true
 	}
 
@@ -1724,7 +1786,7 @@ func (n *bridge) DHCPv6Subnet() *net.IPNet {
 		return nil
 	}
 
-	_, subnet, err := net.ParseCIDR(n.config["ipv6.address"])
+	_, subnet, err := net.ParseCIDR(n.ipv6Address())
 	if err != nil {
 		return nil
 	}
@@ -2330,7 +2392,7 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 		// If requested project matches network's project then include gateway and downstream uplink IPs.
 		if projectName == n.project {
 			// Add our own gateway IPs.
-			for _, addr := range []string{n.config["ipv4.address"], n.config["ipv6.address"]} {
+			for _, addr := range []string{n.config["ipv4.address"], n.ipv6Address()} {
 				ip, _, _ := net.ParseCIDR(addr)
 				if ip != nil {
 					leases = append(leases, api.NetworkLease{
@@ -2411,7 +2473,7 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 			}
 
 			// Add EUI64 records.
-			_, netIP6, _ := net.ParseCIDR(n.config["ipv6.address"])
+			_, netIP6, _ := net.ParseCIDR(n.ipv6Address())
 			if netIP6 != nil && hwAddr != nil && util.IsFalseOrEmpty(n.config["ipv6.dhcp.stateful"]) {
 				eui64IP6, err := eui64.ParseMAC(netIP6.IP, hwAddr)
 				if err == nil {
@@ -2523,5 +2585,176 @@ func (n *bridge) Leases(projectName string, clientType request.ClientType) ([]ap
 
 // UsesDNSMasq indicates if network's config indicates if it needs to use dnsmasq.
 func (n *bridge) UsesDNSMasq() bool {
-	return !util.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) || !util.ValueInSlice(n.config["ipv6.address"], []string{"", "none"})
+	return !util.ValueInSlice(n.config["ipv4.address"], []string{"", "none"}) || !util.ValueInSlice(n.ipv6Address(), []string{"", "none"})
+}
+
+// Flows returns the connections recorded by the flow log of the network.
+func (n *bridge) Flows(projectName string, clientType request.ClientType) ([]api.NetworkFlow, error) {
+	flows := []api.NetworkFlow{}
+
+	// Connections are visible from the projects of the instances on either end.
+	// Connections not involving any instance are only visible from the network's project.
+	for _, flow := range flowLogGet(ProjectNetwork{ProjectName: n.project, NetworkName: n.name}) {
+		if clientType == request.ClientTypeNormal && !util.ValueInSlice(projectName, flowProjects(flow, n.project)) {
+			continue
+		}
+
+		flow.Location = n.state.ServerName
+		flows = append(flows, flow)
+	}
+
+	// Collect flows from other servers.
+	if clientType == request.ClientTypeNormal {
+		notifier, err := cluster.NewNotifier(n.state, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return nil, err
+		}
+
+		err = notifier(func(client incus.InstanceServer) error {
+			memberFlows, err := client.GetNetworkFlows(n.name)
+			if err != nil {
+				return err
+			}
+
+			for _, flow := range memberFlows {
+				if !util.ValueInSlice(projectName, flowProjects(flow, n.project)) {
+					continue
+				}
+
+				flows = append(flows, flow)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return flows, nil
+}
+
+// FlowsUpdate records the connections going through the network into its flow log and sends an event for
+// each of the connections that saw traffic since the last update.
+func (n *bridge) FlowsUpdate() error {
+	pn := ProjectNetwork{ProjectName: n.project, NetworkName: n.name}
+
+	if util.IsFalseOrEmpty(n.config["flows.logging"]) || !n.isRunning() {
+		flowLogDelete(pn)
+		return nil
+	}
+
+	// Get the subnets of the network and the connections involving them.
+	subnets := []*net.IPNet{}
+	entries := []conntrack.Entry{}
+	for family, address := range map[string]string{"ipv4": n.config["ipv4.address"], "ipv6": n.ipv6Address()} {
+		_, subnet, err := net.ParseCIDR(address)
+		if err != nil {
+			continue
+		}
+
+		familyEntries, err := conntrack.Dump(family)
+		if err != nil {
+			return err
+		}
+
+		subnets = append(subnets, subnet)
+		entries = append(entries, familyEntries...)
+	}
+
+	// Map the addresses to the instances connected to the network.
+	addresses := map[string]flowInstance{}
+	macs := map[string]flowInstance{}
+	_, netIP6, _ := net.ParseCIDR(n.ipv6Address())
+	err := UsedByInstanceDevices(n.state, n.project, n.name, n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		instance := flowInstance{project: inst.Project, name: inst.Name}
+
+		for _, key := range []string{"ipv4.address", "ipv6.address"} {
+			nicIP := net.ParseIP(nicConfig[key])
+			if nicIP != nil {
+				addresses[nicIP.String()] = instance
+			}
+		}
+
+		hwAddr, _ := net.ParseMAC(inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)])
+		if nicConfig["hwaddr"] != "" {
+			hwAddr, _ = net.ParseMAC(nicConfig["hwaddr"])
+		}
+
+		if hwAddr != nil {
+			macs[hwAddr.String()] = instance
+
+			// Add SLAAC addresses.
+			if netIP6 != nil {
+				eui64IP6, err := eui64.ParseMAC(netIP6.IP, hwAddr)
+				if err == nil {
+					addresses[eui64IP6.String()] = instance
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Add the dynamic leases.
+	leases, err := n.Leases("", request.ClientTypeNotifier)
+	if err != nil {
+		return err
+	}
+
+	for _, lease := range leases {
+		instance, found := macs[lease.Hwaddr]
+		if found {
+			addresses[lease.Address] = instance
+		}
+	}
+
+	// Update the flow log and send the changes as events.
+	for _, flow := range flowLogUpdate(pn, entries, subnets, addresses) {
+		event := api.EventLogging{
+			Level:   "info",
+			Message: "Network flow",
+			Context: map[string]string{
+				"network":          n.name,
+				"protocol":         flow.Protocol,
+				"source":           flow.Source,
+				"source_port":      fmt.Sprintf("%d", flow.SourcePort),
+				"destination":      flow.Destination,
+				"destination_port": fmt.Sprintf("%d", flow.DestinationPort),
+				"bytes_sent":       fmt.Sprintf("%d", flow.BytesSent),
+				"bytes_received":   fmt.Sprintf("%d", flow.BytesReceived),
+				"packets_sent":     fmt.Sprintf("%d", flow.PacketsSent),
+				"packets_received": fmt.Sprintf("%d", flow.PacketsReceived),
+			},
+		}
+
+		if flow.SourceInstance != "" {
+			event.Context["source_instance"] = flow.SourceInstance
+		}
+
+		if flow.DestinationInstance != "" {
+			event.Context["destination_instance"] = flow.DestinationInstance
+		}
+
+		if flow.SourceProject != "" {
+			event.Context["source_project"] = flow.SourceProject
+		}
+
+		if flow.DestinationProject != "" {
+			event.Context["destination_project"] = flow.DestinationProject
+		}
+
+		// Send the event to the projects of the instances on both ends of the connection.
+		for _, eventProject := range flowProjects(flow, n.project) {
+			err = n.state.Events.Send(eventProject, api.EventTypeNetworkFlow, event)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
 }
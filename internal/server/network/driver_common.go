@@ -35,6 +35,7 @@ type Info struct {
 	AddressForwards    bool // Indicates if driver supports address forwards.
 	LoadBalancers      bool // Indicates if driver supports load balancers.
 	Peering            bool // Indicates if the driver supports network peering.
+	FlowLogging        bool // Indicates if the driver supports flow logging.
 }
 
 // forwardTarget represents a single port forward target.
@@ -1373,6 +1374,16 @@ func (n *common) Leases(projectName string, clientType request.ClientType) ([]ap
 	return nil, ErrNotImplemented
 }
 
+// Flows returns ErrNotImplemented for drivers that don't support flow logging.
+func (n *common) Flows(projectName string, clientType request.ClientType) ([]api.NetworkFlow, error) {
+	return nil, ErrNotImplemented
+}
+
+// FlowsUpdate returns ErrNotImplemented for drivers that don't support flow logging.
+func (n *common) FlowsUpdate() error {
+	return ErrNotImplemented
+}
+
 // PeerCrete returns ErrNotImplemented for drivers that do not support forwards.
 func (n *common) PeerCreate(forward api.NetworkPeersPost) error {
 	return ErrNotImplemented
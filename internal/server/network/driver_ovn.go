@@ -178,6 +178,14 @@ func (n *ovn) uplinkRoutes(uplink *api.Network) ([]*net.IPNet, error) {
 		}
 	}
 
+	// Include the prefix delegated to the uplink network, if any.
+	if uplink.Type == "physical" && util.IsTrue(uplink.Config["ipv6.prefix_delegation.request"]) && uplink.Config[pdVolatilePrefix] != "" {
+		uplinkRoutes, err = SubnetParseAppend(uplinkRoutes, uplink.Config[pdVolatilePrefix])
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return uplinkRoutes, nil
 }
 
@@ -318,6 +326,7 @@ func (n *ovn) Validate(config map[string]string) error {
 		}),
 		"ipv6.dhcp":                            validate.Optional(validate.IsBool),
 		"ipv6.dhcp.stateful":                   validate.Optional(validate.IsBool),
+		"ipv6.prefix_delegation.allocate":      validate.Optional(validate.IsBool),
 		"ipv4.nat":                             validate.Optional(validate.IsBool),
 		"ipv4.nat.address":                     validate.Optional(validate.IsNetworkAddressV4),
 		"ipv6.nat":                             validate.Optional(validate.IsBool),
@@ -338,6 +347,7 @@ func (n *ovn) Validate(config map[string]string) error {
 		// Volatile keys populated automatically as needed.
 		ovnVolatileUplinkIPv4: validate.Optional(validate.IsNetworkAddressV4),
 		ovnVolatileUplinkIPv6: validate.Optional(validate.IsNetworkAddressV6),
+		pdVolatileSubnet:      validate.Optional(validate.IsNetworkV6),
 	}
 
 	err := n.validate(config, rules)
@@ -347,6 +357,30 @@ func (n *ovn) Validate(config map[string]string) error {
 
 	// Peform composite key checks after per-key validation.
 
+	// Each cluster member obtains its own delegated prefix, so there's no single prefix to allocate from.
+	if util.IsTrue(config["ipv6.prefix_delegation.allocate"]) && n.state != nil {
+		clustered, err := cluster.Enabled(n.state.DB.Node)
+		if err != nil {
+			return err
+		}
+
+		if clustered {
+			return fmt.Errorf(`"ipv6.prefix_delegation.allocate" isn't supported on clustered servers`)
+		}
+
+		// Bridge networks use their delegated prefix for themselves, so only physical uplinks hand out subnets.
+		if config["network"] != "" {
+			uplinkNet, err := LoadByName(n.state, project.Default, config["network"])
+			if err != nil {
+				return fmt.Errorf("Failed loading uplink network %q: %w", config["network"], err)
+			}
+
+			if uplinkNet.Type() != "physical" {
+				return fmt.Errorf(`"ipv6.prefix_delegation.allocate" requires a physical uplink network`)
+			}
+		}
+	}
+
 	// Validate DNS zone names.
 	err = n.validateZoneNames(config)
 	if err != nil {
@@ -1773,6 +1807,13 @@ func (n *ovn) validateUplinkNetwork(p *api.Project, uplinkNetworkName string) (s
 	}
 
 	if uplinkNetworkName != "" {
+		// OVN needs a layer 2 uplink, which WireGuard can't provide. WireGuard networks can only be reached
+		// through a bridge network using them as its uplink.
+		uplinkNet, err := LoadByName(n.state, project.Default, uplinkNetworkName)
+		if err == nil && uplinkNet.Type() == "wireguard" {
+			return "", fmt.Errorf(`Option "network" value %q is a WireGuard network, which can't be used as an OVN uplink as it doesn't carry layer 2 traffic (use a bridge network with the WireGuard network as its uplink instead)`, uplinkNetworkName)
+		}
+
 		if !util.ValueInSlice(uplinkNetworkName, allowedUplinkNetworks) {
 			return "", fmt.Errorf(`Option "network" value %q is not one of the allowed uplink networks in project`, uplinkNetworkName)
 		}
@@ -57,6 +57,11 @@ func (n *physical) Validate(config map[string]string) error {
 		rules[k] = v
 	}
 
+	// Add the prefix delegation validation rules.
+	for k, v := range prefixDelegationUplinkRules() {
+		rules[k] = v
+	}
+
 	// Validate the configuration.
 	err = n.validate(config, rules)
 	if err != nil {
@@ -0,0 +1,662 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	internalInstance "github.com/lxc/incus/internal/instance"
+	"github.com/lxc/incus/internal/revert"
+	"github.com/lxc/incus/internal/server/cluster/request"
+	"github.com/lxc/incus/internal/server/db"
+	"github.com/lxc/incus/internal/server/ip"
+	"github.com/lxc/incus/internal/server/network/acl"
+	networkWireguard "github.com/lxc/incus/internal/server/network/wireguard"
+	localUtil "github.com/lxc/incus/internal/server/util"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+	"github.com/lxc/incus/shared/util"
+	"github.com/lxc/incus/shared/validate"
+)
+
+// wireguardDefaultListenPort is the UDP port used when "wireguard.listen_port" isn't set.
+const wireguardDefaultListenPort = 51820
+
+// wireguardDefaultMTU is the MTU used when "mtu" isn't set.
+// This leaves room for the WireGuard encapsulation over an IPv6 1500 bytes MTU path.
+const wireguardDefaultMTU = 1420
+
+// wireguard represents a WireGuard network.
+type wireguard struct {
+	common
+}
+
+// DBType returns the network type DB ID.
+func (n *wireguard) DBType() db.NetworkType {
+	return db.NetworkTypeWireguard
+}
+
+// Info returns the network driver info.
+func (n *wireguard) Info() Info {
+	info := n.common.Info()
+	info.Peering = true
+
+	return info
+}
+
+// FillConfig fills requested config with any default values.
+func (n *wireguard) FillConfig(config map[string]string) error {
+	// Generate the private key of the interface if not provided.
+	if config["wireguard.private_key"] == "" {
+		privateKey, err := networkWireguard.GenerateKey()
+		if err != nil {
+			return err
+		}
+
+		config["wireguard.private_key"] = privateKey
+	}
+
+	return nil
+}
+
+// ValidateName validates network name.
+func (n *wireguard) ValidateName(name string) error {
+	err := validate.IsInterfaceName(name)
+	if err != nil {
+		return err
+	}
+
+	// Apply common name validation that applies to all network types.
+	return n.common.ValidateName(name)
+}
+
+// Validate network config.
+func (n *wireguard) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		"wireguard.private_key": validate.Required(networkWireguard.ValidKey),
+		"wireguard.listen_port": validate.Optional(validate.IsNetworkPort),
+		"mtu":                   validate.Optional(validate.IsNetworkMTU),
+		"ipv4.address":          validate.Optional(validate.IsNetworkAddressCIDRV4),
+		"ipv6.address":          validate.Optional(validate.IsNetworkAddressCIDRV6),
+	}
+
+	err := n.validate(config, rules)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Create checks whether the interface name is used already and that the WireGuard tools are available.
+func (n *wireguard) Create(clientType request.ClientType) error {
+	n.logger.Debug("Create", logger.Ctx{"clientType": clientType, "config": n.config})
+
+	if InterfaceExists(n.name) {
+		return fmt.Errorf("Network interface %q already exists", n.name)
+	}
+
+	if !networkWireguard.Installed() {
+		return fmt.Errorf(`The "wg" tool is required to create WireGuard networks`)
+	}
+
+	return nil
+}
+
+// isRunning returns whether the network is up.
+func (n *wireguard) isRunning() bool {
+	return InterfaceExists(n.name)
+}
+
+// Delete deletes a network.
+func (n *wireguard) Delete(clientType request.ClientType) error {
+	n.logger.Debug("Delete", logger.Ctx{"clientType": clientType})
+
+	if n.isRunning() {
+		err := n.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.common.delete(clientType)
+}
+
+// Rename renames a network.
+func (n *wireguard) Rename(newName string) error {
+	n.logger.Debug("Rename", logger.Ctx{"newName": newName})
+
+	if InterfaceExists(newName) {
+		return fmt.Errorf("Network interface %q already exists", newName)
+	}
+
+	// Bring the network down.
+	if n.isRunning() {
+		err := n.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rename common steps.
+	err := n.common.rename(newName)
+	if err != nil {
+		return err
+	}
+
+	// Bring the network up.
+	err = n.Start()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Start starts the network.
+func (n *wireguard) Start() error {
+	n.logger.Debug("Start")
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { n.setUnavailable() })
+
+	err := n.setup()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	// Ensure network is marked as available now its started.
+	n.setAvailable()
+
+	return nil
+}
+
+// setup creates the WireGuard interface (if needed) and applies the network and peer configuration to it.
+func (n *wireguard) setup() error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if !n.isRunning() {
+		link := &ip.Wireguard{Link: ip.Link{Name: n.name}}
+		err := link.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating WireGuard interface %q: %w", n.name, err)
+		}
+
+		revert.Add(func() { _ = link.Delete() })
+	}
+
+	// Set the MTU.
+	mtu := uint64(wireguardDefaultMTU)
+	if n.config["mtu"] != "" {
+		var err error
+		mtu, err = strconv.ParseUint(n.config["mtu"], 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid MTU %q: %w", n.config["mtu"], err)
+		}
+	}
+
+	link := &ip.Link{Name: n.name}
+	err := link.SetMTU(uint32(mtu))
+	if err != nil {
+		return fmt.Errorf("Failed setting MTU %d on %q: %w", mtu, n.name, err)
+	}
+
+	// Configure the interface identity.
+	listenPort := uint64(wireguardDefaultListenPort)
+	if n.config["wireguard.listen_port"] != "" {
+		listenPort, err = strconv.ParseUint(n.config["wireguard.listen_port"], 10, 16)
+		if err != nil {
+			return fmt.Errorf("Invalid listen port %q: %w", n.config["wireguard.listen_port"], err)
+		}
+	}
+
+	err = networkWireguard.InterfaceSet(n.name, n.config["wireguard.private_key"], listenPort)
+	if err != nil {
+		return err
+	}
+
+	// Configure the tunnel addresses.
+	for _, family := range []string{ip.FamilyV4, ip.FamilyV6} {
+		addr := &ip.Addr{
+			DevName: n.name,
+			Scope:   "global",
+			Family:  family,
+		}
+
+		err = addr.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, key := range []string{"ipv4.address", "ipv6.address"} {
+		if n.config[key] == "" {
+			continue
+		}
+
+		family := ip.FamilyV4
+		if key == "ipv6.address" {
+			family = ip.FamilyV6
+		}
+
+		addr := &ip.Addr{
+			DevName: n.name,
+			Address: n.config[key],
+			Family:  family,
+		}
+
+		err = addr.Add()
+		if err != nil {
+			return fmt.Errorf("Failed adding address %q to %q: %w", n.config[key], n.name, err)
+		}
+	}
+
+	// Traffic coming from the tunnel is routed by the host.
+	err = localUtil.SysctlSet("net/ipv4/ip_forward", "1")
+	if err != nil {
+		return err
+	}
+
+	if util.PathExists("/proc/sys/net/ipv6") {
+		err = localUtil.SysctlSet(fmt.Sprintf("net/ipv6/conf/%s/forwarding", n.name), "1")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = link.SetUp()
+	if err != nil {
+		return err
+	}
+
+	err = n.setupPeers()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// wireguardPeerFromConfig converts the config of a network peer into a WireGuard peer.
+func wireguardPeerFromConfig(config map[string]string) networkWireguard.Peer {
+	peer := networkWireguard.Peer{
+		PublicKey:    config["public_key"],
+		PresharedKey: config["preshared_key"],
+		Endpoint:     config["endpoint"],
+		AllowedIPs:   util.SplitNTrimSpace(config["allowed_ips"], ",", -1, true),
+	}
+
+	peer.PersistentKeepalive, _ = strconv.ParseUint(config["persistent_keepalive"], 10, 16)
+
+	return peer
+}
+
+// setupPeers synchronises the peers configured on the interface and their routes with the network peers.
+func (n *wireguard) setupPeers() error {
+	if !n.isRunning() {
+		return nil
+	}
+
+	peers, err := n.state.DB.Cluster.GetNetworkPeers(n.ID())
+	if err != nil {
+		return fmt.Errorf("Failed loading network peers: %w", err)
+	}
+
+	wgState, err := networkWireguard.State(n.name)
+	if err != nil {
+		return err
+	}
+
+	wantedPeers := make(map[string]networkWireguard.Peer, len(peers))
+	for _, peer := range peers {
+		wgPeer := wireguardPeerFromConfig(peer.Config)
+		wantedPeers[wgPeer.PublicKey] = wgPeer
+	}
+
+	// Remove peers that are not configured anymore.
+	for _, peerState := range wgState.Peers {
+		_, found := wantedPeers[peerState.PublicKey]
+		if found {
+			continue
+		}
+
+		err = networkWireguard.PeerRemove(n.name, peerState.PublicKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Apply the configured peers.
+	for _, wgPeer := range wantedPeers {
+		err = networkWireguard.PeerSet(n.name, wgPeer)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Route the allowed IPs of all peers through the interface.
+	for _, family := range []string{ip.FamilyV4, ip.FamilyV6} {
+		r := &ip.Route{
+			DevName: n.name,
+			Proto:   "static",
+			Family:  family,
+		}
+
+		err = r.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, wgPeer := range wantedPeers {
+		for _, allowedIP := range wgPeer.AllowedIPs {
+			_, subnet, err := net.ParseCIDR(allowedIP)
+			if err != nil {
+				return err
+			}
+
+			family := ip.FamilyV4
+			if subnet.IP.To4() == nil {
+				family = ip.FamilyV6
+			}
+
+			r := &ip.Route{
+				DevName: n.name,
+				Route:   subnet.String(),
+				Proto:   "static",
+				Family:  family,
+			}
+
+			err = r.Add()
+			if err != nil {
+				return fmt.Errorf("Failed adding route %q to %q: %w", subnet.String(), n.name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the network.
+func (n *wireguard) Stop() error {
+	n.logger.Debug("Stop")
+
+	if !n.isRunning() {
+		return nil
+	}
+
+	link := &ip.Link{Name: n.name}
+	err := link.Delete()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update updates the network. Accepts notification boolean indicating if this update request is coming from a
+// cluster notification, in which case do not update the database, just apply local changes needed.
+func (n *wireguard) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
+	n.logger.Debug("Update", logger.Ctx{"clientType": clientType, "newNetwork": newNetwork})
+
+	dbUpdateNeeded, changedKeys, oldNetwork, err := n.common.configChanged(newNetwork)
+	if err != nil {
+		return err
+	}
+
+	if !dbUpdateNeeded {
+		return nil // Nothing changed.
+	}
+
+	// If the network as a whole has not had any previous creation attempts, or the node itself is still
+	// pending, then don't apply the new settings to the node, just to the database record (ready for the
+	// actual global create request to be initiated).
+	if n.Status() == api.NetworkStatusPending || n.LocalStatus() == api.NetworkStatusPending {
+		return n.common.update(newNetwork, targetNode, clientType)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Define a function which reverts everything.
+	revert.Add(func() {
+		// Reset changes to all nodes and database.
+		_ = n.common.update(oldNetwork, targetNode, clientType)
+
+		// Reset any change that was made to the interface.
+		_ = n.setup()
+	})
+
+	// Apply changes to all nodes and database.
+	err = n.common.update(newNetwork, targetNode, clientType)
+	if err != nil {
+		return err
+	}
+
+	err = n.setup()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	// Notify dependent networks (those using this network as their uplink) of the changes.
+	// Do this after the network has been successfully updated so that a failure to notify a dependent network
+	// doesn't prevent the network itself from being updated.
+	if clientType == request.ClientTypeNormal && len(changedKeys) > 0 {
+		n.common.notifyDependentNetworks(changedKeys)
+	}
+
+	return nil
+}
+
+// State returns the network state, including the WireGuard interface and peers state.
+func (n *wireguard) State() (*api.NetworkState, error) {
+	state, err := n.common.State()
+	if err != nil {
+		return nil, err
+	}
+
+	wgState, err := networkWireguard.State(n.name)
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := n.state.DB.Cluster.GetNetworkPeers(n.ID())
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network peers: %w", err)
+	}
+
+	peerNames := make(map[string]string, len(peers))
+	for _, peer := range peers {
+		peerNames[peer.Config["public_key"]] = peer.Name
+	}
+
+	state.Wireguard = &api.NetworkStateWireguard{
+		PublicKey:  wgState.PublicKey,
+		ListenPort: wgState.ListenPort,
+		Peers:      make([]api.NetworkStateWireguardPeer, 0, len(wgState.Peers)),
+	}
+
+	for _, peerState := range wgState.Peers {
+		state.Wireguard.Peers = append(state.Wireguard.Peers, api.NetworkStateWireguardPeer{
+			Name:            peerNames[peerState.PublicKey],
+			PublicKey:       peerState.PublicKey,
+			Endpoint:        peerState.Endpoint,
+			LatestHandshake: peerState.LatestHandshake,
+			BytesReceived:   peerState.BytesReceived,
+			BytesSent:       peerState.BytesSent,
+		})
+	}
+
+	return state, nil
+}
+
+// peerValidate validates the WireGuard peer request.
+func (n *wireguard) peerValidate(peerName string, peer *api.NetworkPeerPut) error {
+	err := acl.ValidName(peerName)
+	if err != nil {
+		return err
+	}
+
+	if util.ValueInSlice(peerName, acl.ReservedNetworkSubects) {
+		return fmt.Errorf("Name cannot be one of the reserved network subjects: %v", acl.ReservedNetworkSubects)
+	}
+
+	rules := map[string]func(value string) error{
+		"public_key":           validate.Required(networkWireguard.ValidKey),
+		"preshared_key":        validate.Optional(networkWireguard.ValidKey),
+		"allowed_ips":          validate.Required(validate.IsListOf(validate.IsNetwork)),
+		"endpoint":             validate.Optional(validate.IsListenAddress(true, false, true)),
+		"persistent_keepalive": validate.Optional(validate.IsInRange(0, 65535)),
+	}
+
+	for k, validator := range rules {
+		err := validator(peer.Config[k])
+		if err != nil {
+			return fmt.Errorf("Invalid value for network peer %q option %q: %w", peerName, k, err)
+		}
+	}
+
+	for k := range peer.Config {
+		_, found := rules[k]
+		if found {
+			continue
+		}
+
+		// User keys are not validated.
+		if internalInstance.IsUserConfig(k) {
+			continue
+		}
+
+		return fmt.Errorf("Invalid option %q", k)
+	}
+
+	return nil
+}
+
+// PeerCreate creates a WireGuard peer.
+func (n *wireguard) PeerCreate(peer api.NetworkPeersPost) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if peer.TargetProject != "" || peer.TargetNetwork != "" {
+		return api.StatusErrorf(http.StatusBadRequest, "WireGuard peers don't use a target network")
+	}
+
+	err := n.peerValidate(peer.Name, &peer.NetworkPeerPut)
+	if err != nil {
+		return err
+	}
+
+	// Check if there is an existing peer using the same name or the same public key.
+	peers, err := n.state.DB.Cluster.GetNetworkPeers(n.ID())
+	if err != nil {
+		return err
+	}
+
+	for _, existingPeer := range peers {
+		if peer.Name == existingPeer.Name {
+			return api.StatusErrorf(http.StatusConflict, "A peer for that name already exists")
+		}
+
+		if peer.Config["public_key"] == existingPeer.Config["public_key"] {
+			return api.StatusErrorf(http.StatusConflict, "A peer for that public key already exists")
+		}
+	}
+
+	peerID, _, err := n.state.DB.Cluster.CreateNetworkPeer(n.ID(), &peer)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_ = n.state.DB.Cluster.DeleteNetworkPeer(n.ID(), peerID)
+		_ = n.setupPeers()
+	})
+
+	err = n.setupPeers()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// PeerUpdate updates a WireGuard peer.
+func (n *wireguard) PeerUpdate(peerName string, req api.NetworkPeerPut) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	curPeerID, curPeer, err := n.state.DB.Cluster.GetNetworkPeer(n.ID(), peerName)
+	if err != nil {
+		return err
+	}
+
+	err = n.peerValidate(peerName, &req)
+	if err != nil {
+		return err
+	}
+
+	// Check the new public key isn't used by another peer.
+	peers, err := n.state.DB.Cluster.GetNetworkPeers(n.ID())
+	if err != nil {
+		return err
+	}
+
+	for _, existingPeer := range peers {
+		if existingPeer.Name != peerName && req.Config["public_key"] == existingPeer.Config["public_key"] {
+			return api.StatusErrorf(http.StatusConflict, "A peer for that public key already exists")
+		}
+	}
+
+	err = n.state.DB.Cluster.UpdateNetworkPeer(n.ID(), curPeerID, &req)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		_ = n.state.DB.Cluster.UpdateNetworkPeer(n.ID(), curPeerID, &curPeer.NetworkPeerPut)
+		_ = n.setupPeers()
+	})
+
+	err = n.setupPeers()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// PeerDelete deletes a WireGuard peer.
+func (n *wireguard) PeerDelete(peerName string) error {
+	peerID, peer, err := n.state.DB.Cluster.GetNetworkPeer(n.ID(), peerName)
+	if err != nil {
+		return err
+	}
+
+	isUsed, err := n.peerIsUsed(peer.Name)
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot delete a Peer that is in use")
+	}
+
+	err = n.state.DB.Cluster.DeleteNetworkPeer(n.ID(), peerID)
+	if err != nil {
+		return err
+	}
+
+	return n.setupPeers()
+}
@@ -0,0 +1,291 @@
+package network
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lxc/incus/internal/server/metrics"
+	"github.com/lxc/incus/internal/server/network/conntrack"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/util"
+)
+
+// flowLogExpiry is how long a connection that's no longer tracked by the kernel is kept in the flow log.
+const flowLogExpiry = time.Hour
+
+// flowInstance identifies the instance an address belongs to.
+type flowInstance struct {
+	project string
+	name    string
+}
+
+// flowKey identifies a connection by its 5-tuple.
+type flowKey struct {
+	protocol        string
+	source          string
+	destination     string
+	sourcePort      uint64
+	destinationPort uint64
+}
+
+// flowRecord is the accounting of a single connection.
+type flowRecord struct {
+	flow    api.NetworkFlow
+	last    conntrack.Entry
+	tracked bool
+
+	// The instances on both ends of the connection, each with its own project.
+	sourceInstance      flowInstance
+	destinationInstance flowInstance
+}
+
+// flowCounters is the traffic accounted to an instance, from the instance's point of view.
+type flowCounters struct {
+	receiveBytes    uint64
+	receivePackets  uint64
+	transmitBytes   uint64
+	transmitPackets uint64
+}
+
+// flowLog is the flow accounting of a network on the local member.
+type flowLog struct {
+	flows     map[flowKey]*flowRecord
+	instances map[flowInstance]*flowCounters
+}
+
+// flowLogs contains the flow logs of the networks with flow logging enabled.
+var flowLogs = map[ProjectNetwork]*flowLog{}
+var flowLogsMu sync.Mutex
+
+// flowLogUpdate accounts the connection tracking entries that involve one of the subnets to the network's flow log.
+// The addresses map is used to associate the connections with instances.
+// Returns the connections that saw traffic since the last update, with their counters set to that traffic.
+func flowLogUpdate(pn ProjectNetwork, entries []conntrack.Entry, subnets []*net.IPNet, addresses map[string]flowInstance) []api.NetworkFlow {
+	flowLogsMu.Lock()
+	defer flowLogsMu.Unlock()
+
+	log := flowLogs[pn]
+	if log == nil {
+		log = &flowLog{
+			flows:     map[flowKey]*flowRecord{},
+			instances: map[flowInstance]*flowCounters{},
+		}
+
+		flowLogs[pn] = log
+	}
+
+	inSubnets := func(ip net.IP) bool {
+		for _, subnet := range subnets {
+			if subnet.Contains(ip) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	now := time.Now()
+	seen := make(map[flowKey]bool, len(entries))
+	changes := []api.NetworkFlow{}
+
+	for _, entry := range entries {
+		destination := entry.Destination
+		destinationPort := entry.DestinationPort
+
+		// Connections that were destination NATed to the network are recorded with their real destination.
+		if !inSubnets(destination) && entry.ReplySource != nil && inSubnets(entry.ReplySource) {
+			destination = entry.ReplySource
+			destinationPort = entry.ReplySourcePort
+		}
+
+		if !inSubnets(entry.Source) && !inSubnets(destination) {
+			continue
+		}
+
+		key := flowKey{
+			protocol:        entry.Protocol,
+			source:          entry.Source.String(),
+			destination:     destination.String(),
+			sourcePort:      entry.SourcePort,
+			destinationPort: destinationPort,
+		}
+
+		// The same connection may be tracked more than once (e.g. in multiple zones).
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+
+		record := log.flows[key]
+		if record == nil {
+			sourceInstance := addresses[key.source]
+			destinationInstance := addresses[key.destination]
+
+			record = &flowRecord{
+				flow: api.NetworkFlow{
+					Protocol:            key.protocol,
+					Source:              key.source,
+					SourcePort:          key.sourcePort,
+					Destination:         key.destination,
+					DestinationPort:     key.destinationPort,
+					SourceInstance:      sourceInstance.name,
+					DestinationInstance: destinationInstance.name,
+					SourceProject:       sourceInstance.project,
+					DestinationProject:  destinationInstance.project,
+				},
+				sourceInstance:      sourceInstance,
+				destinationInstance: destinationInstance,
+			}
+
+			log.flows[key] = record
+		}
+
+		// Work out the traffic since the last update, counters going backwards mean the tuple was reused.
+		delta := entry
+		if record.tracked && entry.OriginalBytes >= record.last.OriginalBytes && entry.ReplyBytes >= record.last.ReplyBytes {
+			delta.OriginalBytes -= record.last.OriginalBytes
+			delta.OriginalPackets -= record.last.OriginalPackets
+			delta.ReplyBytes -= record.last.ReplyBytes
+			delta.ReplyPackets -= record.last.ReplyPackets
+		}
+
+		record.last = entry
+		record.tracked = true
+
+		if delta.OriginalPackets == 0 && delta.ReplyPackets == 0 && !record.flow.LastSeen.IsZero() {
+			continue
+		}
+
+		record.flow.BytesSent += delta.OriginalBytes
+		record.flow.PacketsSent += delta.OriginalPackets
+		record.flow.BytesReceived += delta.ReplyBytes
+		record.flow.PacketsReceived += delta.ReplyPackets
+		record.flow.LastSeen = now
+
+		// Account the traffic to the instances on both ends of the connection.
+		if record.sourceInstance.name != "" {
+			counters := log.instanceCounters(record.sourceInstance)
+			counters.transmitBytes += delta.OriginalBytes
+			counters.transmitPackets += delta.OriginalPackets
+			counters.receiveBytes += delta.ReplyBytes
+			counters.receivePackets += delta.ReplyPackets
+		}
+
+		if record.destinationInstance.name != "" {
+			counters := log.instanceCounters(record.destinationInstance)
+			counters.receiveBytes += delta.OriginalBytes
+			counters.receivePackets += delta.OriginalPackets
+			counters.transmitBytes += delta.ReplyBytes
+			counters.transmitPackets += delta.ReplyPackets
+		}
+
+		change := record.flow
+		change.BytesSent = delta.OriginalBytes
+		change.PacketsSent = delta.OriginalPackets
+		change.BytesReceived = delta.ReplyBytes
+		change.PacketsReceived = delta.ReplyPackets
+		changes = append(changes, change)
+	}
+
+	// Forget about connections that haven't been tracked for a while.
+	for key, record := range log.flows {
+		if seen[key] {
+			continue
+		}
+
+		record.tracked = false
+
+		if now.Sub(record.flow.LastSeen) > flowLogExpiry {
+			delete(log.flows, key)
+		}
+	}
+
+	return changes
+}
+
+// instanceCounters returns the counters of an instance, initializing them if needed.
+func (l *flowLog) instanceCounters(inst flowInstance) *flowCounters {
+	counters := l.instances[inst]
+	if counters == nil {
+		counters = &flowCounters{}
+		l.instances[inst] = counters
+	}
+
+	return counters
+}
+
+// flowProjects returns the projects a flow is visible from. Those are the projects of the instances on both ends
+// of the connection, or the project of the network if no instance is involved.
+func flowProjects(flow api.NetworkFlow, networkProject string) []string {
+	projects := []string{}
+	for _, projectName := range []string{flow.SourceProject, flow.DestinationProject} {
+		if projectName != "" && !util.ValueInSlice(projectName, projects) {
+			projects = append(projects, projectName)
+		}
+	}
+
+	if len(projects) == 0 {
+		projects = append(projects, networkProject)
+	}
+
+	return projects
+}
+
+// flowLogGet returns the flows recorded for a network, most recent first.
+func flowLogGet(pn ProjectNetwork) []api.NetworkFlow {
+	flowLogsMu.Lock()
+	defer flowLogsMu.Unlock()
+
+	flows := []api.NetworkFlow{}
+
+	log := flowLogs[pn]
+	if log == nil {
+		return flows
+	}
+
+	for _, record := range log.flows {
+		flows = append(flows, record.flow)
+	}
+
+	sort.Slice(flows, func(i, j int) bool {
+		return flows[i].LastSeen.After(flows[j].LastSeen)
+	})
+
+	return flows
+}
+
+// flowLogDelete removes the flow log of a network.
+func flowLogDelete(pn ProjectNetwork) {
+	flowLogsMu.Lock()
+	defer flowLogsMu.Unlock()
+
+	delete(flowLogs, pn)
+}
+
+// FlowMetrics returns the traffic accounted to the instances of a project by the flow logs of the local member.
+func FlowMetrics(projectName string) *metrics.MetricSet {
+	flowLogsMu.Lock()
+	defer flowLogsMu.Unlock()
+
+	out := metrics.NewMetricSet(map[string]string{"project": projectName})
+
+	for pn, log := range flowLogs {
+		for inst, counters := range log.instances {
+			if inst.project != projectName {
+				continue
+			}
+
+			labels := map[string]string{"name": inst.name, "network": pn.NetworkName}
+
+			out.AddSamples(metrics.NetworkFlowReceiveBytesTotal, metrics.Sample{Value: float64(counters.receiveBytes), Labels: labels})
+			out.AddSamples(metrics.NetworkFlowReceivePacketsTotal, metrics.Sample{Value: float64(counters.receivePackets), Labels: labels})
+			out.AddSamples(metrics.NetworkFlowTransmitBytesTotal, metrics.Sample{Value: float64(counters.transmitBytes), Labels: labels})
+			out.AddSamples(metrics.NetworkFlowTransmitPacketsTotal, metrics.Sample{Value: float64(counters.transmitPackets), Labels: labels})
+		}
+	}
+
+	return out
+}
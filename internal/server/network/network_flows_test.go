@@ -0,0 +1,62 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lxc/incus/internal/server/network/conntrack"
+	"github.com/lxc/incus/shared/api"
+)
+
+func TestFlowLogUpdateProjects(t *testing.T) {
+	pn := ProjectNetwork{ProjectName: "default", NetworkName: "flowtest"}
+	defer flowLogDelete(pn)
+
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	addresses := map[string]flowInstance{
+		"10.0.0.2": {project: "foo", name: "c1"},
+		"10.0.0.3": {project: "bar", name: "c2"},
+	}
+
+	entries := []conntrack.Entry{{
+		Protocol:        "tcp",
+		Source:          net.ParseIP("10.0.0.2"),
+		Destination:     net.ParseIP("10.0.0.3"),
+		SourcePort:      40000,
+		DestinationPort: 80,
+		OriginalPackets: 2,
+		OriginalBytes:   100,
+		ReplyPackets:    3,
+		ReplyBytes:      300,
+	}}
+
+	changes := flowLogUpdate(pn, entries, []*net.IPNet{subnet}, addresses)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "foo", changes[0].SourceProject)
+		assert.Equal(t, "bar", changes[0].DestinationProject)
+	}
+
+	log := flowLogs[pn]
+
+	// Each end of the connection is accounted to the instance in its own project.
+	source := log.instances[flowInstance{project: "foo", name: "c1"}]
+	if assert.NotNil(t, source) {
+		assert.Equal(t, flowCounters{transmitBytes: 100, transmitPackets: 2, receiveBytes: 300, receivePackets: 3}, *source)
+	}
+
+	destination := log.instances[flowInstance{project: "bar", name: "c2"}]
+	if assert.NotNil(t, destination) {
+		assert.Equal(t, flowCounters{receiveBytes: 100, receivePackets: 2, transmitBytes: 300, transmitPackets: 3}, *destination)
+	}
+
+	assert.Nil(t, log.instances[flowInstance{project: "foo", name: "c2"}])
+}
+
+func TestFlowProjects(t *testing.T) {
+	assert.Equal(t, []string{"foo", "bar"}, flowProjects(api.NetworkFlow{SourceProject: "foo", DestinationProject: "bar"}, "default"))
+	assert.Equal(t, []string{"bar"}, flowProjects(api.NetworkFlow{DestinationProject: "bar"}, "default"))
+	assert.Equal(t, []string{"foo"}, flowProjects(api.NetworkFlow{SourceProject: "foo", DestinationProject: "foo"}, "default"))
+	assert.Equal(t, []string{"default"}, flowProjects(api.NetworkFlow{}, "default"))
+}
@@ -57,6 +57,8 @@ type Network interface {
 	// Status.
 	State() (*api.NetworkState, error)
 	Leases(projectName string, clientType request.ClientType) ([]api.NetworkLease, error)
+	Flows(projectName string, clientType request.ClientType) ([]api.NetworkFlow, error)
+	FlowsUpdate() error
 
 	// Address Forwards.
 	ForwardCreate(forward api.NetworkForwardsPost, clientType request.ClientType) error
@@ -0,0 +1,337 @@
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lxc/incus/internal/server/cluster"
+	"github.com/lxc/incus/internal/server/cluster/request"
+	"github.com/lxc/incus/internal/server/db"
+	"github.com/lxc/incus/internal/server/network/dhcpv6"
+	"github.com/lxc/incus/internal/server/project"
+	"github.com/lxc/incus/internal/server/state"
+	localUtil "github.com/lxc/incus/internal/server/util"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+	"github.com/lxc/incus/shared/util"
+	"github.com/lxc/incus/shared/validate"
+)
+
+// Volatile keys used to record the prefix delegated to an uplink network.
+// Each cluster member requests its own prefix, so those keys are member specific.
+const (
+	pdVolatilePrefix = "volatile.ipv6.prefix_delegation.prefix"
+	pdVolatileServer = "volatile.ipv6.prefix_delegation.server"
+	pdVolatileRenew  = "volatile.ipv6.prefix_delegation.renew"
+	pdVolatileExpiry = "volatile.ipv6.prefix_delegation.expiry"
+)
+
+// pdVolatileSubnet is the volatile key used to record the subnet allocated to a downstream network.
+const pdVolatileSubnet = "volatile.ipv6.prefix_delegation.subnet"
+
+// pdRequestTimeout is how long to wait for a DHCPv6 server to delegate a prefix.
+const pdRequestTimeout = 30 * time.Second
+
+// prefixDelegationUplinkRules returns the validation rules for the prefix delegation keys of uplink networks.
+func prefixDelegationUplinkRules() map[string]func(value string) error {
+	return map[string]func(value string) error{
+		"ipv6.prefix_delegation.request":   validate.Optional(validate.IsBool),
+		"ipv6.prefix_delegation.interface": validate.Optional(validate.IsInterfaceName),
+		"ipv6.prefix_delegation.length":    validate.Optional(validate.IsInRange(1, 64)),
+		pdVolatilePrefix:                   validate.Optional(validate.IsNetworkV6),
+		pdVolatileServer:                   validate.IsAny,
+		pdVolatileRenew:                    validate.Optional(validate.IsInt64),
+		pdVolatileExpiry:                   validate.Optional(validate.IsInt64),
+	}
+}
+
+// prefixDelegationSubnet returns the index-th /64 subnet of a delegated prefix.
+func prefixDelegationSubnet(prefix *net.IPNet, index uint64) (*net.IPNet, error) {
+	ones, bits := prefix.Mask.Size()
+	if bits != 128 || ones > 64 {
+		return nil, fmt.Errorf("Delegated prefix %q is too small to allocate /64 subnets from", prefix.String())
+	}
+
+	if ones < 64 && index >= uint64(1)<<(64-ones) || ones == 64 && index > 0 {
+		return nil, fmt.Errorf("No more /64 subnets available in delegated prefix %q", prefix.String())
+	}
+
+	ip := make(net.IP, net.IPv6len)
+	binary.BigEndian.PutUint64(ip[:8], binary.BigEndian.Uint64(prefix.IP.To16()[:8])+index)
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(64, 128)}, nil
+}
+
+// prefixDelegationIndex returns the index of a /64 subnet within a delegated prefix.
+// Returns false if the subnet isn't a /64 subnet of the prefix.
+func prefixDelegationIndex(prefix *net.IPNet, subnet *net.IPNet) (uint64, bool) {
+	ones, _ := subnet.Mask.Size()
+	if ones != 64 || !SubnetContains(prefix, subnet) {
+		return 0, false
+	}
+
+	return binary.BigEndian.Uint64(subnet.IP.To16()[:8]) - binary.BigEndian.Uint64(prefix.IP.To16()[:8]), true
+}
+
+// prefixDelegationGateway returns the address of the gateway of a delegated subnet in CIDR notation.
+func prefixDelegationGateway(subnet *net.IPNet) string {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, subnet.IP.To16())
+	ip[net.IPv6len-1] = 1
+
+	ones, _ := subnet.Mask.Size()
+	return fmt.Sprintf("%s/%d", ip.String(), ones)
+}
+
+// prefixDelegationAddress returns the address a bridge uses in the first /64 subnet of its delegated prefix.
+// Returns "none" if no prefix was delegated yet.
+func prefixDelegationAddress(config map[string]string) string {
+	_, prefix, err := net.ParseCIDR(config[pdVolatilePrefix])
+	if err != nil {
+		return "none"
+	}
+
+	subnet, err := prefixDelegationSubnet(prefix, 0)
+	if err != nil {
+		return "none"
+	}
+
+	return prefixDelegationGateway(subnet)
+}
+
+// prefixDelegationInterface returns the interface used to request a delegated prefix for an uplink network.
+func prefixDelegationInterface(n Network) (string, error) {
+	config := n.Config()
+
+	if config["ipv6.prefix_delegation.interface"] != "" {
+		return config["ipv6.prefix_delegation.interface"], nil
+	}
+
+	switch n.Type() {
+	case "physical":
+		return GetHostDevice(config["parent"], config["vlan"]), nil
+	case "bridge":
+		externalInterfaces := util.SplitNTrimSpace(config["bridge.external_interfaces"], ",", -1, true)
+		if len(externalInterfaces) > 0 {
+			return externalInterfaces[0], nil
+		}
+	}
+
+	return "", fmt.Errorf(`No interface to request a delegated prefix on, "ipv6.prefix_delegation.interface" must be set`)
+}
+
+// PrefixDelegationUpdate requests or renews the prefix delegated to an uplink network on the local member through
+// DHCPv6 and allocates /64 subnets from it to the networks using it as their uplink.
+// Bridge networks use the first subnet of the prefix for themselves.
+func PrefixDelegationUpdate(ctx context.Context, s *state.State, n Network) error {
+	config := n.Config()
+	newConfig := localUtil.CopyConfig(config)
+	changed := false
+	now := time.Now()
+
+	renew, _ := strconv.ParseInt(config[pdVolatileRenew], 10, 64)
+	expiry, _ := strconv.ParseInt(config[pdVolatileExpiry], 10, 64)
+
+	if config[pdVolatilePrefix] == "" || now.Unix() >= renew {
+		iface, err := prefixDelegationInterface(n)
+		if err != nil {
+			return err
+		}
+
+		client := &dhcpv6.Client{
+			Interface: iface,
+			DUID:      dhcpv6.NewDUID(fmt.Sprintf("incus/%s/%s/%s", s.ServerName, n.Project(), n.Name())),
+			IAID:      1,
+		}
+
+		if config["ipv6.prefix_delegation.length"] != "" {
+			client.PrefixLength, err = strconv.Atoi(config["ipv6.prefix_delegation.length"])
+			if err != nil {
+				return err
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, pdRequestTimeout)
+		defer cancel()
+
+		// Try to extend the current delegation first, then look for a new one.
+		var lease *dhcpv6.Lease
+		_, prefix, _ := net.ParseCIDR(config[pdVolatilePrefix])
+		serverID, _ := hex.DecodeString(config[pdVolatileServer])
+		if prefix != nil && len(serverID) > 0 {
+			lease, err = client.Renew(ctx, &dhcpv6.Lease{Prefix: prefix, ServerID: serverID})
+			if err != nil {
+				logger.Warn("Failed renewing delegated prefix", logger.Ctx{"network": n.Name(), "prefix": prefix.String(), "err": err})
+			}
+		}
+
+		if lease == nil {
+			lease, err = client.Solicit(ctx)
+		}
+
+		if lease != nil {
+			if lease.Prefix.String() != config[pdVolatilePrefix] {
+				logger.Info("Obtained delegated prefix", logger.Ctx{"network": n.Name(), "prefix": lease.Prefix.String()})
+			}
+
+			newConfig[pdVolatilePrefix] = lease.Prefix.String()
+			newConfig[pdVolatileServer] = hex.EncodeToString(lease.ServerID)
+			newConfig[pdVolatileRenew] = fmt.Sprintf("%d", now.Add(lease.T1).Unix())
+			newConfig[pdVolatileExpiry] = fmt.Sprintf("%d", now.Add(lease.ValidLifetime).Unix())
+			changed = true
+		} else if config[pdVolatilePrefix] != "" && now.Unix() >= expiry {
+			logger.Warn("Delegated prefix expired", logger.Ctx{"network": n.Name(), "prefix": config[pdVolatilePrefix], "err": err})
+
+			for _, key := range []string{pdVolatilePrefix, pdVolatileServer, pdVolatileRenew, pdVolatileExpiry} {
+				delete(newConfig, key)
+			}
+
+			changed = true
+		} else {
+			return fmt.Errorf("Failed requesting delegated prefix on %q: %w", iface, err)
+		}
+	}
+
+	if changed {
+		err := n.Validate(newConfig)
+		if err != nil {
+			return err
+		}
+
+		// Only member specific keys changed, so there's no need to notify the other members.
+		err = n.Update(api.NetworkPut{Description: n.Description(), Config: newConfig}, s.ServerName, request.ClientTypeNormal)
+		if err != nil {
+			return fmt.Errorf("Failed updating network: %w", err)
+		}
+	}
+
+	// Subnets of the delegated prefix are only allocated to downstream networks from physical uplinks.
+	// Their addresses are shared by all the cluster members, so this is limited to standalone servers.
+	_, prefix, _ := net.ParseCIDR(newConfig[pdVolatilePrefix])
+	if prefix == nil || n.Type() != "physical" {
+		return nil
+	}
+
+	clustered, err := cluster.Enabled(s.DB.Node)
+	if err != nil {
+		return err
+	}
+
+	if clustered {
+		return nil
+	}
+
+	return prefixDelegationAllocate(ctx, s, n, prefix)
+}
+
+// prefixDelegationAllocate allocates subnets of the delegated prefix to the networks using the uplink network.
+func prefixDelegationAllocate(ctx context.Context, s *state.State, uplink Network, prefix *net.IPNet) error {
+	if uplink.Project() != project.Default {
+		return nil // Only networks in the default project can be used as uplink networks.
+	}
+
+	var projectNetworks map[string]map[int64]api.Network
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		projectNetworks, err = tx.GetCreatedNetworks(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	type downstream struct {
+		project string
+		network api.Network
+
+		// Current subnet of the network if still valid, nil if a new one must be allocated.
+		subnet *net.IPNet
+	}
+
+	// Find the networks using a subnet of the delegated prefix and the ones that need one.
+	used := map[uint64]bool{}
+
+	pending := []downstream{}
+	for projectName, networks := range projectNetworks {
+		for _, netInfo := range networks {
+			if netInfo.Config["network"] != uplink.Name() || util.IsFalseOrEmpty(netInfo.Config["ipv6.prefix_delegation.allocate"]) {
+				continue
+			}
+
+			entry := downstream{project: projectName, network: netInfo}
+
+			_, subnet, _ := net.ParseCIDR(netInfo.Config[pdVolatileSubnet])
+			if subnet != nil {
+				index, ok := prefixDelegationIndex(prefix, subnet)
+				if ok && !used[index] {
+					used[index] = true
+
+					if netInfo.Config["ipv6.address"] == prefixDelegationGateway(subnet) {
+						continue
+					}
+
+					// Keep the subnet, only the address needs fixing.
+					entry.subnet = subnet
+				}
+			}
+
+			pending = append(pending, entry)
+		}
+	}
+
+	// Allocate in a stable order.
+	sort.Slice(pending, func(i int, j int) bool {
+		if pending[i].project != pending[j].project {
+			return pending[i].project < pending[j].project
+		}
+
+		return pending[i].network.Name < pending[j].network.Name
+	})
+
+	var index uint64
+	for _, entry := range pending {
+		subnet := entry.subnet
+		if subnet == nil {
+			for used[index] {
+				index++
+			}
+
+			subnet, err = prefixDelegationSubnet(prefix, index)
+			if err != nil {
+				return err
+			}
+
+			used[index] = true
+		}
+
+		n, err := LoadByName(s, entry.project, entry.network.Name)
+		if err != nil {
+			return err
+		}
+
+		newConfig := localUtil.CopyConfig(n.Config())
+		newConfig[pdVolatileSubnet] = subnet.String()
+		newConfig["ipv6.address"] = prefixDelegationGateway(subnet)
+
+		err = n.Validate(newConfig)
+		if err != nil {
+			return fmt.Errorf("Failed allocating delegated subnet %q to network %q in project %q: %w", subnet.String(), n.Name(), n.Project(), err)
+		}
+
+		err = n.Update(api.NetworkPut{Description: n.Description(), Config: newConfig}, "", request.ClientTypeNormal)
+		if err != nil {
+			return fmt.Errorf("Failed allocating delegated subnet %q to network %q in project %q: %w", subnet.String(), n.Name(), n.Project(), err)
+		}
+
+		logger.Info("Allocated delegated subnet", logger.Ctx{"uplink": uplink.Name(), "project": n.Project(), "network": n.Name(), "subnet": subnet.String()})
+	}
+
+	return nil
+}
@@ -117,7 +117,7 @@ func UsedBy(s *state.State, networkProjectName string, networkID int64, networkN
 		}
 
 		for _, peer := range peers {
-			if peer.Status == api.NetworkStatusCreated {
+			if peer.Status == api.NetworkStatusCreated && peer.TargetNetwork != "" {
 				// Add the target project/network of the peering as using this network.
 				usedBy = append(usedBy, api.NewURL().Path(version.APIVersion, "networks", peer.TargetNetwork).Project(peer.TargetProject).String())
 
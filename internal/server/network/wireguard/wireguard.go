@@ -0,0 +1,215 @@
+package wireguard
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lxc/incus/shared/subprocess"
+)
+
+// Peer represents the configuration of a WireGuard peer.
+type Peer struct {
+	PublicKey           string
+	PresharedKey        string
+	Endpoint            string
+	AllowedIPs          []string
+	PersistentKeepalive uint64
+}
+
+// PeerState represents the runtime state of a WireGuard peer.
+type PeerState struct {
+	PublicKey       string
+	Endpoint        string
+	AllowedIPs      []string
+	LatestHandshake time.Time
+	BytesReceived   uint64
+	BytesSent       uint64
+}
+
+// InterfaceState represents the runtime state of a WireGuard interface.
+type InterfaceState struct {
+	PublicKey  string
+	ListenPort uint64
+	Peers      []PeerState
+}
+
+// Installed returns true if the WireGuard tools are installed.
+func Installed() bool {
+	_, err := exec.LookPath("wg")
+	return err == nil
+}
+
+// GenerateKey generates a new base64 encoded WireGuard private key.
+func GenerateKey() (string, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("Failed generating private key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// PublicKey derives the base64 encoded public key from a base64 encoded private key.
+func PublicKey(privateKey string) (string, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("Invalid key encoding: %w", err)
+	}
+
+	key, err := ecdh.X25519().NewPrivateKey(keyBytes)
+	if err != nil {
+		return "", fmt.Errorf("Invalid private key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// ValidKey checks that the value is a base64 encoded 32 bytes WireGuard key.
+func ValidKey(value string) error {
+	keyBytes, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("Invalid key encoding: %w", err)
+	}
+
+	if len(keyBytes) != 32 {
+		return fmt.Errorf("Invalid key length %d (expected 32 bytes)", len(keyBytes))
+	}
+
+	return nil
+}
+
+// runWithSecret runs the wg command passing the secret through an inherited file descriptor.
+// The placeholder "{secret}" in the arguments is replaced with the path of that file descriptor.
+func runWithSecret(secret string, args ...string) error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = r.Close() }()
+
+	_, err = w.WriteString(secret + "\n")
+	_ = w.Close()
+	if err != nil {
+		return err
+	}
+
+	for i, arg := range args {
+		if arg == "{secret}" {
+			// The first inherited file is available as fd 3 in the child.
+			args[i] = "/dev/fd/3"
+		}
+	}
+
+	_, err = subprocess.RunCommandInheritFds(context.TODO(), []*os.File{r}, "wg", args...)
+	return err
+}
+
+// InterfaceSet configures the private key and listen port of a WireGuard interface.
+func InterfaceSet(devName string, privateKey string, listenPort uint64) error {
+	err := runWithSecret(privateKey, "set", devName, "private-key", "{secret}", "listen-port", strconv.FormatUint(listenPort, 10))
+	if err != nil {
+		return fmt.Errorf("Failed configuring WireGuard interface %q: %w", devName, err)
+	}
+
+	return nil
+}
+
+// PeerSet adds or replaces a peer on a WireGuard interface.
+func PeerSet(devName string, peer Peer) error {
+	args := []string{"set", devName, "peer", peer.PublicKey, "replace-allowed-ips", "allowed-ips", strings.Join(peer.AllowedIPs, ",")}
+
+	if peer.Endpoint != "" {
+		args = append(args, "endpoint", peer.Endpoint)
+	}
+
+	args = append(args, "persistent-keepalive", strconv.FormatUint(peer.PersistentKeepalive, 10))
+
+	var err error
+	if peer.PresharedKey != "" {
+		args = append(args, "preshared-key", "{secret}")
+		err = runWithSecret(peer.PresharedKey, args...)
+	} else {
+		args = append(args, "preshared-key", "/dev/null")
+		_, err = subprocess.RunCommand("wg", args...)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed configuring WireGuard peer %q on %q: %w", peer.PublicKey, devName, err)
+	}
+
+	return nil
+}
+
+// PeerRemove removes a peer from a WireGuard interface.
+func PeerRemove(devName string, publicKey string) error {
+	_, err := subprocess.RunCommand("wg", "set", devName, "peer", publicKey, "remove")
+	if err != nil {
+		return fmt.Errorf("Failed removing WireGuard peer %q from %q: %w", publicKey, devName, err)
+	}
+
+	return nil
+}
+
+// State returns the runtime state of a WireGuard interface.
+func State(devName string) (*InterfaceState, error) {
+	output, err := subprocess.RunCommand("wg", "show", devName, "dump")
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting WireGuard state of %q: %w", devName, err)
+	}
+
+	state := &InterfaceState{
+		Peers: []PeerState{},
+	}
+
+	// The first line describes the interface, each following line describes a peer.
+	for i, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+
+		if i == 0 {
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("Unexpected WireGuard interface line %q", line)
+			}
+
+			state.PublicKey = fields[1]
+			state.ListenPort, _ = strconv.ParseUint(fields[2], 10, 64)
+			continue
+		}
+
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("Unexpected WireGuard peer line %q", line)
+		}
+
+		peer := PeerState{
+			PublicKey: fields[0],
+		}
+
+		if fields[2] != "(none)" {
+			peer.Endpoint = fields[2]
+		}
+
+		if fields[3] != "(none)" {
+			peer.AllowedIPs = strings.Split(fields[3], ",")
+		}
+
+		handshake, _ := strconv.ParseInt(fields[4], 10, 64)
+		if handshake > 0 {
+			peer.LatestHandshake = time.Unix(handshake, 0)
+		}
+
+		peer.BytesReceived, _ = strconv.ParseUint(fields[5], 10, 64)
+		peer.BytesSent, _ = strconv.ParseUint(fields[6], 10, 64)
+
+		state.Peers = append(state.Peers, peer)
+	}
+
+	return state, nil
+}
@@ -0,0 +1,63 @@
+package wireguard
+
+import (
+	"testing"
+)
+
+func TestPublicKey(t *testing.T) {
+	// Known key pair generated with "wg genkey | tee /dev/stderr | wg pubkey".
+	privateKey := "yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk="
+	expected := "HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw="
+
+	publicKey, err := PublicKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if publicKey != expected {
+		t.Fatalf("Unexpected public key %q (expected %q)", publicKey, expected)
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	privateKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ValidKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := PublicKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ValidKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw=", true},
+		{"HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8yk=", false},
+		{"not-base64!", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		err := ValidKey(test.value)
+		if test.valid && err != nil {
+			t.Errorf("Expected %q to be valid: %v", test.value, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("Expected %q to be invalid", test.value)
+		}
+	}
+}
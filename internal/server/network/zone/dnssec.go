@@ -0,0 +1,243 @@
+package zone
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+
+	internalInstance "github.com/lxc/incus/internal/instance"
+	"github.com/lxc/incus/internal/server/db"
+	incusDNS "github.com/lxc/incus/internal/server/dns"
+	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/logger"
+	"github.com/lxc/incus/shared/util"
+)
+
+// dnssecAlgorithms maps the supported signing algorithms to their key size.
+var dnssecAlgorithms = map[string]int{
+	"ECDSAP256SHA256": 256,
+	"ECDSAP384SHA384": 384,
+	"ED25519":         256,
+}
+
+// dnssecDefaultAlgorithm is the signing algorithm used when dnssec.algorithm isn't set.
+const dnssecDefaultAlgorithm = "ECDSAP256SHA256"
+
+// dnssecDefaultZSKLifetime is how long zone signing keys are used for when dnssec.zsk.lifetime isn't set.
+const dnssecDefaultZSKLifetime = "30d"
+
+// dnssecDefaultRolloverPeriod is how long replaced keys are kept when dnssec.rollover.period isn't set.
+const dnssecDefaultRolloverPeriod = "2d"
+
+// validateDNSSECDuration validates a key lifetime expression.
+func validateDNSSECDuration(value string) error {
+	_, err := internalInstance.GetExpiry(time.Time{}, value)
+	return err
+}
+
+// dnssecKeyType returns "ksk" or "zsk" depending on the key flags.
+func dnssecKeyType(flags uint16) string {
+	if flags&dns.SEP != 0 {
+		return "ksk"
+	}
+
+	return "zsk"
+}
+
+// dnssecConfig returns the effective value of a DNSSEC config key.
+func (d *zone) dnssecConfig(key string) string {
+	value := d.info.Config[key]
+	if value != "" {
+		return value
+	}
+
+	switch key {
+	case "dnssec.algorithm":
+		return dnssecDefaultAlgorithm
+	case "dnssec.zsk.lifetime":
+		return dnssecDefaultZSKLifetime
+	case "dnssec.rollover.period":
+		return dnssecDefaultRolloverPeriod
+	}
+
+	return ""
+}
+
+// DNSSECKeys returns the keys used to sign the zone.
+// Keys that were replaced are still returned until the end of the rollover period.
+// The keys are only read from the database, they're generated and rolled over by RotateDNSSECKeys.
+func (d *zone) DNSSECKeys() ([]db.NetworkZoneDNSSECKey, error) {
+	now := time.Now().UTC()
+
+	var keys []db.NetworkZoneDNSSECKey
+	err := d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		allKeys, err := tx.GetNetworkZoneDNSSECKeys(ctx, d.id)
+		if err != nil {
+			return fmt.Errorf("Failed loading DNSSEC keys: %w", err)
+		}
+
+		keys = make([]db.NetworkZoneDNSSECKey, 0, len(allKeys))
+		for _, key := range allKeys {
+			// Skip replaced keys which are past the rollover period but weren't removed yet.
+			if !key.RetiredAt.IsZero() {
+				expiry, err := internalInstance.GetExpiry(key.RetiredAt, d.dnssecConfig("dnssec.rollover.period"))
+				if err != nil {
+					return err
+				}
+
+				if !expiry.After(now) {
+					continue
+				}
+			}
+
+			keys = append(keys, key)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RotateDNSSECKeys generates the missing signing keys of the zone and rolls over the keys using another algorithm
+// or that reached the end of their lifetime. Replaced keys are removed at the end of the rollover period.
+//
+// This must only be run by a single cluster member at a time, usually the leader.
+func (d *zone) RotateDNSSECKeys() error {
+	algorithmName := d.dnssecConfig("dnssec.algorithm")
+	algorithm := dns.StringToAlgorithm[algorithmName]
+	bits, ok := dnssecAlgorithms[algorithmName]
+	if !ok {
+		return fmt.Errorf("Unsupported DNSSEC algorithm %q", algorithmName)
+	}
+
+	now := time.Now().UTC()
+
+	return d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		allKeys, err := tx.GetNetworkZoneDNSSECKeys(ctx, d.id)
+		if err != nil {
+			return fmt.Errorf("Failed loading DNSSEC keys: %w", err)
+		}
+
+		active := map[uint16]bool{}
+
+		for _, key := range allKeys {
+			// Remove replaced keys at the end of the rollover period.
+			if !key.RetiredAt.IsZero() {
+				expiry, err := internalInstance.GetExpiry(key.RetiredAt, d.dnssecConfig("dnssec.rollover.period"))
+				if err != nil {
+					return err
+				}
+
+				if !expiry.After(now) {
+					err = tx.DeleteNetworkZoneDNSSECKey(ctx, key.ID)
+					if err != nil {
+						return fmt.Errorf("Failed deleting DNSSEC key: %w", err)
+					}
+				}
+
+				continue
+			}
+
+			// Replace keys using another algorithm or that reached the end of their lifetime.
+			lifetimeKey := fmt.Sprintf("dnssec.%s.lifetime", dnssecKeyType(key.Flags))
+			expiry, err := internalInstance.GetExpiry(key.CreatedAt, d.dnssecConfig(lifetimeKey))
+			if err != nil {
+				return err
+			}
+
+			if key.Algorithm != algorithm || active[key.Flags] || (!expiry.IsZero() && !expiry.After(now)) {
+				err = tx.RetireNetworkZoneDNSSECKey(ctx, key.ID, now)
+				if err != nil {
+					return fmt.Errorf("Failed retiring DNSSEC key: %w", err)
+				}
+
+				continue
+			}
+
+			active[key.Flags] = true
+		}
+
+		// Generate the missing keys.
+		for _, flags := range []uint16{dns.ZONE | dns.SEP, dns.ZONE} {
+			if active[flags] {
+				continue
+			}
+
+			dnskey := incusDNS.NewDNSKEY(d.info.Name, flags, algorithm, "")
+			privKey, err := dnskey.Generate(bits)
+			if err != nil {
+				return fmt.Errorf("Failed generating DNSSEC key: %w", err)
+			}
+
+			signer, ok := privKey.(crypto.Signer)
+			if !ok {
+				return fmt.Errorf("Unsupported DNSSEC private key type")
+			}
+
+			key := db.NetworkZoneDNSSECKey{
+				Flags:      flags,
+				Algorithm:  algorithm,
+				PublicKey:  dnskey.PublicKey,
+				PrivateKey: dnskey.PrivateKeyString(signer),
+				CreatedAt:  now,
+			}
+
+			_, err = tx.CreateNetworkZoneDNSSECKey(ctx, d.id, key)
+			if err != nil {
+				return fmt.Errorf("Failed storing DNSSEC key: %w", err)
+			}
+
+			d.logger.Info("Generated DNSSEC key", logger.Ctx{"type": dnssecKeyType(flags), "tag": dnskey.KeyTag()})
+		}
+
+		return nil
+	})
+}
+
+// DNSSEC returns the public information about the DNSSEC keys of the zone.
+func (d *zone) DNSSEC() (*api.NetworkZoneDNSSEC, error) {
+	resp := &api.NetworkZoneDNSSEC{
+		Keys: []api.NetworkZoneDNSSECKey{},
+	}
+
+	if !util.IsTrue(d.info.Config["dnssec.enabled"]) {
+		return resp, nil
+	}
+
+	keys, err := d.DNSSECKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		dnskey := incusDNS.NewDNSKEY(d.info.Name, key.Flags, key.Algorithm, key.PublicKey)
+
+		info := api.NetworkZoneDNSSECKey{
+			KeyTag:    dnskey.KeyTag(),
+			Type:      dnssecKeyType(key.Flags),
+			Algorithm: dns.AlgorithmToString[key.Algorithm],
+			DNSKEY:    strings.Join(strings.Fields(dnskey.String()), " "),
+			CreatedAt: key.CreatedAt,
+			RetiredAt: key.RetiredAt,
+		}
+
+		if info.Type == "ksk" {
+			ds := dnskey.ToDS(dns.SHA256)
+			if ds != nil {
+				info.DS = strings.Join(strings.Fields(ds.String()), " ")
+			}
+		}
+
+		resp.Keys = append(resp.Keys, info)
+	}
+
+	return resp, nil
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/lxc/incus/internal/server/cluster/request"
+	"github.com/lxc/incus/internal/server/db"
 	"github.com/lxc/incus/internal/server/state"
 	"github.com/lxc/incus/shared/api"
 )
@@ -22,6 +23,11 @@ type NetworkZone interface {
 	Content() (*strings.Builder, error)
 	SOA() (*strings.Builder, error)
 
+	// DNSSEC.
+	DNSSECKeys() ([]db.NetworkZoneDNSSECKey, error)
+	RotateDNSSECKeys() error
+	DNSSEC() (*api.NetworkZoneDNSSEC, error)
+
 	// Records.
 	AddRecord(req api.NetworkZoneRecordsPost) error
 	GetRecords() ([]api.NetworkZoneRecord, error)
@@ -88,11 +88,21 @@ func Create(s *state.State, projectName string, zoneInfo *api.NetworkZonesPost)
 	}
 
 	// Insert DB record.
-	_, err = s.DB.Cluster.CreateNetworkZone(projectName, zoneInfo)
+	id, err := s.DB.Cluster.CreateNetworkZone(projectName, zoneInfo)
 	if err != nil {
 		return err
 	}
 
+	// Generate the signing keys.
+	if util.IsTrue(zoneInfo.Config["dnssec.enabled"]) {
+		zone.init(s, id, projectName, &api.NetworkZone{Name: zoneInfo.Name, NetworkZonePut: zoneInfo.NetworkZonePut})
+
+		err = zone.RotateDNSSECKeys()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Trigger a refresh of the TSIG entries.
 	err = s.DNS.UpdateTSIG()
 	if err != nil {
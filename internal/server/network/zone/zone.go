@@ -156,6 +156,13 @@ func (d *zone) validateConfig(info *api.NetworkZonePut) error {
 	rules["dns.nameservers"] = validate.IsListOf(validate.IsAny)
 	rules["network.nat"] = validate.Optional(validate.IsBool)
 
+	// DNSSEC config keys.
+	rules["dnssec.enabled"] = validate.Optional(validate.IsBool)
+	rules["dnssec.algorithm"] = validate.Optional(validate.IsOneOf("ECDSAP256SHA256", "ECDSAP384SHA384", "ED25519"))
+	rules["dnssec.zsk.lifetime"] = validate.Optional(validateDNSSECDuration)
+	rules["dnssec.ksk.lifetime"] = validate.Optional(validateDNSSECDuration)
+	rules["dnssec.rollover.period"] = validate.Optional(validateDNSSECDuration)
+
 	// Validate peer config.
 	for k := range info.Config {
 		if !strings.HasPrefix(k, "peers.") {
@@ -262,6 +269,14 @@ func (d *zone) Update(config *api.NetworkZonePut, clientType request.ClientType)
 		}
 	}
 
+	// Generate the signing keys or roll them over to a new algorithm.
+	if clientType == request.ClientTypeNormal && util.IsTrue(d.info.Config["dnssec.enabled"]) {
+		err = d.RotateDNSSECKeys()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Trigger a refresh of the TSIG entries.
 	err = d.state.DNS.UpdateTSIG()
 	if err != nil {
@@ -1013,6 +1013,18 @@ func AllowProjectUpdate(tx *db.ClusterTx, projectName string, config map[string]
 				return fmt.Errorf("Can't change %q in project %q: %w", key, projectName, err)
 			}
 
+		case "limits.buckets", "limits.backups", "limits.snapshots", "limits.networks.forwards", "limits.networks.load_balancers":
+			err := validateCountLimit(tx, info.Project, key, config[key])
+			if err != nil {
+				return fmt.Errorf("Can't change %q in project %q: %w", key, projectName, err)
+			}
+
+		case "limits.backups.size":
+			err := validateBackupsSizeLimit(tx, info.Project, config[key])
+			if err != nil {
+				return fmt.Errorf("Can't change %q in project %q: %w", key, projectName, err)
+			}
+
 		case "limits.processes":
 			fallthrough
 		case "limits.cpu":
@@ -1094,6 +1106,48 @@ func validateInstanceCountLimit(instances []api.Instance, key, value, project st
 	return nil
 }
 
+// Check that a count based limit is equal or above the current usage.
+func validateCountLimit(tx *db.ClusterTx, p api.Project, key string, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+
+	count, err := countLimitUsage(context.Background(), tx, &p, key)
+	if err != nil {
+		return err
+	}
+
+	if limit < count {
+		return fmt.Errorf("%q is too low: current usage is %d", key, count)
+	}
+
+	return nil
+}
+
+// Check that limits.backups.size is equal or above the total size of the current backups.
+func validateBackupsSizeLimit(tx *db.ClusterTx, p api.Project, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	p.Config = map[string]string{"limits.backups.size": value}
+	limit, size, err := backupsSizeUsage(context.Background(), tx, &p)
+	if err != nil {
+		return err
+	}
+
+	if limit < size {
+		return fmt.Errorf(`"limits.backups.size" is too low: current backups use %s`, units.GetByteSizeStringIEC(size, 2))
+	}
+
+	return nil
+}
+
 var countConfigInstanceType = map[string]api.InstanceType{
 	"limits.containers":       api.InstanceTypeContainer,
 	"limits.virtual-machines": api.InstanceTypeVM,
@@ -1503,9 +1557,70 @@ func AllowBackupCreation(tx *db.ClusterTx, projectName string) error {
 		return fmt.Errorf("Project %q doesn't allow for backup creation", projectName)
 	}
 
+	err = allowCountLimit(ctx, tx, project, "limits.backups")
+	if err != nil {
+		return err
+	}
+
+	if project.Config["limits.backups.size"] != "" {
+		limit, size, err := backupsSizeUsage(ctx, tx, project)
+		if err != nil {
+			return err
+		}
+
+		if size >= limit {
+			return api.StatusErrorf(http.StatusBadRequest, "Limit \"limits.backups.size\" of %s has been reached for project %q", units.GetByteSizeStringIEC(limit, 2), projectName)
+		}
+	}
+
 	return nil
 }
 
+// AllowBackupSize returns an error if the backups of the project, including a newly written one, exceed the
+// "limits.backups.size" of the project.
+func AllowBackupSize(tx *db.ClusterTx, projectName string) error {
+	ctx := context.Background()
+	dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
+	if err != nil {
+		return err
+	}
+
+	project, err := dbProject.ToAPI(ctx, tx.Tx())
+	if err != nil {
+		return err
+	}
+
+	if project.Config["limits.backups.size"] == "" {
+		return nil
+	}
+
+	limit, size, err := backupsSizeUsage(ctx, tx, project)
+	if err != nil {
+		return err
+	}
+
+	if size > limit {
+		return api.StatusErrorf(http.StatusBadRequest, "Backup would exceed limit \"limits.backups.size\" of %s for project %q", units.GetByteSizeStringIEC(limit, 2), projectName)
+	}
+
+	return nil
+}
+
+// backupsSizeUsage returns the "limits.backups.size" of the project and the total size of its backups.
+func backupsSizeUsage(ctx context.Context, tx *db.ClusterTx, p *api.Project) (int64, int64, error) {
+	limit, err := units.ParseByteSizeString(p.Config["limits.backups.size"])
+	if err != nil {
+		return -1, -1, fmt.Errorf("Invalid project \"limits.backups.size\" value: %w", err)
+	}
+
+	size, err := tx.GetProjectBackupsSize(ctx, p.Name)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	return limit, size, nil
+}
+
 // AllowSnapshotCreation returns an error if any project-specific restriction is violated
 // when creating a new snapshot in a project.
 func AllowSnapshotCreation(p *api.Project) error {
@@ -1516,6 +1631,85 @@ func AllowSnapshotCreation(p *api.Project) error {
 	return nil
 }
 
+// AllowSnapshotCount returns an error if an instance or custom storage volume which already has the given
+// number of snapshots isn't allowed another one by the "limits.snapshots" of the project.
+func AllowSnapshotCount(p *api.Project, count int) error {
+	return checkCountLimit(p, "limits.snapshots", count)
+}
+
+// AllowStorageBucketCreation returns an error if the "limits.buckets" of the project doesn't allow for
+// creating a new storage bucket.
+func AllowStorageBucketCreation(tx *db.ClusterTx, p *api.Project) error {
+	return allowCountLimit(context.Background(), tx, p, "limits.buckets")
+}
+
+// AllowNetworkForwardCreation returns an error if the "limits.networks.forwards" of the project doesn't allow
+// for creating a new network forward on one of the networks it uses.
+func AllowNetworkForwardCreation(tx *db.ClusterTx, p *api.Project) error {
+	return allowCountLimit(context.Background(), tx, p, "limits.networks.forwards")
+}
+
+// AllowNetworkLoadBalancerCreation returns an error if the "limits.networks.load_balancers" of the project
+// doesn't allow for creating a new network load balancer on one of the networks it uses.
+func AllowNetworkLoadBalancerCreation(tx *db.ClusterTx, p *api.Project) error {
+	return allowCountLimit(context.Background(), tx, p, "limits.networks.load_balancers")
+}
+
+// allowCountLimit returns an error if the count based limit with the given key doesn't allow for creating a
+// new entity in the project.
+func allowCountLimit(ctx context.Context, tx *db.ClusterTx, p *api.Project, key string) error {
+	if p.Config[key] == "" {
+		return nil
+	}
+
+	count, err := countLimitUsage(ctx, tx, p, key)
+	if err != nil {
+		return err
+	}
+
+	return checkCountLimit(p, key, count)
+}
+
+// countLimitUsage returns the current usage of the count based limit with the given key.
+// Network forwards and load balancers are counted by the project which created them, even when that's on the
+// networks of the default project because the project doesn't have "features.networks" enabled.
+func countLimitUsage(ctx context.Context, tx *db.ClusterTx, p *api.Project, key string) (int, error) {
+	switch key {
+	case "limits.buckets":
+		return tx.GetProjectStorageBucketsCount(ctx, p.Name)
+	case "limits.backups":
+		return tx.GetProjectBackupsCount(ctx, p.Name)
+	case "limits.snapshots":
+		return tx.GetProjectMaxSnapshotsCount(ctx, p.Name)
+	case "limits.networks.forwards":
+		return tx.GetProjectNetworkForwardsCount(ctx, p.Name)
+	case "limits.networks.load_balancers":
+		return tx.GetProjectNetworkLoadBalancersCount(ctx, p.Name)
+	}
+
+	return -1, fmt.Errorf("Unknown count limit %q", key)
+}
+
+// checkCountLimit returns an error if the given count of entities has reached the limit set by the given key
+// in the project config. An unset key means no limit.
+func checkCountLimit(p *api.Project, key string, count int) error {
+	value := p.Config[key]
+	if value == "" {
+		return nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("Invalid project %q value: %w", key, err)
+	}
+
+	if count >= limit {
+		return api.StatusErrorf(http.StatusBadRequest, "Limit %q of %d has been reached for project %q", key, limit, p.Name)
+	}
+
+	return nil
+}
+
 // GetRestrictedClusterGroups returns a slice of restricted cluster groups for the given project.
 func GetRestrictedClusterGroups(p *api.Project) []string {
 	return util.SplitNTrimSpace(p.Config["restricted.cluster.groups"], ",", -1, true)
@@ -1526,8 +1720,8 @@ func AllowClusterMember(p *api.Project, member *db.NodeInfo) error {
 	clusterGroupsAllowed := GetRestrictedClusterGroups(p)
 
 	if util.IsTrue(p.Config["restricted"]) && len(clusterGroupsAllowed) > 0 {
-		for _, memberGroupName := range member.Groups {
-			if util.ValueInSlice(memberGroupName, clusterGroupsAllowed) {
+		for _, groupName := range clusterGroupsAllowed {
+			if member.InClusterGroup(groupName) {
 				return nil
 			}
 		}
@@ -1582,6 +1776,12 @@ func CheckTargetGroup(ctx context.Context, tx *db.ClusterTx, p *api.Project, gro
 		return api.StatusErrorf(http.StatusForbidden, err.Error())
 	}
 
+	// Label selectors are dynamic groups which always exist.
+	_, _, isSelector := db.ParseClusterGroupSelector(groupName)
+	if isSelector {
+		return nil
+	}
+
 	// Check if the target group exists.
 	targetGroupExists, err := cluster.ClusterGroupExists(ctx, tx.Tx(), groupName)
 	if err != nil {
@@ -204,3 +204,37 @@ func TestCheckClusterTargetRestriction_RestrictedFalse(t *testing.T) {
 	err = project.CheckClusterTargetRestriction(authorizer, req, p, "n1")
 	assert.NoError(t, err)
 }
+
+// If a storage bucket limit is configured, the check passes below it and fails once it's reached.
+func TestAllowStorageBucketCreation(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	id, err := cluster.CreateProject(ctx, tx.Tx(), cluster.Project{Name: "p1"})
+	require.NoError(t, err)
+
+	err = cluster.CreateProjectConfig(ctx, tx.Tx(), id, map[string]string{"limits.buckets": "2"})
+	require.NoError(t, err)
+
+	_, err = tx.Tx().Exec("INSERT INTO storage_pools (id, name, driver, description) VALUES (1, 'pool1', 'dir', '')")
+	require.NoError(t, err)
+
+	_, err = tx.Tx().Exec("INSERT INTO storage_buckets (name, storage_pool_id, node_id, description, project_id) VALUES ('b1', 1, 1, '', ?)", id)
+	require.NoError(t, err)
+
+	dbProject, err := cluster.GetProject(ctx, tx.Tx(), "p1")
+	require.NoError(t, err)
+
+	p, err := dbProject.ToAPI(ctx, tx.Tx())
+	require.NoError(t, err)
+
+	err = project.AllowStorageBucketCreation(tx, p)
+	assert.NoError(t, err)
+
+	_, err = tx.Tx().Exec("INSERT INTO storage_buckets (name, storage_pool_id, node_id, description, project_id) VALUES ('b2', 1, 1, '', ?)", id)
+	require.NoError(t, err)
+
+	err = project.AllowStorageBucketCreation(tx, p)
+	assert.EqualError(t, err, `Limit "limits.buckets" of 2 has been reached for project "p1"`)
+}
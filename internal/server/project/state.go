@@ -8,6 +8,7 @@ import (
 	"github.com/lxc/incus/internal/server/db"
 	"github.com/lxc/incus/internal/server/instance/instancetype"
 	"github.com/lxc/incus/shared/api"
+	"github.com/lxc/incus/shared/units"
 )
 
 // GetCurrentAllocations returns the current resource utilization for a given project.
@@ -92,5 +93,54 @@ func GetCurrentAllocations(ctx context.Context, tx *db.ClusterTx, projectName st
 		Usage: int64(len(networks[projectName])),
 	}
 
+	// Get the count based limits and usage.
+	countResources := map[string]string{
+		"buckets":                "limits.buckets",
+		"backups":                "limits.backups",
+		"snapshots":              "limits.snapshots",
+		"network-forwards":       "limits.networks.forwards",
+		"network-load-balancers": "limits.networks.load_balancers",
+	}
+
+	for name, key := range countResources {
+		limit = -1
+		value, ok := info.Project.Config[key]
+		if ok {
+			limit, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		count, err := countLimitUsage(ctx, tx, &info.Project, key)
+		if err != nil {
+			return nil, err
+		}
+
+		result[name] = api.ProjectStateResource{
+			Limit: int64(limit),
+			Usage: int64(count),
+		}
+	}
+
+	// Get the backups size limit and usage.
+	backupsSizeLimit := int64(-1)
+	if info.Project.Config["limits.backups.size"] != "" {
+		backupsSizeLimit, err = units.ParseByteSizeString(info.Project.Config["limits.backups.size"])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	backupsSize, err := tx.GetProjectBackupsSize(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	result["backups-size"] = api.ProjectStateResource{
+		Limit: backupsSizeLimit,
+		Usage: backupsSize,
+	}
+
 	return result, nil
 }
@@ -0,0 +1,163 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lxc/incus/shared/api"
+)
+
+// NUMAPlacement represents the NUMA nodes and CPU threads selected for an instance.
+type NUMAPlacement struct {
+	Nodes []int64
+	CPUs  []int64
+}
+
+// PlaceNUMA selects count CPU threads for an instance while keeping it on as few NUMA nodes as possible.
+//
+// The usage map holds the number of instances pinned to each CPU thread. Nodes with the lowest average usage
+// are preferred and the least used threads are picked within the selected nodes.
+// If hugepages isn't zero, the instance memory is split evenly between the selected nodes and each of them must
+// have enough free huge pages for its share of those bytes.
+func PlaceNUMA(cpu *api.ResourcesCPU, memory *api.ResourcesMemory, usage map[int64]int, count int, hugepages uint64) (*NUMAPlacement, error) {
+	if count <= 0 {
+		return nil, fmt.Errorf("Invalid number of CPUs %d", count)
+	}
+
+	// Build a map of the usable CPU threads of each NUMA node.
+	nodeCPUs := map[int64][]int64{}
+	for _, socket := range cpu.Sockets {
+		for _, core := range socket.Cores {
+			for _, thread := range core.Threads {
+				if !thread.Online || thread.Isolated {
+					continue
+				}
+
+				node := int64(thread.NUMANode)
+				nodeCPUs[node] = append(nodeCPUs[node], thread.ID)
+			}
+		}
+	}
+
+	// Get the free huge pages of each NUMA node.
+	nodeHugepages := map[int64]uint64{}
+	if memory != nil {
+		if len(memory.Nodes) > 0 {
+			for _, node := range memory.Nodes {
+				nodeHugepages[int64(node.NUMANode)] = node.HugepagesTotal - node.HugepagesUsed
+			}
+		} else {
+			nodeHugepages[0] = memory.HugepagesTotal - memory.HugepagesUsed
+		}
+	}
+
+	// Sort the candidate nodes from least to most used.
+	load := func(node int64) float64 {
+		total := 0
+		for _, id := range nodeCPUs[node] {
+			total += usage[id]
+		}
+
+		return float64(total) / float64(len(nodeCPUs[node]))
+	}
+
+	candidates := make([]int64, 0, len(nodeCPUs))
+	for node := range nodeCPUs {
+		candidates = append(candidates, node)
+	}
+
+	sort.Slice(candidates, func(i int, j int) bool {
+		loadI := load(candidates[i])
+		loadJ := load(candidates[j])
+		if loadI != loadJ {
+			return loadI < loadJ
+		}
+
+		if len(nodeCPUs[candidates[i]]) != len(nodeCPUs[candidates[j]]) {
+			return len(nodeCPUs[candidates[i]]) > len(nodeCPUs[candidates[j]])
+		}
+
+		return candidates[i] < candidates[j]
+	})
+
+	// Prefer a single node, otherwise span as few of the least used nodes as possible.
+	var selected []int64
+	for size := 1; size <= len(candidates) && selected == nil; size++ {
+		selected = numaSelectNodes(candidates, nodeCPUs, nodeHugepages, size, count, hugepages)
+	}
+
+	if selected == nil {
+		totalCPUs := 0
+		for _, node := range candidates {
+			totalCPUs += len(nodeCPUs[node])
+		}
+
+		if totalCPUs < count {
+			return nil, fmt.Errorf("Not enough CPUs available (requested %d, available %d)", count, totalCPUs)
+		}
+
+		return nil, fmt.Errorf("Not enough free huge pages available on the NUMA nodes")
+	}
+
+	// Pick the least used threads of the selected nodes.
+	threads := []int64{}
+	for _, node := range selected {
+		threads = append(threads, nodeCPUs[node]...)
+	}
+
+	sort.Slice(threads, func(i int, j int) bool {
+		if usage[threads[i]] != usage[threads[j]] {
+			return usage[threads[i]] < usage[threads[j]]
+		}
+
+		return threads[i] < threads[j]
+	})
+
+	placement := &NUMAPlacement{
+		Nodes: selected,
+		CPUs:  threads[:count],
+	}
+
+	sort.Slice(placement.Nodes, func(i int, j int) bool { return placement.Nodes[i] < placement.Nodes[j] })
+	sort.Slice(placement.CPUs, func(i int, j int) bool { return placement.CPUs[i] < placement.CPUs[j] })
+
+	return placement, nil
+}
+
+// numaSelectNodes returns the first size candidate nodes that each have enough free huge pages for their share
+// of the instance memory, provided they have at least count CPU threads between them.
+// Returns nil if there's no such set of nodes.
+func numaSelectNodes(candidates []int64, nodeCPUs map[int64][]int64, nodeHugepages map[int64]uint64, size int, count int, hugepages uint64) []int64 {
+	share := (hugepages + uint64(size) - 1) / uint64(size)
+
+	// With a single node, look for one with enough CPU threads rather than just the least used one.
+	if size == 1 {
+		for _, node := range candidates {
+			if len(nodeCPUs[node]) >= count && nodeHugepages[node] >= share {
+				return []int64{node}
+			}
+		}
+
+		return nil
+	}
+
+	selected := []int64{}
+	totalCPUs := 0
+	for _, node := range candidates {
+		if nodeHugepages[node] < share {
+			continue
+		}
+
+		selected = append(selected, node)
+		totalCPUs += len(nodeCPUs[node])
+		if len(selected) == size {
+			break
+		}
+	}
+
+	if len(selected) < size || totalCPUs < count {
+		return nil
+	}
+
+	return selected
+}
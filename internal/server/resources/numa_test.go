@@ -0,0 +1,153 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lxc/incus/shared/api"
+)
+
+// numaTestCPU returns a CPU with the given number of NUMA nodes and threads per node.
+// Threads are numbered sequentially, starting with the ones of node 0.
+func numaTestCPU(nodes int, threads int) *api.ResourcesCPU {
+	socket := api.ResourcesCPUSocket{}
+
+	for node := 0; node < nodes; node++ {
+		for thread := 0; thread < threads; thread++ {
+			socket.Cores = append(socket.Cores, api.ResourcesCPUCore{
+				Threads: []api.ResourcesCPUThread{{
+					ID:       int64(node*threads + thread),
+					NUMANode: uint64(node),
+					Online:   true,
+				}},
+			})
+		}
+	}
+
+	return &api.ResourcesCPU{Sockets: []api.ResourcesCPUSocket{socket}}
+}
+
+func TestPlaceNUMA(t *testing.T) {
+	isolated := numaTestCPU(2, 2)
+	isolated.Sockets[0].Cores[0].Threads[0].Isolated = true
+	isolated.Sockets[0].Cores[1].Threads[0].Online = false
+
+	hugepages := &api.ResourcesMemory{
+		Nodes: []api.ResourcesMemoryNode{
+			{NUMANode: 0, HugepagesTotal: 1024, HugepagesUsed: 1024},
+			{NUMANode: 1, HugepagesTotal: 4096, HugepagesUsed: 1024},
+		},
+	}
+
+	spanHugepages := &api.ResourcesMemory{
+		Nodes: []api.ResourcesMemoryNode{
+			{NUMANode: 0, HugepagesTotal: 4096, HugepagesUsed: 4096},
+			{NUMANode: 1, HugepagesTotal: 2048},
+			{NUMANode: 2, HugepagesTotal: 2048},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		cpu       *api.ResourcesCPU
+		memory    *api.ResourcesMemory
+		usage     map[int64]int
+		count     int
+		hugepages uint64
+		expected  *NUMAPlacement
+		err       string
+	}{
+		{
+			name:     "Idle host uses the first node",
+			cpu:      numaTestCPU(2, 4),
+			count:    2,
+			expected: &NUMAPlacement{Nodes: []int64{0}, CPUs: []int64{0, 1}},
+		},
+		{
+			name:     "Least used node is preferred",
+			cpu:      numaTestCPU(2, 4),
+			usage:    map[int64]int{0: 1, 1: 1},
+			count:    2,
+			expected: &NUMAPlacement{Nodes: []int64{1}, CPUs: []int64{4, 5}},
+		},
+		{
+			name:     "Least used threads are picked within the node",
+			cpu:      numaTestCPU(1, 4),
+			usage:    map[int64]int{0: 2, 1: 1},
+			count:    2,
+			expected: &NUMAPlacement{Nodes: []int64{0}, CPUs: []int64{2, 3}},
+		},
+		{
+			name:     "Instances larger than a node span nodes",
+			cpu:      numaTestCPU(3, 2),
+			usage:    map[int64]int{0: 1},
+			count:    3,
+			expected: &NUMAPlacement{Nodes: []int64{1, 2}, CPUs: []int64{2, 3, 4}},
+		},
+		{
+			name:     "Isolated and offline threads are skipped",
+			cpu:      isolated,
+			count:    2,
+			expected: &NUMAPlacement{Nodes: []int64{1}, CPUs: []int64{2, 3}},
+		},
+		{
+			name:      "Node with enough huge pages is preferred",
+			cpu:       numaTestCPU(2, 2),
+			memory:    hugepages,
+			count:     1,
+			hugepages: 2048,
+			expected:  &NUMAPlacement{Nodes: []int64{1}, CPUs: []int64{2}},
+		},
+		{
+			name:      "Spanning nodes only uses the ones with enough huge pages for their share",
+			cpu:       numaTestCPU(3, 2),
+			memory:    spanHugepages,
+			count:     3,
+			hugepages: 4096,
+			expected:  &NUMAPlacement{Nodes: []int64{1, 2}, CPUs: []int64{2, 3, 4}},
+		},
+		{
+			name:      "Huge pages are checked on each spanned node",
+			cpu:       numaTestCPU(2, 2),
+			memory:    hugepages,
+			count:     4,
+			hugepages: 3072,
+			err:       "Not enough free huge pages available on the NUMA nodes",
+		},
+		{
+			name:      "Not enough huge pages",
+			cpu:       numaTestCPU(2, 2),
+			memory:    hugepages,
+			count:     1,
+			hugepages: 8192,
+			err:       "Not enough free huge pages available on the NUMA nodes",
+		},
+		{
+			name:  "Not enough CPUs",
+			cpu:   numaTestCPU(2, 2),
+			count: 5,
+			err:   "Not enough CPUs available (requested 5, available 4)",
+		},
+		{
+			name:  "Invalid number of CPUs",
+			cpu:   numaTestCPU(1, 1),
+			count: 0,
+			err:   "Invalid number of CPUs 0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			placement, err := PlaceNUMA(test.cpu, test.memory, test.usage, test.count, test.hugepages)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, placement)
+		})
+	}
+}
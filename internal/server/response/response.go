@@ -524,9 +524,22 @@ func (r *forwardedResponse) Render(w http.ResponseWriter) error {
 		w.Header().Set(key, response.Header.Get(key))
 	}
 
+	// Announce the trailers, their values are only known once the body was read.
+	for key := range response.Trailer {
+		w.Header().Add("Trailer", key)
+	}
+
 	w.WriteHeader(response.StatusCode)
 	_, err = io.Copy(w, response.Body)
-	return err
+	if err != nil {
+		return err
+	}
+
+	for key := range response.Trailer {
+		w.Header().Set(key, response.Trailer.Get(key))
+	}
+
+	return nil
 }
 
 func (r *forwardedResponse) String() string {
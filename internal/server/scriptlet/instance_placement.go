@@ -3,14 +3,15 @@ package scriptlet
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strconv"
-	"strings"
 
 	"go.starlark.net/starlark"
 
 	"github.com/lxc/incus/internal/instance"
 	"github.com/lxc/incus/internal/server/cluster"
 	"github.com/lxc/incus/internal/server/db"
+	dbCluster "github.com/lxc/incus/internal/server/db/cluster"
 	instanceDrivers "github.com/lxc/incus/internal/server/instance/drivers"
 	"github.com/lxc/incus/internal/server/resources"
 	scriptletLoad "github.com/lxc/incus/internal/server/scriptlet/load"
@@ -27,31 +28,13 @@ func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State,
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	logFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-		var sb strings.Builder
-		for _, arg := range args {
-			s, err := strconv.Unquote(arg.String())
-			if err != nil {
-				s = arg.String()
-			}
-
-			sb.WriteString(s)
-		}
-
-		switch b.Name() {
-		case "log_info":
-			l.Info(fmt.Sprintf("Instance placement scriptlet: %s", sb.String()))
-		case "log_warn":
-			l.Warn(fmt.Sprintf("Instance placement scriptlet: %s", sb.String()))
-		default:
-			l.Error(fmt.Sprintf("Instance placement scriptlet: %s", sb.String()))
-		}
-
-		return starlark.None, nil
-	}
+	logFunc := newLogFunc(l, "Instance placement")
 
 	var targetMember *db.NodeInfo
 
+	// Values stored by the scriptlet, only committed once it has run successfully.
+	pendingData := map[string]string{}
+
 	setTargetFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		var memberName string
 
@@ -242,6 +225,189 @@ func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State,
 		return rv, nil
 	}
 
+	getInstancesFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var location string
+		var projectName string
+
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "location??", &location, "project??", &projectName)
+		if err != nil {
+			return nil, err
+		}
+
+		var filters []dbCluster.InstanceFilter
+		if location != "" || projectName != "" {
+			filter := dbCluster.InstanceFilter{}
+
+			if location != "" {
+				filter.Node = &location
+			}
+
+			if projectName != "" {
+				filter.Project = &projectName
+			}
+
+			filters = append(filters, filter)
+		}
+
+		instances := []api.Instance{}
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			dbInstances, err := dbCluster.GetInstances(ctx, tx.Tx(), filters...)
+			if err != nil {
+				return fmt.Errorf("Failed loading instances: %w", err)
+			}
+
+			for _, dbInst := range dbInstances {
+				inst, err := dbInst.ToAPI(ctx, tx.Tx())
+				if err != nil {
+					return fmt.Errorf("Failed loading instance %q in project %q: %w", dbInst.Name, dbInst.Project, err)
+				}
+
+				instances = append(instances, *inst)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rv, err := StarlarkMarshal(instances)
+		if err != nil {
+			return nil, fmt.Errorf("Marshalling instances failed: %w", err)
+		}
+
+		return rv, nil
+	}
+
+	getProjectFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var projectName string
+
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &projectName)
+		if err != nil {
+			return nil, err
+		}
+
+		var p *api.Project
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+			if err != nil {
+				return err
+			}
+
+			p, err = dbProject.ToAPI(ctx, tx.Tx())
+
+			return err
+		})
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return starlark.None, nil
+			}
+
+			return nil, fmt.Errorf("Failed loading project %q: %w", projectName, err)
+		}
+
+		rv, err := StarlarkMarshal(p)
+		if err != nil {
+			return nil, fmt.Errorf("Marshalling project %q failed: %w", projectName, err)
+		}
+
+		return rv, nil
+	}
+
+	getClusterGroupsFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		err := starlark.UnpackArgs(b.Name(), args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+
+		groups := []api.ClusterGroup{}
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			dbGroups, err := dbCluster.GetClusterGroups(ctx, tx.Tx())
+			if err != nil {
+				return fmt.Errorf("Failed loading cluster groups: %w", err)
+			}
+
+			for _, dbGroup := range dbGroups {
+				dbGroup.Nodes, err = tx.GetClusterGroupNodes(ctx, dbGroup.Name)
+				if err != nil {
+					return fmt.Errorf("Failed loading members of cluster group %q: %w", dbGroup.Name, err)
+				}
+
+				group, err := dbGroup.ToAPI()
+				if err != nil {
+					return err
+				}
+
+				groups = append(groups, *group)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		rv, err := StarlarkMarshal(groups)
+		if err != nil {
+			return nil, fmt.Errorf("Marshalling cluster groups failed: %w", err)
+		}
+
+		return rv, nil
+	}
+
+	getDataFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var key string
+
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &key)
+		if err != nil {
+			return nil, err
+		}
+
+		// Values set earlier in this run take precedence over the stored ones.
+		value, ok := pendingData[key]
+		if ok {
+			if value == "" {
+				return starlark.None, nil
+			}
+
+			return starlark.String(value), nil
+		}
+
+		var data map[string]string
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			data, err = tx.GetScriptletData(ctx, scriptletLoad.NameInstancePlacement)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok = data[key]
+		if !ok {
+			return starlark.None, nil
+		}
+
+		return starlark.String(value), nil
+	}
+
+	setDataFunc := func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var key string
+		var value string
+
+		err := starlark.UnpackArgs(b.Name(), args, kwargs, "key", &key, "value", &value)
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "" {
+			return nil, fmt.Errorf("Key cannot be empty")
+		}
+
+		pendingData[key] = value
+
+		return starlark.None, nil
+	}
+
 	var err error
 	var raftNodes []db.RaftNode
 	err = s.DB.Node.Transaction(ctx, func(ctx context.Context, tx *db.NodeTx) error {
@@ -307,6 +473,11 @@ func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State,
 		"get_cluster_member_resources": starlark.NewBuiltin("get_cluster_member_resources", getClusterMemberResourcesFunc),
 		"get_cluster_member_state":     starlark.NewBuiltin("get_cluster_member_state", getClusterMemberStateFunc),
 		"get_instance_resources":       starlark.NewBuiltin("get_instance_resources", getInstanceResourcesFunc),
+		"get_instances":                starlark.NewBuiltin("get_instances", getInstancesFunc),
+		"get_project":                  starlark.NewBuiltin("get_project", getProjectFunc),
+		"get_cluster_groups":           starlark.NewBuiltin("get_cluster_groups", getClusterGroupsFunc),
+		"get_data":                     starlark.NewBuiltin("get_data", getDataFunc),
+		"set_data":                     starlark.NewBuiltin("set_data", setDataFunc),
 	}
 
 	prog, thread, err := scriptletLoad.InstancePlacementProgram()
@@ -360,5 +531,15 @@ func InstancePlacementRun(ctx context.Context, l logger.Logger, s *state.State,
 		return nil, fmt.Errorf("Failed with unexpected return value: %v", v)
 	}
 
+	// Store the values set by the scriptlet now that the placement succeeded.
+	if len(pendingData) > 0 {
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateScriptletData(ctx, scriptletLoad.NameInstancePlacement, pendingData)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed storing scriptlet data: %w", err)
+		}
+	}
+
 	return targetMember, nil
 }
@@ -9,25 +9,23 @@ import (
 	"github.com/lxc/incus/shared/util"
 )
 
-// nameInstancePlacement is the name used in Starlark for the instance placement scriptlet.
-const nameInstancePlacement = "instance_placement"
+// NameInstancePlacement is the name used in Starlark for the instance placement scriptlet.
+const NameInstancePlacement = "instance_placement"
 
-// InstancePlacementCompile compiles the instance placement scriptlet.
-func InstancePlacementCompile(src string) (*starlark.Program, error) {
+// NameInstanceValidation is the name used in Starlark for the instance validation scriptlet.
+const NameInstanceValidation = "instance_validation"
+
+// NameProjectValidation is the name used in Starlark for the project validation scriptlet.
+const NameProjectValidation = "project_validation"
+
+// compile compiles a scriptlet, only allowing the given predeclared names.
+func compile(programName string, src string, preDeclared []string) (*starlark.Program, error) {
 	isPreDeclared := func(name string) bool {
-		return util.ValueInSlice(name, []string{
-			"log_info",
-			"log_warn",
-			"log_error",
-			"set_target",
-			"get_cluster_member_resources",
-			"get_cluster_member_state",
-			"get_instance_resources",
-		})
+		return util.ValueInSlice(name, preDeclared)
 	}
 
 	// Parse, resolve, and compile a Starlark source file.
-	_, mod, err := starlark.SourceProgram(nameInstancePlacement, src, isPreDeclared)
+	_, mod, err := starlark.SourceProgram(programName, src, isPreDeclared)
 	if err != nil {
 		return nil, err
 	}
@@ -35,46 +33,126 @@ func InstancePlacementCompile(src string) (*starlark.Program, error) {
 	return mod, nil
 }
 
-// InstancePlacementValidate validates the instance placement scriptlet.
-func InstancePlacementValidate(src string) error {
-	_, err := InstancePlacementCompile(src)
-	return err
-}
-
 var programsMu sync.Mutex
 var programs = make(map[string]*starlark.Program)
 
-// InstancePlacementSet compiles the instance placement scriptlet into memory for use with InstancePlacementRun.
-// If empty src is provided the current program is deleted.
-func InstancePlacementSet(src string) error {
+// set compiles a scriptlet into memory. If empty src is provided the current program is deleted.
+func set(compiler func(string) (*starlark.Program, error), programName string, src string) error {
 	if src == "" {
 		programsMu.Lock()
-		delete(programs, nameInstancePlacement)
+		delete(programs, programName)
 		programsMu.Unlock()
 	} else {
-		prog, err := InstancePlacementCompile(src)
+		prog, err := compiler(src)
 		if err != nil {
 			return err
 		}
 
 		programsMu.Lock()
-		programs[nameInstancePlacement] = prog
+		programs[programName] = prog
 		programsMu.Unlock()
 	}
 
 	return nil
 }
 
-// InstancePlacementProgram returns the precompiled instance placement scriptlet program.
-func InstancePlacementProgram() (*starlark.Program, *starlark.Thread, error) {
+// program returns a precompiled scriptlet program.
+func program(name string, programName string) (*starlark.Program, *starlark.Thread, error) {
 	programsMu.Lock()
-	prog, found := programs[nameInstancePlacement]
+	prog, found := programs[programName]
 	programsMu.Unlock()
 	if !found {
-		return nil, nil, fmt.Errorf("Instance placement scriptlet not loaded")
+		return nil, nil, fmt.Errorf("%s scriptlet not loaded", name)
 	}
 
-	thread := &starlark.Thread{Name: nameInstancePlacement}
+	thread := &starlark.Thread{Name: programName}
 
 	return prog, thread, nil
 }
+
+// InstancePlacementCompile compiles the instance placement scriptlet.
+func InstancePlacementCompile(src string) (*starlark.Program, error) {
+	return compile(NameInstancePlacement, src, []string{
+		"log_info",
+		"log_warn",
+		"log_error",
+		"set_target",
+		"get_cluster_member_resources",
+		"get_cluster_member_state",
+		"get_instance_resources",
+		"get_instances",
+		"get_project",
+		"get_cluster_groups",
+		"get_data",
+		"set_data",
+	})
+}
+
+// InstancePlacementValidate validates the instance placement scriptlet.
+func InstancePlacementValidate(src string) error {
+	_, err := InstancePlacementCompile(src)
+	return err
+}
+
+// InstancePlacementSet compiles the instance placement scriptlet into memory for use with InstancePlacementRun.
+// If empty src is provided the current program is deleted.
+func InstancePlacementSet(src string) error {
+	return set(InstancePlacementCompile, NameInstancePlacement, src)
+}
+
+// InstancePlacementProgram returns the precompiled instance placement scriptlet program.
+func InstancePlacementProgram() (*starlark.Program, *starlark.Thread, error) {
+	return program("Instance placement", NameInstancePlacement)
+}
+
+// InstanceValidationCompile compiles the instance validation scriptlet.
+func InstanceValidationCompile(src string) (*starlark.Program, error) {
+	return compile(NameInstanceValidation, src, []string{
+		"log_info",
+		"log_warn",
+		"log_error",
+	})
+}
+
+// InstanceValidationValidate validates the instance validation scriptlet.
+func InstanceValidationValidate(src string) error {
+	_, err := InstanceValidationCompile(src)
+	return err
+}
+
+// InstanceValidationSet compiles the instance validation scriptlet into memory for use with InstanceValidationRun.
+// If empty src is provided the current program is deleted.
+func InstanceValidationSet(src string) error {
+	return set(InstanceValidationCompile, NameInstanceValidation, src)
+}
+
+// InstanceValidationProgram returns the precompiled instance validation scriptlet program.
+func InstanceValidationProgram() (*starlark.Program, *starlark.Thread, error) {
+	return program("Instance validation", NameInstanceValidation)
+}
+
+// ProjectValidationCompile compiles the project validation scriptlet.
+func ProjectValidationCompile(src string) (*starlark.Program, error) {
+	return compile(NameProjectValidation, src, []string{
+		"log_info",
+		"log_warn",
+		"log_error",
+	})
+}
+
+// ProjectValidationValidate validates the project validation scriptlet.
+func ProjectValidationValidate(src string) error {
+	_, err := ProjectValidationCompile(src)
+	return err
+}
+
+// ProjectValidationSet compiles the project validation scriptlet into memory for use with ProjectValidationRun.
+// If empty src is provided the current program is deleted.
+func ProjectValidationSet(src string) error {
+	return set(ProjectValidationCompile, NameProjectValidation, src)
+}
+
+// ProjectValidationProgram returns the precompiled project validation scriptlet program.
+func ProjectValidationProgram() (*starlark.Program, *starlark.Thread, error) {
+	return program("Project validation", NameProjectValidation)
+}
@@ -0,0 +1,38 @@
+package scriptlet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/lxc/incus/shared/logger"
+)
+
+// newLogFunc returns the implementation of the log_info, log_warn and log_error builtins.
+// Messages are prefixed with the given scriptlet name.
+func newLogFunc(l logger.Logger, name string) func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var sb strings.Builder
+		for _, arg := range args {
+			s, err := strconv.Unquote(arg.String())
+			if err != nil {
+				s = arg.String()
+			}
+
+			sb.WriteString(s)
+		}
+
+		switch b.Name() {
+		case "log_info":
+			l.Info(fmt.Sprintf("%s scriptlet: %s", name, sb.String()))
+		case "log_warn":
+			l.Warn(fmt.Sprintf("%s scriptlet: %s", name, sb.String()))
+		default:
+			l.Error(fmt.Sprintf("%s scriptlet: %s", name, sb.String()))
+		}
+
+		return starlark.None, nil
+	}
+}
@@ -0,0 +1,94 @@
+package scriptlet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.starlark.net/starlark"
+
+	scriptletLoad "github.com/lxc/incus/internal/server/scriptlet/load"
+	"github.com/lxc/incus/shared/api"
+	apiScriptlet "github.com/lxc/incus/shared/api/scriptlet"
+	"github.com/lxc/incus/shared/logger"
+)
+
+// InstanceValidationRun runs the instance validation scriptlet.
+// If the scriptlet rejects the request, a bad request error with the scriptlet's message is returned.
+func InstanceValidationRun(ctx context.Context, l logger.Logger, req *apiScriptlet.InstanceValidation) error {
+	prog, thread, err := scriptletLoad.InstanceValidationProgram()
+	if err != nil {
+		return err
+	}
+
+	return validationRun(ctx, l, "Instance validation", prog, thread, req)
+}
+
+// ProjectValidationRun runs the project validation scriptlet.
+// If the scriptlet rejects the request, a bad request error with the scriptlet's message is returned.
+func ProjectValidationRun(ctx context.Context, l logger.Logger, req *apiScriptlet.ProjectValidation) error {
+	prog, thread, err := scriptletLoad.ProjectValidationProgram()
+	if err != nil {
+		return err
+	}
+
+	return validationRun(ctx, l, "Project validation", prog, thread, req)
+}
+
+// validationRun calls the function named after the thread in the validation scriptlet with the request.
+// The function must return None to accept the request or a string explaining why it is rejected.
+func validationRun(ctx context.Context, l logger.Logger, name string, prog *starlark.Program, thread *starlark.Thread, req any) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	logFunc := newLogFunc(l, name)
+
+	env := starlark.StringDict{
+		"log_info":  starlark.NewBuiltin("log_info", logFunc),
+		"log_warn":  starlark.NewBuiltin("log_warn", logFunc),
+		"log_error": starlark.NewBuiltin("log_error", logFunc),
+	}
+
+	go func() {
+		<-ctx.Done()
+		thread.Cancel("Request finished")
+	}()
+
+	globals, err := prog.Init(thread, env)
+	if err != nil {
+		return fmt.Errorf("%s scriptlet failed initializing: %w", name, err)
+	}
+
+	globals.Freeze()
+
+	// Retrieve a global variable from starlark environment.
+	validation := globals[thread.Name]
+	if validation == nil {
+		return fmt.Errorf("%s scriptlet missing %s function", name, thread.Name)
+	}
+
+	rv, err := StarlarkMarshal(req)
+	if err != nil {
+		return fmt.Errorf("%s scriptlet marshalling request failed: %w", name, err)
+	}
+
+	// Call starlark function from Go.
+	v, err := starlark.Call(thread, validation, nil, []starlark.Tuple{
+		{
+			starlark.String("request"),
+			rv,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%s scriptlet failed to run: %w", name, err)
+	}
+
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil
+	case starlark.String:
+		return api.StatusErrorf(http.StatusBadRequest, "Rejected by %s scriptlet: %s", thread.Name, v.GoString())
+	default:
+		return fmt.Errorf("%s scriptlet failed with unexpected return value: %v", name, v)
+	}
+}
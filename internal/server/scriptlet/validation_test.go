@@ -0,0 +1,81 @@
+package scriptlet
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	scriptletLoad "github.com/lxc/incus/internal/server/scriptlet/load"
+	"github.com/lxc/incus/shared/api"
+	apiScriptlet "github.com/lxc/incus/shared/api/scriptlet"
+	"github.com/lxc/incus/shared/logger"
+)
+
+func TestInstanceValidationRun(t *testing.T) {
+	src := `
+def instance_validation(request):
+    if not request.name.startswith(request.project + "-"):
+        return "Instance names must start with the project name"
+
+    if request.config.get("user.owner", "") == "":
+        return "Missing user.owner"
+
+    for name, device in request.devices.items():
+        if device["type"] == "unix-char":
+            return "Device %s isn't allowed" % name
+
+    return
+`
+
+	err := scriptletLoad.InstanceValidationSet(src)
+	require.NoError(t, err)
+	defer func() { _ = scriptletLoad.InstanceValidationSet("") }()
+
+	req := &apiScriptlet.InstanceValidation{
+		InstancePut: api.InstancePut{
+			Config:  map[string]string{"user.owner": "alice"},
+			Devices: map[string]map[string]string{"root": {"type": "disk", "path": "/", "pool": "default"}},
+		},
+		Name:    "web-c1",
+		Type:    "container",
+		Reason:  apiScriptlet.InstanceValidationReasonCreate,
+		Project: "web",
+	}
+
+	err = InstanceValidationRun(context.Background(), logger.Log, req)
+	assert.NoError(t, err)
+
+	req.Name = "c1"
+	err = InstanceValidationRun(context.Background(), logger.Log, req)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusBadRequest))
+	assert.ErrorContains(t, err, "Instance names must start with the project name")
+
+	req.Name = "web-c1"
+	req.Devices["tty"] = map[string]string{"type": "unix-char", "path": "/dev/tty0"}
+	err = InstanceValidationRun(context.Background(), logger.Log, req)
+	assert.ErrorContains(t, err, "Device tty isn't allowed")
+}
+
+func TestProjectValidationRun(t *testing.T) {
+	err := scriptletLoad.ProjectValidationSet(`
+def project_validation(request):
+    return 1
+`)
+	require.NoError(t, err)
+	defer func() { _ = scriptletLoad.ProjectValidationSet("") }()
+
+	// Only None or a string can be returned.
+	err = ProjectValidationRun(context.Background(), logger.Log, &apiScriptlet.ProjectValidation{Name: "foo"})
+	assert.ErrorContains(t, err, "unexpected return value")
+	assert.False(t, api.StatusErrorCheck(err, http.StatusBadRequest))
+
+	// Unknown builtins are rejected when loading the scriptlet.
+	err = scriptletLoad.ProjectValidationSet(`
+def project_validation(request):
+    set_target("foo")
+`)
+	assert.Error(t, err)
+}
@@ -380,6 +380,30 @@ var APIExtensions = []string{
 	"event_lifecycle_name_and_project",
 	"instances_nic_limits_priority",
 	"disk_initial_volume_configuration",
+	"network_wireguard",
+	"network_flows",
+	"network_zones_dnssec",
+	"network_dns_resolver",
+	"network_ipv6_prefix_delegation",
+	"vm_memory_hotplug",
+	"instance_debug_memory",
+	"console_vga_screenshot",
+	"container_live_migration_precopy",
+	"instance_cpu_numa_balanced",
+	"vm_cpu_hotunplug",
+	"vm_pci_usb_hotplug",
+	"instance_usage_history",
+	"container_stateful_publish",
+	"cluster_rebalance",
+	"instance_placement_scriptlet_data",
+	"instance_placement_groups",
+	"cluster_healing_fencing",
+	"validation_scriptlets",
+	"projects_limits_extended",
+	"projects_usage_history",
+	"cluster_maintenance_windows",
+	"cluster_rolling_upgrade",
+	"cluster_member_labels",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
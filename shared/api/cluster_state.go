@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // ClusterMemberSysInfo represents the sysinfo of a cluster member.
 //
 // swagger:model
@@ -25,4 +29,40 @@ type ClusterMemberSysInfo struct {
 type ClusterMemberState struct {
 	SysInfo      ClusterMemberSysInfo        `json:"sysinfo" yaml:"sysinfo"`
 	StoragePools map[string]StoragePoolState `json:"storage_pools" yaml:"storage_pools"`
+
+	// Actions taken while healing the cluster member, oldest first
+	//
+	// API extension: cluster_healing_fencing
+	Healing []ClusterMemberHealingAction `json:"healing" yaml:"healing"`
+}
+
+// ClusterMemberHealingAction represents an action taken while healing an offline cluster member.
+//
+// swagger:model
+//
+// API extension: cluster_healing_fencing.
+type ClusterMemberHealingAction struct {
+	// When the action was taken
+	// Example: 2024-01-02T10:00:00Z
+	Date time.Time `json:"date" yaml:"date"`
+
+	// Action taken (one of "fenced", "fencing-failed", "instance-moved", "instance-failed" or "healed")
+	// Example: instance-moved
+	Action string `json:"action" yaml:"action"`
+
+	// Project of the instance the action applies to
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Instance the action applies to
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Cluster member the instance was moved to
+	// Example: server02
+	Target string `json:"target" yaml:"target"`
+
+	// Additional details
+	// Example: Fence command failed: exit status 1
+	Message string `json:"message" yaml:"message"`
 }
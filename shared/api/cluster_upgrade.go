@@ -0,0 +1,77 @@
+package api
+
+import (
+	"time"
+)
+
+// ClusterUpgrade represents the state of a rolling upgrade of the cluster.
+//
+// swagger:model
+//
+// API extension: cluster_rolling_upgrade.
+type ClusterUpgrade struct {
+	// Status of the rolling upgrade (idle, running, blocked or completed)
+	// Example: running
+	Status string `json:"status" yaml:"status"`
+
+	// Cluster members in upgrade order
+	Members []ClusterUpgradeMember `json:"members" yaml:"members"`
+
+	// Reasons preventing a rolling upgrade from starting or proceeding
+	// Example: ["Cluster member \"server03\" is offline"]
+	Blockers []string `json:"blockers" yaml:"blockers"`
+}
+
+// ClusterUpgradeMember represents the upgrade state of a cluster member.
+//
+// swagger:model
+//
+// API extension: cluster_rolling_upgrade.
+type ClusterUpgradeMember struct {
+	// Name of the cluster member
+	// Example: server01
+	Name string `json:"name" yaml:"name"`
+
+	// Version of the daemon running on the member (empty if unreachable)
+	// Example: 0.2
+	Version string `json:"version" yaml:"version"`
+
+	// Database schema version of the member
+	// Example: 74
+	Schema int `json:"schema" yaml:"schema"`
+
+	// Number of API extensions supported by the member
+	// Example: 380
+	APIExtensions int `json:"api_extensions" yaml:"api_extensions"`
+
+	// Status of the cluster member
+	// Example: Online
+	Status string `json:"status" yaml:"status"`
+
+	// Rolling upgrade state of the member (pending, evacuating, upgrading, rejoining, restoring or done)
+	// Example: upgrading
+	UpgradeState string `json:"upgrade_state" yaml:"upgrade_state"`
+
+	// Error preventing the rolling upgrade of the member from proceeding
+	// Example: Failed evacuating cluster member
+	UpgradeError string `json:"upgrade_error" yaml:"upgrade_error"`
+
+	// When the upgrade state of the member last changed
+	// Example: 2023-11-01T10:00:00Z
+	UpgradeDate time.Time `json:"upgrade_date" yaml:"upgrade_date"`
+}
+
+// ClusterUpgradePost represents the fields required to drive a rolling upgrade of the cluster.
+//
+// swagger:model
+//
+// API extension: cluster_rolling_upgrade.
+type ClusterUpgradePost struct {
+	// The action to be performed. Valid actions are "start", "upgraded", "resume" and "abort".
+	// Example: upgraded
+	Action string `json:"action" yaml:"action"`
+
+	// Name of the cluster member whose binary was replaced (only for the "upgraded" action)
+	// Example: server01
+	Member string `json:"member" yaml:"member"`
+}
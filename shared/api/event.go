@@ -8,10 +8,11 @@ import (
 
 // Event types.
 const (
-	EventTypeLifecycle  = "lifecycle"
-	EventTypeLogging    = "logging"
-	EventTypeOperation  = "operation"
-	EventTypeNetworkACL = "network-acl"
+	EventTypeLifecycle   = "lifecycle"
+	EventTypeLogging     = "logging"
+	EventTypeOperation   = "operation"
+	EventTypeNetworkACL  = "network-acl"
+	EventTypeNetworkFlow = "network-flow"
 )
 
 // Event represents an event entry (over websocket)
@@ -45,7 +46,7 @@ type Event struct {
 
 // ToLogging creates log record for the event.
 func (event *Event) ToLogging() (EventLogRecord, error) {
-	if event.Type == EventTypeLogging || event.Type == EventTypeNetworkACL {
+	if event.Type == EventTypeLogging || event.Type == EventTypeNetworkACL || event.Type == EventTypeNetworkFlow {
 		e := &EventLogging{}
 		err := json.Unmarshal(event.Metadata, &e)
 		if err != nil {
@@ -13,6 +13,10 @@ const (
 	EventLifecycleClusterGroupRenamed               = "cluster-group-renamed"
 	EventLifecycleClusterGroupUpdated               = "cluster-group-updated"
 	EventLifecycleClusterMemberAdded                = "cluster-member-added"
+	EventLifecycleClusterMemberFenced               = "cluster-member-fenced"
+	EventLifecycleClusterMemberHealed               = "cluster-member-healed"
+	EventLifecycleClusterMemberMaintenanceStarted   = "cluster-member-maintenance-started"
+	EventLifecycleClusterMemberMaintenanceEnded     = "cluster-member-maintenance-ended"
 	EventLifecycleClusterMemberRemoved              = "cluster-member-removed"
 	EventLifecycleClusterMemberRenamed              = "cluster-member-renamed"
 	EventLifecycleClusterMemberUpdated              = "cluster-member-updated"
@@ -62,6 +62,12 @@ type ImagesPost struct {
 	//
 	// API extension: image_create_aliases
 	Aliases []ImageAlias `json:"aliases" yaml:"aliases"`
+
+	// Whether to include the runtime state of the container in the image
+	// Example: false
+	//
+	// API extension: container_stateful_publish
+	Stateful bool `json:"stateful" yaml:"stateful"`
 }
 
 // ImagesPostSource represents the source of a new image
@@ -0,0 +1,16 @@
+package api
+
+// InstanceDebugMemoryPost represents a guest memory dump request.
+//
+// swagger:model
+//
+// API extension: instance_debug_memory.
+type InstanceDebugMemoryPost struct {
+	// Format of the dump (elf, kdump-zlib, kdump-lzo, kdump-snappy or win-dmp)
+	// Example: elf
+	Format string `json:"format" yaml:"format"`
+
+	// Whether to leave the instance paused once the dump is complete
+	// Example: false
+	Pause bool `json:"pause" yaml:"pause"`
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"time"
+)
+
+// InstanceUsage represents the resource usage history of an instance.
+//
+// swagger:model
+//
+// API extension: instance_usage_history.
+type InstanceUsage struct {
+	// Start of the history
+	// Example: 2023-10-18T00:00:00Z
+	Since time.Time `json:"since" yaml:"since"`
+
+	// Duration of each entry (in seconds)
+	// Example: 3600
+	Step int64 `json:"step" yaml:"step"`
+
+	// Usage entries, oldest first
+	Entries []InstanceUsageEntry `json:"entries" yaml:"entries"`
+}
+
+// InstanceUsageEntry represents the resource usage of an instance over one step of its history.
+//
+// swagger:model
+//
+// API extension: instance_usage_history.
+type InstanceUsageEntry struct {
+	// Start of the step
+	// Example: 2023-10-18T01:00:00Z
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+
+	// Number of samples covering the step
+	// Example: 12
+	Samples int64 `json:"samples" yaml:"samples"`
+
+	// CPU time used during the step (in nanoseconds)
+	// Example: 183000000000
+	CPUUsage int64 `json:"cpu_usage" yaml:"cpu_usage"`
+
+	// Average memory usage during the step (in bytes)
+	// Example: 73248768
+	MemoryUsage int64 `json:"memory_usage" yaml:"memory_usage"`
+
+	// Highest memory usage sampled during the step (in bytes)
+	// Example: 98304000
+	MemoryUsagePeak int64 `json:"memory_usage_peak" yaml:"memory_usage_peak"`
+
+	// Highest disk usage sampled during the step (in bytes)
+	// Example: 1510621184
+	DiskUsage int64 `json:"disk_usage" yaml:"disk_usage"`
+
+	// Bytes received over the network during the step
+	// Example: 2035612
+	NetworkReceived int64 `json:"network_received" yaml:"network_received"`
+
+	// Bytes sent over the network during the step
+	// Example: 96342
+	NetworkSent int64 `json:"network_sent" yaml:"network_sent"`
+}
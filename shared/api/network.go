@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // NetworksPost represents the fields of a new network
 //
 // swagger:model
@@ -182,6 +186,11 @@ type NetworkState struct {
 	//
 	// API extension: network_state_ovn
 	OVN *NetworkStateOVN `json:"ovn" yaml:"ovn"`
+
+	// Additional WireGuard network information
+	//
+	// API extension: network_wireguard
+	Wireguard *NetworkStateWireguard `json:"wireguard" yaml:"wireguard"`
 }
 
 // NetworkStateAddress represents a network address
@@ -316,3 +325,52 @@ type NetworkStateOVN struct {
 	// OVN network chassis name
 	Chassis string `json:"chassis" yaml:"chassis"`
 }
+
+// NetworkStateWireguard represents WireGuard specific state
+//
+// swagger:model
+//
+// API extension: network_wireguard.
+type NetworkStateWireguard struct {
+	// Public key of the interface
+	// Example: HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw=
+	PublicKey string `json:"public_key" yaml:"public_key"`
+
+	// UDP port the interface listens on
+	// Example: 51820
+	ListenPort uint64 `json:"listen_port" yaml:"listen_port"`
+
+	// State of the configured peers
+	Peers []NetworkStateWireguardPeer `json:"peers" yaml:"peers"`
+}
+
+// NetworkStateWireguardPeer represents the state of a WireGuard peer
+//
+// swagger:model
+//
+// API extension: network_wireguard.
+type NetworkStateWireguardPeer struct {
+	// Name of the network peer
+	// Example: site-b
+	Name string `json:"name" yaml:"name"`
+
+	// Public key of the peer
+	// Example: xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=
+	PublicKey string `json:"public_key" yaml:"public_key"`
+
+	// Current endpoint of the peer
+	// Example: 198.51.100.10:51820
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// Time of the latest handshake with the peer
+	// Example: 2023-10-20T10:33:10Z
+	LatestHandshake time.Time `json:"latest_handshake" yaml:"latest_handshake"`
+
+	// Number of bytes received from the peer
+	// Example: 250542118
+	BytesReceived uint64 `json:"bytes_received" yaml:"bytes_received"`
+
+	// Number of bytes sent to the peer
+	// Example: 17524040140
+	BytesSent uint64 `json:"bytes_sent" yaml:"bytes_sent"`
+}
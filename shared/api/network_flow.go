@@ -0,0 +1,72 @@
+package api
+
+import (
+	"time"
+)
+
+// NetworkFlow represents the accounting of a connection going through a network
+//
+// swagger:model
+//
+// API extension: network_flows.
+type NetworkFlow struct {
+	// Protocol name
+	// Example: tcp
+	Protocol string `json:"protocol" yaml:"protocol"`
+
+	// Source address of the connection
+	// Example: 10.0.0.2
+	Source string `json:"source" yaml:"source"`
+
+	// Source port of the connection (not set for protocols without ports)
+	// Example: 51002
+	SourcePort uint64 `json:"source_port" yaml:"source_port"`
+
+	// Destination address of the connection
+	// Example: 192.0.2.10
+	Destination string `json:"destination" yaml:"destination"`
+
+	// Destination port of the connection (not set for protocols without ports)
+	// Example: 443
+	DestinationPort uint64 `json:"destination_port" yaml:"destination_port"`
+
+	// Name of the instance that initiated the connection (if known)
+	// Example: c1
+	SourceInstance string `json:"source_instance" yaml:"source_instance"`
+
+	// Name of the instance that received the connection (if known)
+	// Example: c2
+	DestinationInstance string `json:"destination_instance" yaml:"destination_instance"`
+
+	// Project of the instance that initiated the connection (if known)
+	// Example: default
+	SourceProject string `json:"source_project" yaml:"source_project"`
+
+	// Project of the instance that received the connection (if known)
+	// Example: default
+	DestinationProject string `json:"destination_project" yaml:"destination_project"`
+
+	// Number of bytes sent by the source
+	// Example: 23012
+	BytesSent uint64 `json:"bytes_sent" yaml:"bytes_sent"`
+
+	// Number of bytes received by the source
+	// Example: 810923
+	BytesReceived uint64 `json:"bytes_received" yaml:"bytes_received"`
+
+	// Number of packets sent by the source
+	// Example: 120
+	PacketsSent uint64 `json:"packets_sent" yaml:"packets_sent"`
+
+	// Number of packets received by the source
+	// Example: 612
+	PacketsReceived uint64 `json:"packets_received" yaml:"packets_received"`
+
+	// Time at which the connection was last seen
+	// Example: 2023-10-20T10:33:10Z
+	LastSeen time.Time `json:"last_seen" yaml:"last_seen"`
+
+	// What cluster member this flow was recorded on
+	// Example: server01
+	Location string `json:"location" yaml:"location"`
+}
@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // NetworkZonesPost represents the fields of a new network zone
 //
 // swagger:model
@@ -118,3 +122,48 @@ type NetworkZoneRecord struct {
 func (f *NetworkZoneRecord) Writable() NetworkZoneRecordPut {
 	return f.NetworkZoneRecordPut
 }
+
+// NetworkZoneDNSSEC represents the DNSSEC state of a network zone
+//
+// swagger:model
+//
+// API extension: network_zones_dnssec.
+type NetworkZoneDNSSEC struct {
+	// Keys used to sign the zone, including retired keys that are still published
+	Keys []NetworkZoneDNSSECKey `json:"keys" yaml:"keys"`
+}
+
+// NetworkZoneDNSSECKey represents the public part of a DNSSEC key of a network zone
+//
+// swagger:model
+//
+// API extension: network_zones_dnssec.
+type NetworkZoneDNSSECKey struct {
+	// Key tag
+	// Example: 20326
+	KeyTag uint16 `json:"key_tag" yaml:"key_tag"`
+
+	// Key type (ksk or zsk)
+	// Example: ksk
+	Type string `json:"type" yaml:"type"`
+
+	// Signing algorithm
+	// Example: ECDSAP256SHA256
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+
+	// DNSKEY record of the key
+	// Example: example.net. 3600 IN DNSKEY 257 3 13 mdsswUyr3DPW132mOi8V9xESWE8jTo0dxCjjnopKl+GqJxpVXckHAeF+KkxLbxILfDLUT0rAK9iUzy1L53eKGQ==
+	DNSKEY string `json:"dnskey" yaml:"dnskey"`
+
+	// DS record to publish in the parent zone (only set for key signing keys)
+	// Example: example.net. 3600 IN DS 20326 13 2 e06d44b80b8f1d39a95c0b0d7c65d08458e880409bbc683457104237c7f8ec8d
+	DS string `json:"ds" yaml:"ds"`
+
+	// When the key was created
+	// Example: 2023-10-20T10:33:10Z
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// When the key was replaced by a new one (zero for active keys)
+	// Example: 2023-11-19T10:33:10Z
+	RetiredAt time.Time `json:"retired_at" yaml:"retired_at"`
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"time"
+)
+
+// ProjectUsage represents the resource usage history of a project.
+//
+// swagger:model
+//
+// API extension: projects_usage_history.
+type ProjectUsage struct {
+	// Start of the history
+	// Example: 2023-10-01T00:00:00Z
+	Start time.Time `json:"start" yaml:"start"`
+
+	// End of the history
+	// Example: 2023-11-01T00:00:00Z
+	End time.Time `json:"end" yaml:"end"`
+
+	// Duration of each entry (in seconds)
+	// Example: 86400
+	Step int64 `json:"step" yaml:"step"`
+
+	// Usage entries, oldest first
+	Entries []ProjectUsageEntry `json:"entries" yaml:"entries"`
+}
+
+// ProjectUsageEntry represents the resource usage of a project over one step of its history.
+//
+// swagger:model
+//
+// API extension: projects_usage_history.
+type ProjectUsageEntry struct {
+	// Start of the step
+	// Example: 2023-10-02T00:00:00Z
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+
+	// Running container hours during the step
+	// Example: 48
+	ContainerHours float64 `json:"container_hours" yaml:"container_hours"`
+
+	// Running virtual machine hours during the step
+	// Example: 24
+	VirtualMachineHours float64 `json:"virtual_machine_hours" yaml:"virtual_machine_hours"`
+
+	// vCPU hours of the running instances during the step
+	// Example: 96
+	CPUHours float64 `json:"cpu_hours" yaml:"cpu_hours"`
+
+	// Used disk GB hours (1 GB being 1000^3 bytes) during the step
+	// Example: 1200
+	DiskGBHours float64 `json:"disk_gb_hours" yaml:"disk_gb_hours"`
+}
@@ -0,0 +1,12 @@
+package api
+
+// ScriptletData represents the key/value store of a scriptlet.
+//
+// swagger:model
+//
+// API extension: instance_placement_scriptlet_data.
+type ScriptletData struct {
+	// Values stored for the scriptlet
+	// Example: {"licenses": "4"}
+	Data map[string]string `json:"data" yaml:"data"`
+}
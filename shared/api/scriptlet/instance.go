@@ -31,3 +31,25 @@ type InstancePlacement struct {
 	Reason  string `json:"reason"`
 	Project string `json:"project"`
 }
+
+// InstanceValidationReasonCreate is when a new instance request is received.
+const InstanceValidationReasonCreate = "create"
+
+// InstanceValidationReasonUpdate is when an instance update request is received.
+const InstanceValidationReasonUpdate = "update"
+
+// InstanceValidationReasonRename is when an instance rename request is received.
+const InstanceValidationReasonRename = "rename"
+
+// InstanceValidation represents the instance validation request.
+// The configuration and devices are expanded with the instance's profiles.
+//
+// API extension: validation_scriptlets.
+type InstanceValidation struct {
+	api.InstancePut `yaml:",inline"`
+
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Project string `json:"project"`
+}
@@ -0,0 +1,24 @@
+package scriptlet
+
+import (
+	"github.com/lxc/incus/shared/api"
+)
+
+// ProjectValidationReasonCreate is when a new project request is received.
+const ProjectValidationReasonCreate = "create"
+
+// ProjectValidationReasonUpdate is when a project update request is received.
+const ProjectValidationReasonUpdate = "update"
+
+// ProjectValidationReasonRename is when a project rename request is received.
+const ProjectValidationReasonRename = "rename"
+
+// ProjectValidation represents the project validation request.
+//
+// API extension: validation_scriptlets.
+type ProjectValidation struct {
+	api.ProjectPut `yaml:",inline"`
+
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
@@ -284,12 +284,14 @@ if [ "${1:-"all"}" != "cluster" ]; then
     run_test test_image_import_dir "import image from directory"
     run_test test_image_refresh "image refresh"
     run_test test_image_acl "image acl"
+    run_test test_image_publish_stateful "stateful image publishing"
     run_test test_cloud_init "cloud-init"
     run_test test_exec "exec"
     run_test test_concurrent_exec "concurrent exec"
     run_test test_concurrent "concurrent startup"
     run_test test_snapshots "container snapshots"
     run_test test_snap_restore "snapshot restores"
+    run_test test_snap_vm_stateful "stateful virtual machine snapshots"
     run_test test_snap_expiry "snapshot expiry"
     run_test test_snap_schedule "snapshot scheduling"
     run_test test_snap_volume_db_recovery "snapshot volume database record recovery"
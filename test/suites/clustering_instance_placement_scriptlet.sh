@@ -159,6 +159,81 @@ EOF
   INCUS_DIR="${INCUS_ONE_DIR}" incus info c1 | grep -q "Location: node3"
   INCUS_DIR="${INCUS_ONE_DIR}" incus delete -f c1
 
+  # Check the scriptlet data endpoints.
+  INCUS_DIR="${INCUS_ONE_DIR}" incus query -X PUT /1.0/scriptlets/instance_placement/data -d '{\"data\": {\"licenses\": \"1\", \"foo\": \"bar\"}}'
+  [ "$(INCUS_DIR="${INCUS_TWO_DIR}" incus query /1.0/scriptlets/instance_placement/data | jq -r '.data.foo')" = "bar" ]
+  INCUS_DIR="${INCUS_TWO_DIR}" incus query -X PATCH /1.0/scriptlets/instance_placement/data -d '{\"data\": {\"foo\": \"\"}}'
+  [ "$(INCUS_DIR="${INCUS_THREE_DIR}" incus query /1.0/scriptlets/instance_placement/data | jq -r '.data.foo')" = "null" ]
+  [ "$(INCUS_DIR="${INCUS_THREE_DIR}" incus query /1.0/scriptlets/instance_placement/data | jq -r '.data.licenses')" = "1" ]
+  ! INCUS_DIR="${INCUS_ONE_DIR}" incus query -X PATCH /1.0/scriptlets/instance_placement/data -d '{\"data\": {\"\": \"bar\"}}' || false
+  ! INCUS_DIR="${INCUS_ONE_DIR}" incus query /1.0/scriptlets/foo/data || false
+
+  # Set an instance placement scriptlet using the cluster state and its key/value store to place instances.
+  INCUS_DIR="${INCUS_ONE_DIR}" incus cluster group create blue
+  INCUS_DIR="${INCUS_ONE_DIR}" incus cluster group assign node3 default,blue
+  INCUS_DIR="${INCUS_ONE_DIR}" incus init testimage c0 --target node3
+
+  cat << EOF | INCUS_DIR="${INCUS_ONE_DIR}" incus config set instances.placement.scriptlet=-
+def instance_placement(request, candidate_members):
+        project = get_project(request.project)
+        if project == None or project.name != "default":
+                return "Expecting project default"
+
+        if get_project("nonexistent") != None:
+                return "Expecting no nonexistent project"
+
+        members = []
+        for group in get_cluster_groups():
+                if group.name == "blue":
+                        members = group.members
+
+        if members != ["node3"]:
+                return "Expecting node3 in the blue group"
+
+        names = [inst.name for inst in get_instances(location="node3", project="default")]
+        if "c0" not in names:
+                return "Expecting c0 on node3"
+
+        if len(get_instances(location="node2")) != 0:
+                return "Expecting no instances on node2"
+
+        licenses = int(get_data("licenses"))
+        if licenses < 1:
+                return "No license left"
+
+        set_data("licenses", str(licenses - 1))
+        if get_data("licenses") != str(licenses - 1):
+                return "Expecting the updated licenses value"
+
+        set_target(members[0])
+
+        return # No error.
+EOF
+
+  # The first instance takes the last license, the next one is refused without changing the stored data.
+  INCUS_DIR="${INCUS_TWO_DIR}" incus init testimage c1
+  INCUS_DIR="${INCUS_ONE_DIR}" incus info c1 | grep -q "Location: node3"
+  [ "$(INCUS_DIR="${INCUS_ONE_DIR}" incus query /1.0/scriptlets/instance_placement/data | jq -r '.data.licenses')" = "0" ]
+  ! INCUS_DIR="${INCUS_ONE_DIR}" incus init testimage c2 || false
+  [ "$(INCUS_DIR="${INCUS_ONE_DIR}" incus query /1.0/scriptlets/instance_placement/data | jq -r '.data.licenses')" = "0" ]
+
+  # Values set by a failing scriptlet aren't stored.
+  cat << EOF | INCUS_DIR="${INCUS_ONE_DIR}" incus config set instances.placement.scriptlet=-
+def instance_placement(request, candidate_members):
+        set_data("licenses", "10")
+
+        fail("Instance not allowed")
+EOF
+
+  ! INCUS_DIR="${INCUS_ONE_DIR}" incus init testimage c2 || false
+  [ "$(INCUS_DIR="${INCUS_ONE_DIR}" incus query /1.0/scriptlets/instance_placement/data | jq -r '.data.licenses')" = "0" ]
+
+  INCUS_DIR="${INCUS_ONE_DIR}" incus config unset instances.placement.scriptlet
+  INCUS_DIR="${INCUS_ONE_DIR}" incus delete -f c0 c1
+  INCUS_DIR="${INCUS_ONE_DIR}" incus cluster group assign node3 default
+  INCUS_DIR="${INCUS_ONE_DIR}" incus cluster group delete blue
+  INCUS_DIR="${INCUS_ONE_DIR}" incus query -X PUT /1.0/scriptlets/instance_placement/data -d '{\"data\": {}}'
+
   # Delete the storage pool
   printf 'config: {}\ndevices: {}' | INCUS_DIR="${INCUS_ONE_DIR}" incus profile edit default
   INCUS_DIR="${INCUS_ONE_DIR}" incus storage delete data
@@ -184,3 +184,41 @@ test_image_refresh() {
   incus remote rm l2
   kill_incus "${INCUS2_DIR}"
 }
+
+test_image_publish_stateful() {
+  if ! command -v criu >/dev/null 2>&1; then
+    echo "==> SKIP: stateful image publishing with CRIU (missing binary)"
+    return
+  fi
+
+  ensure_import_testimage
+
+  incus launch testimage c1
+  incus exec c1 -- sh -c "nohup sleep 1234 >/dev/null 2>&1 &"
+  incus publish c1 --alias stateful-image --stateful
+
+  # The published container keeps running and the image is flagged as stateful.
+  [ "$(incus list -c s --format csv c1)" = "RUNNING" ]
+  [ "$(incus image get-property stateful-image stateful)" = "true" ]
+
+  # Instances created from the image resume the state on first start, even through a plain start request.
+  incus init stateful-image c2
+  [ "$(incus config get c2 volatile.apply_state)" = "true" ]
+  incus query --wait -X PUT -d '{\"action\": \"start\", \"timeout\": -1}' /1.0/instances/c2/state
+  [ "$(incus list -c s --format csv c2)" = "RUNNING" ]
+  incus exec c2 -- pgrep -f "sleep 1234"
+  [ -z "$(incus config get c2 volatile.apply_state)" ]
+
+  # Later starts boot normally.
+  incus restart c2 --force
+  ! incus exec c2 -- pgrep -f "sleep 1234" || false
+
+  # Images published statelessly aren't flagged.
+  incus publish c1 --alias stateless-image --force
+  ! incus image get-property stateless-image stateful || false
+  incus init stateless-image c3
+  [ -z "$(incus config get c3 volatile.apply_state)" ]
+
+  incus delete --force c1 c2 c3
+  incus image delete stateful-image stateless-image
+}
@@ -132,6 +132,14 @@ test_network_forward() {
     [ "$(nft -nn list chain inet incus "fwdpstrt.${netName}" | wc -l)" -eq 7 ]
   fi
 
+  # Check forwards created from a project without its own networks are counted against that project.
+  incus project create fwdtest -c features.networks=false -c limits.networks.forwards=1
+  incus network forward create "${netName}" 198.51.100.2 --project fwdtest
+  ! incus network forward create "${netName}" 198.51.100.3 --project fwdtest || false
+  ! incus project set fwdtest limits.networks.forwards=0 || false
+  incus network forward delete "${netName}" 198.51.100.2 --project fwdtest
+  incus project delete fwdtest
+
   # Check forward is exported via BGP prefixes before network delete.
   incus query /internal/testing/bgp | grep "198.51.100.1/32"
 
@@ -260,6 +260,22 @@ test_projects_backups() {
   [ -f "${INCUS_DIR}/non-optimized/backup/index.yaml" ]
   [ -d "${INCUS_DIR}/non-optimized/backup/container" ]
 
+  # Backups exceeding the size limit are discarded.
+  incus project set foo limits.backups.size=1KiB
+  ! incus export c1 "${INCUS_DIR}/c1-limited.tar.gz" || false
+  [ "$(incus query "/1.0/instances/c1/backups?project=foo" | jq length)" = "0" ]
+  incus project set foo limits.backups.size=1GiB
+
+  # Limits can't be set below the current usage.
+  incus query -X POST -d '{\"name\": \"b1\"}' "/1.0/instances/c1/backups?project=foo"
+  ! incus project set foo limits.backups=0 || false
+  ! incus project set foo limits.backups.size=1KiB || false
+  incus project set foo limits.backups=1
+  incus project info foo | grep -q "BACKUPS-SIZE"
+  incus query -X DELETE "/1.0/instances/c1/backups/b1?project=foo"
+  incus project unset foo limits.backups
+  incus project unset foo limits.backups.size
+
   # Delete the container
   incus delete c1
 
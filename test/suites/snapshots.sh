@@ -314,6 +314,60 @@ restore_and_compare_fs() {
   fi
 }
 
+test_snap_vm_stateful() {
+  if ! incus info | grep -q 'driver: .*qemu'; then
+    echo "==> SKIP: stateful virtual machine snapshots (QEMU not available)"
+    return
+  fi
+
+  # Boot a tiny test kernel if provided, otherwise the VM sits in its firmware which is enough to have state.
+  incus init --empty --vm v1 -c limits.cpu=1 -c limits.memory=128MiB -c migration.stateful=true -c security.secureboot=false
+  incus config device override v1 root size.state=256MiB
+  if [ -n "${INCUS_VM_TEST_KERNEL:-}" ]; then
+    incus config set v1 raw.qemu="-kernel ${INCUS_VM_TEST_KERNEL} -append console=ttyS0"
+  fi
+
+  incus start v1
+
+  # Stateful snapshots store the state in the snapshot and leave the instance running.
+  incus snapshot create v1 snap0 --stateful
+  [ "$(incus query /1.0/instances/v1/snapshots/snap0 | jq -r .stateful)" = "true" ]
+  [ "$(incus list v1 -c s --format csv)" = "RUNNING" ]
+
+  incus snapshot create v1 snap1
+  [ "$(incus query /1.0/instances/v1/snapshots/snap1 | jq -r .stateful)" = "false" ]
+
+  # Restoring the running state requires a stateful snapshot.
+  ! incus snapshot restore v1 snap1 --stateful || false
+
+  # A stateful restore resumes the instance, from a running or a stopped instance.
+  incus snapshot restore v1 snap0 --stateful
+  [ "$(incus list v1 -c s --format csv)" = "RUNNING" ]
+  [ "$(incus query /1.0/instances/v1 | jq -r .stateful)" = "false" ]
+
+  incus stop v1 --force
+  incus snapshot restore v1 snap0 --stateful
+  [ "$(incus list v1 -c s --format csv)" = "RUNNING" ]
+
+  # A stateless restore discards the state.
+  incus snapshot restore v1 snap0
+  [ "$(incus list v1 -c s --format csv)" = "RUNNING" ]
+  [ "$(incus query /1.0/instances/v1 | jq -r .stateful)" = "false" ]
+
+  # Hotplugged memory can't be part of a stateful snapshot.
+  if incus config set v1 limits.memory=256MiB; then
+    ! incus snapshot create v1 snap2 --stateful || false
+  fi
+
+  # A stateless restore of a stopped instance doesn't leave it with the snapshot's state.
+  incus stop v1 --force
+  incus snapshot restore v1 snap0
+  [ "$(incus list v1 -c s --format csv)" = "STOPPED" ]
+  [ "$(incus query /1.0/instances/v1 | jq -r .stateful)" = "false" ]
+
+  incus delete -f v1
+}
+
 test_snap_expiry() {
   # shellcheck disable=2039,3043
   local incus_backend